@@ -0,0 +1,241 @@
+// cmd/mockue/admin.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Reporting and reconnect timing
+const (
+	heartbeatInterval = 15 * time.Second
+	reportAttempts    = 3
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// event is an event as the station sends it to the admin
+type event struct {
+	ID      string         `json:"id"`
+	Type    string         `json:"type"`
+	At      time.Time      `json:"at"`
+	Phase   string         `json:"phase,omitempty"`
+	Text    string         `json:"text,omitempty"`
+	Score   *int           `json:"score,omitempty"`
+	Reason  string         `json:"reason,omitempty"`
+	Ratings map[string]int `json:"ratings,omitempty"`
+}
+
+// message is the envelope of the control channel
+type message struct {
+	Type            string `json:"type"`
+	ID              string `json:"id,omitempty"`
+	SessionID       string `json:"sessionId,omitempty"`
+	Command         string `json:"command,omitempty"`
+	OK              bool   `json:"ok,omitempty"`
+	Error           string `json:"error,omitempty"`
+	PayloadVersions []int  `json:"payloadVersions,omitempty"`
+	Battery         *int   `json:"battery,omitempty"`
+	AppVersion      string `json:"appVersion,omitempty"`
+}
+
+// status returns a hello or heartbeat describing the station
+func (c *adminClient) status(s *station, messageType string) message {
+	m := message{
+		Type:       messageType,
+		SessionID:  s.currentSession(),
+		AppVersion: c.opts.AppVersion,
+	}
+	if c.opts.Battery >= 0 {
+		battery := c.opts.Battery
+		m.Battery = &battery
+	}
+	if messageType == "hello" {
+		m.PayloadVersions = c.opts.PayloadVersions
+	}
+	return m
+}
+
+// adminClient talks to the admin server on behalf of the station
+type adminClient struct {
+	opts       options
+	httpClient *http.Client
+
+	// acked remembers the answer to each command, since the admin resends
+	// unacknowledged commands after a reconnect
+	mu    sync.Mutex
+	acked map[string]message
+}
+
+func newAdminClient(opts options) *adminClient {
+	return &adminClient{
+		opts:       opts,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		acked:      make(map[string]message),
+	}
+}
+
+// reportEvent posts an event for a session, retrying a few times. The admin ignores
+// events it already has, so resending after an unclear failure is safe.
+func (c *adminClient) reportEvent(sessionID string, e event) {
+	body, err := json.Marshal(e)
+	if err != nil {
+		log.Printf("Error encoding event: %v", err)
+		return
+	}
+	endpoint := strings.TrimRight(c.opts.Admin, "/") + "/api/ue/sessions/" + url.PathEscape(sessionID) + "/events"
+
+	for attempt := 1; attempt <= reportAttempts; attempt++ {
+		err = c.post(endpoint, body)
+		if err == nil {
+			return
+		}
+		log.Printf("Reporting %s for session %s failed (attempt %d of %d): %v", e.Type, sessionID, attempt, reportAttempts, err)
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
+
+func (c *adminClient) post(endpoint string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.opts.Token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("admin responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// controlURL returns the WebSocket URL of the station's control channel
+func (c *adminClient) controlURL() string {
+	base := strings.TrimRight(c.opts.Admin, "/")
+	base = strings.Replace(base, "https://", "wss://", 1)
+	base = strings.Replace(base, "http://", "ws://", 1)
+	return base + "/api/ue/stations/" + url.PathEscape(c.opts.StationID) + "/control"
+}
+
+// controlLoop keeps the control channel open, reconnecting with a growing delay
+func (c *adminClient) controlLoop(s *station) {
+	delay := minReconnectDelay
+	for {
+		connected := time.Now()
+		if err := c.serveControl(s); err != nil {
+			log.Printf("Control channel: %v", err)
+		}
+		if time.Since(connected) > maxReconnectDelay {
+			delay = minReconnectDelay
+		}
+		log.Printf("Reconnecting to the control channel in %s", delay)
+		time.Sleep(delay)
+		delay *= 2
+		if delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// serveControl connects once and answers commands until the connection drops
+func (c *adminClient) serveControl(s *station) error {
+	header := http.Header{"Authorization": {"Bearer " + c.opts.Token}}
+	ws, resp, err := websocket.DefaultDialer.Dial(c.controlURL(), header)
+	if err != nil {
+		if resp != nil {
+			return fmt.Errorf("connecting: %w (status %d)", err, resp.StatusCode)
+		}
+		return fmt.Errorf("connecting: %w", err)
+	}
+	defer ws.Close()
+	log.Printf("Connected to the control channel at %s", c.controlURL())
+
+	// Writes come from the heartbeat and from acks sent after -ack-delay
+	var writeMu sync.Mutex
+	write := func(m message) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return ws.WriteJSON(m)
+	}
+
+	if err := write(c.status(s, "hello")); err != nil {
+		return fmt.Errorf("saying hello: %w", err)
+	}
+
+	if c.opts.NoHeartbeat {
+		log.Printf("Heartbeats disabled; the admin will drop this connection")
+		ws.SetPingHandler(func(string) error { return nil })
+	} else {
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			ticker := time.NewTicker(heartbeatInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					if err := write(c.status(s, "heartbeat")); err != nil {
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	for {
+		var m message
+		if err := ws.ReadJSON(&m); err != nil {
+			return err
+		}
+		if m.Type != "command" {
+			continue
+		}
+		log.Printf("Command %s: %s session %s", m.ID, m.Command, m.SessionID)
+		go c.acknowledge(s, m, write)
+	}
+}
+
+// acknowledge applies a command and answers it, after -ack-delay
+func (c *adminClient) acknowledge(s *station, m message, write func(message) error) {
+	if c.opts.AckDelay > 0 {
+		time.Sleep(c.opts.AckDelay)
+	}
+
+	c.mu.Lock()
+	ack, repeated := c.acked[m.ID]
+	c.mu.Unlock()
+
+	if !repeated {
+		ack = message{Type: "ack", ID: m.ID, OK: true}
+		if c.opts.RejectCommands {
+			ack.OK, ack.Error = false, "mock station rejects all commands"
+		} else if reason := s.command(m.SessionID, m.Command); reason != "" {
+			ack.OK, ack.Error = false, reason
+		}
+		c.mu.Lock()
+		c.acked[m.ID] = ack
+		c.mu.Unlock()
+	}
+
+	if err := write(ack); err != nil {
+		log.Printf("Error acknowledging %s: %v", m.ID, err)
+		return
+	}
+	log.Printf("Acknowledged %s (ok: %t)", m.ID, ack.OK)
+}
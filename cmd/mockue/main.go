@@ -0,0 +1,225 @@
+// cmd/mockue/main.go
+
+// Command mockue is a stand-in for an Unreal Engine VR station, for development and
+// testing on machines that cannot run Unreal. It accepts session payloads like a real
+// station, plays a scripted session, reports its events and final score to the admin and
+// answers commands on the control channel. Flags make it misbehave in the ways real
+// stations do: slow or failing payload answers, slow or rejected acks, crashes and
+// missed heartbeats.
+//
+// Usage:
+//
+//	mockue -station station_default -token secret -admin http://localhost:8080
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/internal/uepayload"
+)
+
+// options holds the command line configuration of the mock station
+type options struct {
+	Addr      string
+	Path      string
+	Admin     string
+	StationID string
+	Token     string
+
+	Script string
+	Speed  float64
+	Score  int
+	// PayloadVersions are the payload versions the station advertises and accepts
+	PayloadVersions []int
+	// Battery and AppVersion are reported in heartbeats; a negative Battery reports none
+	Battery    int
+	AppVersion string
+
+	// Failure modes
+	PayloadDelay   time.Duration
+	PayloadStatus  int
+	AckDelay       time.Duration
+	RejectCommands bool
+	CrashAfter     time.Duration
+	NoHeartbeat    bool
+}
+
+func parseOptions() options {
+	var opts options
+	flag.StringVar(&opts.Addr, "addr", ":8081", "address to accept session payloads on")
+	flag.StringVar(&opts.Path, "path", "/api/vr-session", "path to accept session payloads on")
+	flag.StringVar(&opts.Admin, "admin", "http://localhost:8080", "base URL of the admin server")
+	flag.StringVar(&opts.StationID, "station", "station_default", "ID of the station in the admin settings")
+	flag.StringVar(&opts.Token, "token", "", "auth token of the station")
+
+	flag.StringVar(&opts.Script, "script", "", "JSON file with the session script (default: built-in script)")
+	flag.Float64Var(&opts.Speed, "speed", 1, "play the script this many times faster")
+	flag.IntVar(&opts.Score, "score", 80, "final score reported when a session completes; rubric items are rated at the same share of their scale")
+	flag.IntVar(&opts.Battery, "battery", 87, "battery level in percent reported in heartbeats, or -1 for none")
+	flag.StringVar(&opts.AppVersion, "app-version", "mockue-1.0", "app version reported in heartbeats")
+	versions := flag.String("payload-versions", strconv.Itoa(uepayload.Version), "comma separated payload versions the station supports")
+
+	flag.DurationVar(&opts.PayloadDelay, "payload-delay", 0, "wait this long before answering a payload, to cause timeouts")
+	flag.IntVar(&opts.PayloadStatus, "payload-status", http.StatusOK, "HTTP status to answer payloads with")
+	flag.DurationVar(&opts.AckDelay, "ack-delay", 0, "wait this long before acknowledging a command")
+	flag.BoolVar(&opts.RejectCommands, "reject-commands", false, "answer every command with a negative ack")
+	flag.DurationVar(&opts.CrashAfter, "crash-after", 0, "report a crash this long into each session")
+	flag.BoolVar(&opts.NoHeartbeat, "no-heartbeat", false, "stop sending heartbeats and answering pings")
+	flag.Parse()
+
+	parsed, err := uepayload.ParseVersions(*versions)
+	if err != nil {
+		log.Fatalf("Invalid -payload-versions: %v", err)
+	}
+	opts.PayloadVersions = parsed
+	if opts.Speed <= 0 {
+		opts.Speed = 1
+	}
+	return opts
+}
+
+// payload is the part of the session payload the mock station needs
+type payload struct {
+	Version   int    `json:"version"`
+	SessionID string `json:"sessionId"`
+	Status    string `json:"status"`
+	Scenario  struct {
+		Name string `json:"name"`
+	} `json:"scenario"`
+	Avatar struct {
+		Name string `json:"name"`
+	} `json:"avatar"`
+	Observer struct {
+		Name   string `json:"name"`
+		Rubric []struct {
+			ID  string `json:"id"`
+			Min int    `json:"min"`
+			Max int    `json:"max"`
+		} `json:"rubric"`
+	} `json:"observer"`
+}
+
+// station keeps the sessions the mock is playing
+type station struct {
+	opts   options
+	script []Step
+	admin  *adminClient
+
+	mu   sync.Mutex
+	runs map[string]*run
+}
+
+// handlePayload accepts a session payload and starts playing sessions it has not seen yet
+func (s *station) handlePayload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		// The admin's station check sends a GET
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read payload", http.StatusBadRequest)
+		return
+	}
+	var p payload
+	if err := json.Unmarshal(body, &p); err != nil || p.SessionID == "" {
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		return
+	}
+	if !slices.Contains(s.opts.PayloadVersions, p.Version) {
+		log.Printf("Refusing payload version %d for session %s", p.Version, p.SessionID)
+		http.Error(w, fmt.Sprintf("unsupported payload version %d", p.Version), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Payload v%d for session %s (%s): scenario %q, avatar %q, observer %q",
+		p.Version, p.SessionID, p.Status, p.Scenario.Name, p.Avatar.Name, p.Observer.Name)
+
+	if s.opts.PayloadDelay > 0 {
+		log.Printf("Delaying the answer by %s", s.opts.PayloadDelay)
+		time.Sleep(s.opts.PayloadDelay)
+	}
+	if s.opts.PayloadStatus < 200 || s.opts.PayloadStatus > 299 {
+		log.Printf("Answering the payload with status %d", s.opts.PayloadStatus)
+		http.Error(w, "mock station failure", s.opts.PayloadStatus)
+		return
+	}
+
+	s.mu.Lock()
+	_, known := s.runs[p.SessionID]
+	if !known && (p.Status == "pending" || p.Status == "starting" || p.Status == "running") {
+		run := newRun(s, p)
+		s.runs[p.SessionID] = run
+		go run.play()
+	}
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// command applies a control channel command to the session it names
+func (s *station) command(sessionID, command string) string {
+	s.mu.Lock()
+	run, ok := s.runs[sessionID]
+	s.mu.Unlock()
+	if !ok {
+		return "unknown session"
+	}
+	return run.command(command)
+}
+
+// currentSession returns a session the mock is playing, or "" when it is idle
+func (s *station) currentSession() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sessionID := range s.runs {
+		return sessionID
+	}
+	return ""
+}
+
+// finished forgets a session once its script has ended
+func (s *station) finished(sessionID string) {
+	s.mu.Lock()
+	delete(s.runs, sessionID)
+	s.mu.Unlock()
+}
+
+func main() {
+	opts := parseOptions()
+
+	script := defaultScript()
+	if opts.Script != "" {
+		loaded, err := loadScript(opts.Script)
+		if err != nil {
+			log.Fatalf("Error loading script: %v", err)
+		}
+		script = loaded
+	}
+
+	s := &station{
+		opts:   opts,
+		script: script,
+		admin:  newAdminClient(opts),
+		runs:   make(map[string]*run),
+	}
+
+	go s.admin.controlLoop(s)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(opts.Path, s.handlePayload)
+
+	log.Printf("Mock station %s accepting payloads on %s%s, reporting to %s", opts.StationID, opts.Addr, opts.Path, opts.Admin)
+	if err := http.ListenAndServe(opts.Addr, mux); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,297 @@
+// cmd/mockue/mockue_test.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/internal/uepayload"
+	"github.com/saladinomario/vr-training-admin/internal/unreal"
+)
+
+const (
+	testStationID = "station_test"
+	testToken     = "secret"
+	waitTimeout   = 5 * time.Second
+)
+
+// reportedEvent is an event the mock station posted to the admin
+type reportedEvent struct {
+	sessionID string
+	event
+}
+
+// fakeAdmin stands in for the admin server: it serves the control channel through the
+// admin's own hub and records the events stations report
+type fakeAdmin struct {
+	server *httptest.Server
+	hub    *unreal.Hub
+	events chan reportedEvent
+}
+
+func newFakeAdmin(t *testing.T) *fakeAdmin {
+	t.Helper()
+	authenticate := func(stationID, token string) error {
+		if stationID != testStationID || token != testToken {
+			return errors.New("unknown station or wrong token")
+		}
+		return nil
+	}
+	a := &fakeAdmin{
+		hub:    unreal.NewHub(authenticate, unreal.NewRegistry()),
+		events: make(chan reportedEvent, 64),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/ue/stations/{id}/control", func(w http.ResponseWriter, r *http.Request) {
+		a.hub.ServeStation(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("/api/ue/sessions/{id}/events", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testToken {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var e event
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		a.events <- reportedEvent{sessionID: r.PathValue("id"), event: e}
+		w.WriteHeader(http.StatusAccepted)
+	})
+	a.server = httptest.NewServer(mux)
+	t.Cleanup(a.server.Close)
+	return a
+}
+
+// nextEvent waits for the next event a station reports
+func (a *fakeAdmin) nextEvent(t *testing.T) reportedEvent {
+	t.Helper()
+	select {
+	case e := <-a.events:
+		return e
+	case <-time.After(waitTimeout):
+		t.Fatal("no event reported in time")
+		return reportedEvent{}
+	}
+}
+
+// command sends a command through the control channel like the admin does
+func (a *fakeAdmin) command(sessionID, command string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return a.hub.Send(ctx, testStationID, sessionID, command)
+}
+
+// testOptions returns the options of a well-behaved station reporting to admin
+func testOptions(admin *fakeAdmin) options {
+	return options{
+		Admin:           admin.server.URL,
+		StationID:       testStationID,
+		Token:           testToken,
+		Speed:           1,
+		Score:           80,
+		PayloadVersions: []int{uepayload.Version},
+		Battery:         -1,
+		PayloadStatus:   http.StatusOK,
+	}
+}
+
+// startStation runs the mock station in-process: its payload handler behind an httptest
+// server and its control channel connected to admin. It returns the payload endpoint.
+func startStation(t *testing.T, admin *fakeAdmin, opts options, script []Step) string {
+	t.Helper()
+	s := &station{
+		opts:   opts,
+		script: script,
+		admin:  newAdminClient(opts),
+		runs:   make(map[string]*run),
+	}
+	go s.admin.serveControl(s)
+
+	deadline := time.Now().Add(waitTimeout)
+	for !admin.hub.Connected(testStationID) {
+		if time.Now().After(deadline) {
+			t.Fatal("the mock station did not connect to the control channel")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(s.handlePayload))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// sendPayload delivers a session payload to the station like the admin's dispatcher does
+func sendPayload(client *unreal.Client, endpoint, sessionID string, version int) error {
+	body := fmt.Sprintf(`{"version": %d, "sessionId": %q, "status": "starting",
+		"scenario": {"name": "Fire drill"}, "avatar": {"name": "Alex"},
+		"observer": {"name": "Coach", "rubric": [{"id": "greeting", "min": 0, "max": 10}]}}`, version, sessionID)
+	return client.Send(context.Background(), unreal.Target{Endpoint: endpoint, AuthToken: testToken}, []byte(body))
+}
+
+// pausableScript starts, then waits long enough for the test to send commands
+func pausableScript() []Step {
+	return []Step{
+		{Type: "started"},
+		{After: Duration(300 * time.Millisecond), Type: "phase-changed", Phase: "debrief"},
+		{Type: "completed"},
+	}
+}
+
+func TestMockStationPlaysSession(t *testing.T) {
+	admin := newFakeAdmin(t)
+	endpoint := startStation(t, admin, testOptions(admin), pausableScript())
+
+	if err := sendPayload(unreal.NewClient(nil), endpoint, "session_1", uepayload.Version); err != nil {
+		t.Fatalf("payload refused: %v", err)
+	}
+	if e := admin.nextEvent(t); e.sessionID != "session_1" || e.Type != "started" {
+		t.Fatalf("first event = %s for %s, want started for session_1", e.Type, e.sessionID)
+	}
+
+	if err := admin.command("session_1", unreal.CommandPause, waitTimeout); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	var rejected *unreal.RejectedError
+	if err := admin.command("session_1", unreal.CommandPause, waitTimeout); !errors.As(err, &rejected) {
+		t.Errorf("pausing a paused session = %v, want a rejected ack", err)
+	}
+	// Paused sessions hold still
+	select {
+	case e := <-admin.events:
+		t.Fatalf("paused session reported %s", e.Type)
+	case <-time.After(500 * time.Millisecond):
+	}
+	if err := admin.command("session_1", unreal.CommandResume, waitTimeout); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+
+	if e := admin.nextEvent(t); e.Type != "phase-changed" || e.Phase != "debrief" {
+		t.Errorf("second event = %s %s, want phase-changed debrief", e.Type, e.Phase)
+	}
+	completed := admin.nextEvent(t)
+	if completed.Type != "completed" || completed.Score == nil || *completed.Score != 80 {
+		t.Fatalf("last event = %+v, want completed with score 80", completed.event)
+	}
+	if got := completed.Ratings["greeting"]; got != 8 {
+		t.Errorf("greeting rated %d, want 8", got)
+	}
+	if completed.ID != "session_1-3" {
+		t.Errorf("completed event ID = %q, want session_1-3", completed.ID)
+	}
+}
+
+func TestMockStationCompleteCommandEndsSession(t *testing.T) {
+	admin := newFakeAdmin(t)
+	endpoint := startStation(t, admin, testOptions(admin), pausableScript())
+
+	if err := sendPayload(unreal.NewClient(nil), endpoint, "session_1", uepayload.Version); err != nil {
+		t.Fatal(err)
+	}
+	admin.nextEvent(t)
+	if err := admin.command("session_1", unreal.CommandComplete, waitTimeout); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	select {
+	case e := <-admin.events:
+		t.Errorf("a session completed by the admin reported %s", e.Type)
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
+func TestMockStationCrash(t *testing.T) {
+	admin := newFakeAdmin(t)
+	opts := testOptions(admin)
+	opts.CrashAfter = 100 * time.Millisecond
+	endpoint := startStation(t, admin, opts, pausableScript())
+
+	if err := sendPayload(unreal.NewClient(nil), endpoint, "session_1", uepayload.Version); err != nil {
+		t.Fatal(err)
+	}
+	if e := admin.nextEvent(t); e.Type != "started" {
+		t.Fatalf("first event = %s, want started", e.Type)
+	}
+	crashed := admin.nextEvent(t)
+	if crashed.Type != "crashed" || !strings.Contains(crashed.Reason, "crash") {
+		t.Errorf("second event = %s (%q), want crashed with a reason", crashed.Type, crashed.Reason)
+	}
+}
+
+func TestMockStationSlowAck(t *testing.T) {
+	admin := newFakeAdmin(t)
+	opts := testOptions(admin)
+	opts.AckDelay = time.Second
+	endpoint := startStation(t, admin, opts, pausableScript())
+
+	if err := sendPayload(unreal.NewClient(nil), endpoint, "session_1", uepayload.Version); err != nil {
+		t.Fatal(err)
+	}
+	admin.nextEvent(t)
+	if err := admin.command("session_1", unreal.CommandPause, 100*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("command to a slow station = %v, want the wait to time out", err)
+	}
+}
+
+func TestMockStationRejectsCommands(t *testing.T) {
+	admin := newFakeAdmin(t)
+	opts := testOptions(admin)
+	opts.RejectCommands = true
+	endpoint := startStation(t, admin, opts, pausableScript())
+
+	if err := sendPayload(unreal.NewClient(nil), endpoint, "session_1", uepayload.Version); err != nil {
+		t.Fatal(err)
+	}
+	admin.nextEvent(t)
+	var rejected *unreal.RejectedError
+	if err := admin.command("session_1", unreal.CommandPause, waitTimeout); !errors.As(err, &rejected) {
+		t.Errorf("command = %v, want a rejected ack", err)
+	}
+}
+
+func TestMockStationPayloadFailures(t *testing.T) {
+	tests := []struct {
+		name      string
+		change    func(opts *options)
+		version   int
+		status    int  // expected StatusError code, or 0 for a transport error
+		permanent bool // whether the dispatcher would give up right away
+	}{
+		{"timeout", func(opts *options) { opts.PayloadDelay = time.Second }, uepayload.Version, 0, false},
+		{"server error", func(opts *options) { opts.PayloadStatus = http.StatusServiceUnavailable }, uepayload.Version, http.StatusServiceUnavailable, false},
+		{"unsupported version", func(opts *options) {}, uepayload.Version + 1, http.StatusBadRequest, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			admin := newFakeAdmin(t)
+			opts := testOptions(admin)
+			tt.change(&opts)
+			endpoint := startStation(t, admin, opts, pausableScript())
+
+			client := unreal.NewClient(&http.Client{Timeout: 100 * time.Millisecond})
+			err := sendPayload(client, endpoint, "session_1", tt.version)
+			if err == nil {
+				t.Fatal("payload accepted, want a failure")
+			}
+
+			var statusErr *unreal.StatusError
+			isStatus := errors.As(err, &statusErr)
+			switch {
+			case tt.status == 0 && isStatus:
+				t.Errorf("error = %v, want a transport error", err)
+			case tt.status != 0 && (!isStatus || statusErr.StatusCode != tt.status):
+				t.Errorf("error = %v, want status %d", err, tt.status)
+			case isStatus && statusErr.Permanent() != tt.permanent:
+				t.Errorf("permanent = %t, want %t", statusErr.Permanent(), tt.permanent)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+// cmd/mockue/script.go
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+// Step is one event of a session script, reported After the previous step
+type Step struct {
+	After  Duration `json:"after"`
+	Type   string   `json:"type"`
+	Phase  string   `json:"phase,omitempty"`
+	Text   string   `json:"text,omitempty"`
+	Score  *int     `json:"score,omitempty"`
+	Reason string   `json:"reason,omitempty"`
+	// Ratings rate the observer rubric items by ID on completed steps
+	Ratings map[string]int `json:"ratings,omitempty"`
+}
+
+// Duration is a time.Duration written as a string such as "1.5s" in scripts
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// defaultScript is a short customer service conversation. Texts may name the
+// {avatar}, {observer} and {scenario} of the session.
+func defaultScript() []Step {
+	second := Duration(time.Second)
+	return []Step{
+		{After: 0, Type: "started"},
+		{After: 2 * second, Type: "phase-changed", Phase: "introduction"},
+		{After: second, Type: "avatar-utterance", Text: "Hello, I'm {avatar}. I need some help with my request."},
+		{After: 4 * second, Type: "trainee-utterance", Text: "Good morning, of course. What can I do for you?"},
+		{After: 3 * second, Type: "avatar-utterance", Text: "I have already been waiting for a long time."},
+		{After: 4 * second, Type: "trainee-utterance", Text: "I'm sorry about the wait. Let's sort this out together."},
+		{After: 2 * second, Type: "phase-changed", Phase: "resolution"},
+		{After: 3 * second, Type: "observer-intervention", Text: "{observer}: remember to confirm the next steps."},
+		{After: 4 * second, Type: "trainee-utterance", Text: "I will submit your request today and send you a confirmation."},
+		{After: 3 * second, Type: "avatar-utterance", Text: "Thank you, that helps a lot."},
+		{After: 2 * second, Type: "phase-changed", Phase: "debrief"},
+		{After: 2 * second, Type: "completed"},
+	}
+}
+
+// loadScript reads a script from a JSON array of steps
+func loadScript(path string) ([]Step, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var steps []Step
+	if err := json.Unmarshal(data, &steps); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("%s has no steps", path)
+	}
+	return steps, nil
+}
+
+// run plays the script of one session. Commands from the control channel pause,
+// resume or end it while it waits for the next step.
+type run struct {
+	station  *station
+	payload  payload
+	texts    *strings.Replacer
+	commands chan string
+	replies  chan string
+
+	// played is the time spent playing, without pauses
+	played time.Duration
+	sent   int
+}
+
+func newRun(s *station, p payload) *run {
+	return &run{
+		station:  s,
+		payload:  p,
+		texts:    strings.NewReplacer("{avatar}", p.Avatar.Name, "{observer}", p.Observer.Name, "{scenario}", p.Scenario.Name),
+		commands: make(chan string),
+		replies:  make(chan string),
+	}
+}
+
+// play reports the steps of the script at their time, or a crash when -crash-after is reached
+func (r *run) play() {
+	defer r.station.finished(r.payload.SessionID)
+	opts := r.station.opts
+	log.Printf("Session %s: playing %d steps at %gx speed", r.payload.SessionID, len(r.station.script), opts.Speed)
+
+	for _, step := range r.station.script {
+		wait := time.Duration(float64(step.After) / opts.Speed)
+
+		if opts.CrashAfter > 0 && r.played+wait >= opts.CrashAfter {
+			if !r.wait(opts.CrashAfter - r.played) {
+				return
+			}
+			log.Printf("Session %s: simulating a crash", r.payload.SessionID)
+			r.report(Step{Type: "crashed", Reason: "mock station crash after " + opts.CrashAfter.String()})
+			return
+		}
+
+		if !r.wait(wait) {
+			return
+		}
+		if step.Type == "completed" && step.Score == nil {
+			score := opts.Score
+			step.Score = &score
+		}
+		if step.Type == "completed" && step.Ratings == nil {
+			step.Ratings = r.ratings(opts.Score)
+		}
+		r.report(step)
+	}
+	log.Printf("Session %s: script finished", r.payload.SessionID)
+}
+
+// ratings rates every rubric item of the session's observer at score percent of its scale
+func (r *run) ratings(score int) map[string]int {
+	if len(r.payload.Observer.Rubric) == 0 {
+		return nil
+	}
+	ratings := make(map[string]int, len(r.payload.Observer.Rubric))
+	for _, item := range r.payload.Observer.Rubric {
+		ratings[item.ID] = item.Min + int(math.Round(float64(item.Max-item.Min)*float64(score)/100))
+	}
+	return ratings
+}
+
+// wait lets d of playing time pass, holding still while the session is paused.
+// It returns false when the session was completed from the admin.
+func (r *run) wait(d time.Duration) bool {
+	paused := false
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	started := time.Now()
+
+	for {
+		var fire <-chan time.Time
+		if !paused {
+			fire = timer.C
+		}
+		select {
+		case <-fire:
+			r.played += d
+			return true
+		case command := <-r.commands:
+			switch {
+			case command == "pause" && !paused:
+				timer.Stop()
+				elapsed := time.Since(started)
+				r.played += elapsed
+				d -= elapsed
+				paused = true
+				r.replies <- ""
+			case command == "resume" && paused:
+				timer.Reset(d)
+				started = time.Now()
+				paused = false
+				r.replies <- ""
+			case command == "complete" || command == "abort":
+				r.replies <- ""
+				log.Printf("Session %s: ended by a %s command from the admin", r.payload.SessionID, command)
+				return false
+			default:
+				r.replies <- fmt.Sprintf("cannot %s in the current state", command)
+			}
+		}
+	}
+}
+
+// command hands a control channel command to the script and returns the reason it
+// was refused, or "" when it was applied
+func (r *run) command(command string) string {
+	select {
+	case r.commands <- command:
+		return <-r.replies
+	case <-time.After(time.Second):
+		return "session is not waiting for commands"
+	}
+}
+
+// report sends one step to the admin as an event
+func (r *run) report(step Step) {
+	r.sent++
+	event := event{
+		ID:      fmt.Sprintf("%s-%d", r.payload.SessionID, r.sent),
+		Type:    step.Type,
+		At:      time.Now(),
+		Phase:   step.Phase,
+		Text:    r.texts.Replace(step.Text),
+		Score:   step.Score,
+		Reason:  step.Reason,
+		Ratings: step.Ratings,
+	}
+	if event.Text != "" {
+		log.Printf("Session %s: %s: %s", r.payload.SessionID, event.Type, event.Text)
+	} else {
+		log.Printf("Session %s: %s %s", r.payload.SessionID, event.Type, event.Phase)
+	}
+	r.station.admin.reportEvent(r.payload.SessionID, event)
+}
@@ -0,0 +1,152 @@
+// cmd/server/commands.go
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/internal/backup"
+	"github.com/saladinomario/vr-training-admin/internal/config"
+	"github.com/saladinomario/vr-training-admin/internal/models"
+)
+
+// commands are the maintenance subcommands that run instead of the server
+var commands = map[string]func(args []string) error{
+	"migrate": runMigrate,
+	"backup":  runBackup,
+	"restore": runRestore,
+}
+
+// runMigrate upgrades the persisted data to the current schema, or only reports
+// the pending changes when -dry-run is given
+func runMigrate(args []string) error {
+	cfg := config.FromEnv()
+
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	cfg.RegisterFlags(fs)
+	dryRun := fs.Bool("dry-run", false, "report what would change without writing anything")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	reports, err := models.MigrateDataDir(cfg, *dryRun)
+	for _, report := range reports {
+		if !report.Pending() {
+			fmt.Printf("%s: up to date (version %d)\n", report.Target, report.ToVersion)
+			continue
+		}
+		verb := "migrated"
+		if *dryRun {
+			verb = "would migrate"
+		}
+		fmt.Printf("%s: %s from version %d to %d\n", report.Target, verb, report.FromVersion, report.ToVersion)
+		for _, change := range report.Changes {
+			fmt.Printf("  %s\n", change)
+		}
+	}
+	return err
+}
+
+// runBackup writes an archive of all data to the file given with -o, leaving the secrets
+// out unless -secrets is given
+func runBackup(args []string) error {
+	cfg := config.FromEnv()
+
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	cfg.RegisterFlags(fs)
+	output := fs.String("o", backup.FileName(time.Now()), "archive file to write")
+	withSecrets := fs.Bool("secrets", false, "include the LLM credentials and station auth tokens")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	stores, err := models.OpenStores(cfg)
+	if err != nil {
+		return err
+	}
+	defer stores.Close()
+
+	snap := stores.Snapshot()
+	if !*withSecrets {
+		snap = snap.WithoutSecrets()
+	}
+	manifest, err := backup.WriteFile(*output, snap)
+	if err != nil {
+		return err
+	}
+	printManifest(*output, manifest)
+	return nil
+}
+
+// runRestore replaces all data with the content of an archive. The archive is fully
+// validated first, and the current data is saved to the backups directory of the data
+// directory before anything is replaced. The server should not be running.
+func runRestore(args []string) error {
+	cfg := config.FromEnv()
+
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	cfg.RegisterFlags(fs)
+	verifyOnly := fs.Bool("verify", false, "only validate the archive without restoring it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: restore [flags] <archive>")
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	archive := fs.Arg(0)
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, manifest, err := backup.Read(f)
+	if err != nil {
+		return err
+	}
+	printManifest(archive, manifest)
+	if *verifyOnly {
+		fmt.Println("archive is valid")
+		return nil
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	stores, err := models.OpenStores(cfg)
+	if err != nil {
+		return err
+	}
+	defer stores.Close()
+
+	safety := backup.PreRestorePath(cfg.DataDir, time.Now())
+	if _, err := backup.Restore(f, stores, safety); err != nil {
+		return err
+	}
+	fmt.Printf("previous data saved to %s\n", safety)
+	fmt.Println("restore complete")
+	return nil
+}
+
+func printManifest(path string, manifest *backup.Manifest) {
+	fmt.Printf("%s: schema version %d, created %s\n",
+		path, manifest.SchemaVersion, manifest.CreatedAt.Format(time.RFC3339))
+	for _, file := range manifest.Files {
+		fmt.Printf("  %-16s %4d items  sha256 %s\n", file.Name, file.Items, file.SHA256[:12])
+	}
+}
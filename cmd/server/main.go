@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"os"
 
+	"github.com/saladinomario/vr-training-admin/internal/config"
 	"github.com/saladinomario/vr-training-admin/internal/handlers"
 )
 
@@ -31,6 +34,14 @@ func setupRoutes() *http.ServeMux {
 	log.Println("Setting up observer routes")
 	handlers.SetupObserverRoutes(mux)
 
+	// Register trainee routes
+	log.Println("Setting up trainee routes")
+	handlers.SetupTraineeRoutes(mux)
+
+	// Register content pack import and export routes
+	log.Println("Setting up content pack routes")
+	handlers.SetupPackRoutes(mux)
+
 	// Register settings routes
 	log.Println("Setting up settings routes")
 	handlers.SetupSettingsRoutes(mux)
@@ -39,6 +50,22 @@ func setupRoutes() *http.ServeMux {
 	log.Println("Setting up session routes")
 	handlers.SetupSessionRoutes(mux)
 
+	// Register the schedule of sessions booked in advance
+	log.Println("Setting up schedule routes")
+	handlers.SetupScheduleRoutes(mux)
+
+	// Register the live station overview
+	log.Println("Setting up station routes")
+	handlers.SetupStationRoutes(mux)
+
+	// Register the API stations use to report session events
+	log.Println("Setting up Unreal Engine event routes")
+	handlers.SetupEventRoutes(mux)
+
+	// Register the WebSocket control channel stations connect to
+	log.Println("Setting up station control routes")
+	handlers.SetupControlRoutes(mux)
+
 	// Serve static files
 	log.Println("Setting up static file server")
 	fs := http.FileServer(http.Dir("static"))
@@ -58,13 +85,33 @@ func printRegisteredRoutes() {
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			if err := command(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	if err := handlers.InitStores(cfg); err != nil {
+		log.Fatalf("Error initializing stores: %v", err)
+	}
+
+	handlers.StartBackgroundJobs(context.Background())
+
 	mux := setupRoutes()
 	printRegisteredRoutes()
 
-	log.Println("Server starting on :8080")
-	log.Println("Visit http://localhost:8080 to view the application")
+	log.Printf("Server starting on %s", cfg.Addr)
+	log.Printf("Visit %s to view the application", cfg.URL())
 
-	if err := http.ListenAndServe(":8080", mux); err != nil {
+	if err := http.ListenAndServe(cfg.Addr, mux); err != nil {
 		log.Fatal(err)
 	}
 }
@@ -0,0 +1,271 @@
+// internal/backup/backup.go
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/internal/models"
+)
+
+// Archive format identifiers written into every manifest
+const (
+	Format        = "vr-training-admin-backup"
+	FormatVersion = 1
+	ManifestName  = "manifest.json"
+)
+
+// maxEntrySize bounds every file read from an archive
+const maxEntrySize = 256 << 20
+
+var ErrInvalidArchive = errors.New("invalid backup archive")
+
+// Manifest describes the content of a backup archive
+type Manifest struct {
+	Format        string         `json:"format"`
+	FormatVersion int            `json:"formatVersion"`
+	SchemaVersion int            `json:"schemaVersion"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	Files         []ManifestFile `json:"files"`
+
+	// SecretsOmitted marks an archive without the LLM credentials and station auth tokens.
+	// Restoring it keeps the secrets the admin already has.
+	SecretsOmitted bool `json:"secretsOmitted,omitempty"`
+}
+
+// ManifestFile describes one data file of the archive
+type ManifestFile struct {
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	Items  int    `json:"items"`
+}
+
+// FileName returns the suggested name for an archive created at t
+func FileName(t time.Time) string {
+	return "vr-admin-backup-" + t.Format("20060102-150405") + ".tar.gz"
+}
+
+// Write stores snap as a gzipped tar archive with a manifest listing the checksum of every file
+func Write(w io.Writer, snap *models.Snapshot) (*Manifest, error) {
+	manifest := &Manifest{
+		Format:         Format,
+		FormatVersion:  FormatVersion,
+		SchemaVersion:  models.CurrentSchemaVersion,
+		CreatedAt:      time.Now().UTC(),
+		SecretsOmitted: snap.SecretsOmitted,
+	}
+
+	contents := make(map[string][]byte)
+	for _, kind := range models.DocumentKinds() {
+		data, count, err := snap.EncodeDocument(kind)
+		if err != nil {
+			return nil, fmt.Errorf("encoding %s: %w", kind, err)
+		}
+		name := models.DocumentFileName(kind)
+		sum := sha256.Sum256(data)
+		manifest.Files = append(manifest.Files, ManifestFile{
+			Name:   name,
+			Kind:   kind,
+			Size:   int64(len(data)),
+			SHA256: hex.EncodeToString(sum[:]),
+			Items:  count,
+		})
+		contents[name] = data
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	if err := writeEntry(tw, ManifestName, manifestData, manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+	for _, file := range manifest.Files {
+		if err := writeEntry(tw, file.Name, contents[file.Name], manifest.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func writeEntry(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// Read parses and fully validates an archive: the manifest must be present and supported,
+// every listed file must match its size and checksum, no unlisted files may be present,
+// and the decoded data must form a consistent snapshot. Nothing is changed by Read.
+func Read(r io.Reader) (*models.Snapshot, *Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	defer gz.Close()
+
+	entries := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			return nil, nil, fmt.Errorf("%w: unexpected entry %s", ErrInvalidArchive, header.Name)
+		}
+		if _, dup := entries[header.Name]; dup {
+			return nil, nil, fmt.Errorf("%w: duplicate entry %s", ErrInvalidArchive, header.Name)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxEntrySize+1))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		if len(data) > maxEntrySize {
+			return nil, nil, fmt.Errorf("%w: entry %s is too large", ErrInvalidArchive, header.Name)
+		}
+		entries[header.Name] = data
+	}
+
+	manifestData, ok := entries[ManifestName]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: missing %s", ErrInvalidArchive, ManifestName)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("%w: manifest: %v", ErrInvalidArchive, err)
+	}
+	if manifest.Format != Format {
+		return nil, nil, fmt.Errorf("%w: not a %s archive", ErrInvalidArchive, Format)
+	}
+	if manifest.FormatVersion > FormatVersion {
+		return nil, nil, fmt.Errorf("%w: archive format %d is newer than supported %d",
+			ErrInvalidArchive, manifest.FormatVersion, FormatVersion)
+	}
+	if manifest.SchemaVersion > models.CurrentSchemaVersion {
+		return nil, nil, fmt.Errorf("%w: schema version %d is newer than supported %d",
+			ErrInvalidArchive, manifest.SchemaVersion, models.CurrentSchemaVersion)
+	}
+
+	snap := &models.Snapshot{SecretsOmitted: manifest.SecretsOmitted}
+	listed := map[string]bool{ManifestName: true}
+	kinds := make(map[string]bool)
+	for _, file := range manifest.Files {
+		data, ok := entries[file.Name]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: missing %s", ErrInvalidArchive, file.Name)
+		}
+		if int64(len(data)) != file.Size {
+			return nil, nil, fmt.Errorf("%w: %s has size %d, expected %d",
+				ErrInvalidArchive, file.Name, len(data), file.Size)
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != file.SHA256 {
+			return nil, nil, fmt.Errorf("%w: checksum mismatch for %s", ErrInvalidArchive, file.Name)
+		}
+		if err := snap.DecodeDocument(file.Kind, data); err != nil {
+			return nil, nil, fmt.Errorf("%w: %s: %v", ErrInvalidArchive, file.Name, err)
+		}
+		listed[file.Name] = true
+		kinds[file.Kind] = true
+	}
+
+	for name := range entries {
+		if !listed[name] {
+			return nil, nil, fmt.Errorf("%w: unlisted entry %s", ErrInvalidArchive, name)
+		}
+	}
+	for _, kind := range models.DocumentKinds() {
+		if !kinds[kind] && !models.DocumentOptional(kind) {
+			return nil, nil, fmt.Errorf("%w: archive has no %s", ErrInvalidArchive, kind)
+		}
+	}
+	if err := snap.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	return snap, &manifest, nil
+}
+
+// PreRestorePath returns where the current data is saved before a restore started at t
+func PreRestorePath(dataDir string, t time.Time) string {
+	return filepath.Join(dataDir, "backups", "pre-restore-"+t.Format("20060102-150405")+".tar.gz")
+}
+
+// WriteFile archives snap to a new file at path, creating its directory if needed
+func WriteFile(path string, snap *models.Snapshot) (*Manifest, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := Write(f, snap)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Restore validates the archive read from r and only then replaces the content of stores.
+// The current content, secrets included, is first saved to safetyPath so a bad restore can
+// be undone. An archive without secrets keeps the current ones.
+func Restore(r io.Reader, stores *models.Stores, safetyPath string) (*Manifest, error) {
+	snap, manifest, err := Read(r)
+	if err != nil {
+		return nil, err
+	}
+	current := stores.Snapshot()
+	if _, err := WriteFile(safetyPath, current); err != nil {
+		return manifest, fmt.Errorf("saving current data before restore: %w", err)
+	}
+	if snap.SecretsOmitted {
+		snap.KeepSecrets(current)
+	}
+	if err := stores.Restore(snap); err != nil {
+		return manifest, err
+	}
+	return manifest, nil
+}
@@ -0,0 +1,261 @@
+// internal/backup/backup_test.go
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/internal/config"
+	"github.com/saladinomario/vr-training-admin/internal/models"
+	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+	"github.com/saladinomario/vr-training-admin/templates/components/settings"
+	"github.com/saladinomario/vr-training-admin/templates/components/trainees"
+)
+
+func testSnapshot() *models.Snapshot {
+	return &models.Snapshot{
+		Scenarios: []scenarios.Scenario{{ID: "scenario_1", Name: "Fire drill"}},
+		Trainees:  []trainees.Trainee{{ID: "trainee_1", Name: "Sam"}},
+		Sessions: []*sessions.Session{{
+			ID:            "session_1",
+			ScenarioID:    "scenario_1",
+			TraineeID:     "trainee_1",
+			Status:        sessions.StatusCompleted,
+			Configuration: &sessions.Configuration{},
+		}},
+		GeneralSettings: models.DefaultGeneralSettings(),
+	}
+}
+
+func writeArchive(t *testing.T, snap *models.Snapshot) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := Write(&buf, snap); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// rewriteArchive copies archive, passing every entry through edit. Entries for which edit
+// returns nil are dropped; extra entries are appended at the end.
+func rewriteArchive(t *testing.T, archive []byte, edit func(name string, data []byte) []byte, extra map[string][]byte) []byte {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+
+	var buf bytes.Buffer
+	out := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(out)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data = edit(header.Name, data); data == nil {
+			continue
+		}
+		if err := writeEntry(tw, header.Name, data, header.ModTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, data := range extra {
+		if err := writeEntry(tw, name, data, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// editManifest returns an edit function that changes the decoded manifest
+func editManifest(t *testing.T, change func(m *Manifest)) func(string, []byte) []byte {
+	return func(name string, data []byte) []byte {
+		if name != ManifestName {
+			return data
+		}
+		var m Manifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			t.Fatal(err)
+		}
+		change(&m)
+		changed, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return changed
+	}
+}
+
+func TestReadRoundTrip(t *testing.T) {
+	snap, manifest, err := Read(bytes.NewReader(writeArchive(t, testSnapshot())))
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if manifest.Format != Format || manifest.SchemaVersion != models.CurrentSchemaVersion {
+		t.Errorf("manifest = %s v%d, want %s v%d", manifest.Format, manifest.SchemaVersion, Format, models.CurrentSchemaVersion)
+	}
+	if len(snap.Sessions) != 1 || snap.Sessions[0].ID != "session_1" {
+		t.Errorf("read %d sessions, want session_1", len(snap.Sessions))
+	}
+}
+
+func TestReadRejectsInvalidArchives(t *testing.T) {
+	sessionsFile := models.DocumentFileName(models.DocumentSessions)
+	valid := writeArchive(t, testSnapshot())
+	keep := func(name string, data []byte) []byte { return data }
+
+	dangling := testSnapshot()
+	dangling.Trainees = nil
+
+	tests := []struct {
+		name    string
+		archive []byte
+	}{
+		{"not gzip", []byte("not an archive")},
+		{"missing manifest", rewriteArchive(t, valid, func(name string, data []byte) []byte {
+			if name == ManifestName {
+				return nil
+			}
+			return data
+		}, nil)},
+		{"wrong format", rewriteArchive(t, valid, editManifest(t, func(m *Manifest) { m.Format = "something-else" }), nil)},
+		{"newer format version", rewriteArchive(t, valid, editManifest(t, func(m *Manifest) { m.FormatVersion = FormatVersion + 1 }), nil)},
+		{"newer schema version", rewriteArchive(t, valid, editManifest(t, func(m *Manifest) { m.SchemaVersion = models.CurrentSchemaVersion + 1 }), nil)},
+		{"checksum mismatch", rewriteArchive(t, valid, func(name string, data []byte) []byte {
+			if name == sessionsFile {
+				// Same size, different content
+				return bytes.Replace(data, []byte("completed"), []byte("COMPLETED"), 1)
+			}
+			return data
+		}, nil)},
+		{"size mismatch", rewriteArchive(t, valid, func(name string, data []byte) []byte {
+			if name == sessionsFile {
+				return append(data, ' ')
+			}
+			return data
+		}, nil)},
+		{"listed file missing", rewriteArchive(t, valid, func(name string, data []byte) []byte {
+			if name == sessionsFile {
+				return nil
+			}
+			return data
+		}, nil)},
+		{"unlisted entry", rewriteArchive(t, valid, keep, map[string][]byte{"extra.json": []byte("{}")})},
+		{"required document missing", rewriteArchive(t, valid, func(name string, data []byte) []byte {
+			if name == sessionsFile {
+				return nil
+			}
+			return editManifest(t, func(m *Manifest) {
+				files := m.Files[:0]
+				for _, f := range m.Files {
+					if f.Kind != models.DocumentSessions {
+						files = append(files, f)
+					}
+				}
+				m.Files = files
+			})(name, data)
+		}, nil)},
+		{"dangling reference", writeArchive(t, dangling)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Read(bytes.NewReader(tt.archive))
+			if !errors.Is(err, ErrInvalidArchive) {
+				t.Errorf("Read() = %v, want ErrInvalidArchive", err)
+			}
+		})
+	}
+}
+
+func TestRestoreKeepsSecretsOmittedFromArchive(t *testing.T) {
+	dir := t.TempDir()
+	stores, err := models.OpenStores(config.Config{DataDir: dir, Storage: config.StorageMemory})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stores.Close()
+	if err := stores.Settings.UpdateLLMSettings(settings.LLMSettings{Provider: "openai", APIKey: "sk-current", ServiceAccountKey: "{}"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := stores.Settings.UpdateStations([]settings.Station{
+		{ID: "station_1", Name: "Bay 1", Endpoint: "http://bay1:8080", AuthToken: "token-current"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := testSnapshot()
+	snap.LLMSettings = settings.LLMSettings{Provider: "openai", APIKey: "sk-archived"}
+	snap.Stations = []settings.Station{
+		{ID: "station_1", Name: "Bay 1", Endpoint: "http://bay1:8080", AuthToken: "token-archived"},
+		{ID: "station_2", Name: "Bay 2", Endpoint: "http://bay2:8080", AuthToken: "token-new"},
+	}
+	archive := writeArchive(t, snap.WithoutSecrets())
+	for _, secret := range []string{"sk-archived", "token-archived", "token-new"} {
+		if bytes.Contains(decompress(t, archive), []byte(secret)) {
+			t.Errorf("archive without secrets contains %s", secret)
+		}
+	}
+
+	manifest, err := Restore(bytes.NewReader(archive), stores, filepath.Join(dir, "backups", "pre-restore.tar.gz"))
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if !manifest.SecretsOmitted {
+		t.Error("manifest does not record that the secrets were left out")
+	}
+	if llm := stores.Settings.GetLLMSettings(); llm.APIKey != "sk-current" || llm.ServiceAccountKey != "{}" {
+		t.Errorf("LLM credentials = %q, %q, want the current ones", llm.APIKey, llm.ServiceAccountKey)
+	}
+	want := map[string]string{"station_1": "token-current", "station_2": ""}
+	for _, station := range stores.Settings.GetStations() {
+		if station.AuthToken != want[station.ID] {
+			t.Errorf("%s has token %q, want %q", station.ID, station.AuthToken, want[station.ID])
+		}
+	}
+
+	// An archive with secrets replaces them
+	if _, err := Restore(bytes.NewReader(writeArchive(t, snap)), stores, filepath.Join(dir, "backups", "pre-restore-2.tar.gz")); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if llm := stores.Settings.GetLLMSettings(); llm.APIKey != "sk-archived" {
+		t.Errorf("API key = %q, want the archived one", llm.APIKey)
+	}
+}
+
+// decompress returns the tar stream of a gzipped archive
+func decompress(t *testing.T, archive []byte) []byte {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
@@ -0,0 +1,137 @@
+// internal/config/config.go
+package config
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"strings"
+)
+
+// Storage backend names
+const (
+	StorageMemory = "memory"
+	StorageFile   = "file"
+	StorageSQLite = "sqlite"
+)
+
+// Config holds the runtime configuration of the admin server
+type Config struct {
+	Addr    string // HTTP listen address
+	DataDir string // Directory holding persisted data
+	Storage string // Storage backend name
+
+	// TrustedProxies lists the addresses or CIDR ranges of reverse proxies whose
+	// X-Remote-User header names the user. Empty means the header is ignored.
+	TrustedProxies string
+}
+
+// Default returns the configuration used when nothing is overridden
+func Default() Config {
+	return Config{
+		Addr:    ":8080",
+		DataDir: "./data",
+		Storage: StorageFile,
+	}
+}
+
+// FromEnv returns the defaults overridden by any VR_ADMIN_* environment variables
+func FromEnv() Config {
+	cfg := Default()
+
+	if v := os.Getenv("VR_ADMIN_ADDR"); v != "" {
+		cfg.Addr = v
+	}
+	if v := os.Getenv("VR_ADMIN_DATA_DIR"); v != "" {
+		cfg.DataDir = v
+	}
+	if v := os.Getenv("VR_ADMIN_STORAGE"); v != "" {
+		cfg.Storage = v
+	}
+	if v := os.Getenv("VR_ADMIN_TRUSTED_PROXIES"); v != "" {
+		cfg.TrustedProxies = v
+	}
+
+	return cfg
+}
+
+// RegisterFlags binds the storage related flags of fs to c
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.DataDir, "data-dir", c.DataDir, "directory for persisted data")
+	fs.StringVar(&c.Storage, "storage", c.Storage, "storage backend (memory, file, sqlite)")
+}
+
+// Load builds the server configuration from environment variables and command line flags.
+// Flags take precedence over environment variables, which take precedence over defaults.
+func Load(args []string) (Config, error) {
+	cfg := FromEnv()
+
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	fs.StringVar(&cfg.Addr, "addr", cfg.Addr, "HTTP listen address")
+	fs.StringVar(&cfg.TrustedProxies, "trusted-proxies", cfg.TrustedProxies, "comma separated addresses or CIDR ranges of reverse proxies allowed to set X-Remote-User")
+	cfg.RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// Validate checks that the configuration values are usable
+func (c Config) Validate() error {
+	switch c.Storage {
+	case StorageMemory, StorageFile, StorageSQLite:
+	default:
+		return fmt.Errorf("unknown storage backend %q", c.Storage)
+	}
+	if c.DataDir == "" {
+		return fmt.Errorf("data directory must not be empty")
+	}
+	if _, err := c.ProxyPrefixes(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ProxyPrefixes parses TrustedProxies. A plain address is a range holding only that address.
+func (c Config) ProxyPrefixes() ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, entry := range strings.Split(c.TrustedProxies, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// URL returns the address a browser on this machine can use to reach the server
+func (c Config) URL() string {
+	host, port, err := net.SplitHostPort(c.Addr)
+	if err != nil {
+		return "http://" + c.Addr
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
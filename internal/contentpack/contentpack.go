@@ -0,0 +1,389 @@
+// internal/contentpack/contentpack.go
+package contentpack
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/saladinomario/vr-training-admin/internal/models"
+	"github.com/saladinomario/vr-training-admin/templates/components/avatars"
+	"github.com/saladinomario/vr-training-admin/templates/components/observers"
+	"github.com/saladinomario/vr-training-admin/templates/components/packs"
+	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
+)
+
+// Pack format identifiers
+const (
+	Format  = "vr-training-content-pack"
+	Version = 1
+)
+
+// Encodings a pack can be written in
+const (
+	EncodingJSON = "json"
+	EncodingYAML = "yaml"
+)
+
+var ErrInvalidPack = errors.New("invalid content pack")
+
+// New builds a pack holding the given items
+func New(scenarioList []scenarios.Scenario, avatarList []avatars.Avatar, observerList []observers.Observer) *packs.Pack {
+	return &packs.Pack{
+		Format:        Format,
+		Version:       Version,
+		SchemaVersion: models.CurrentSchemaVersion,
+		ExportedAt:    time.Now().UTC(),
+		Scenarios:     scenarioList,
+		Avatars:       avatarList,
+		Observers:     observerList,
+	}
+}
+
+// Selection names the items to export by kind
+type Selection struct {
+	Scenarios []string
+	Avatars   []string
+	Observers []string
+}
+
+// Empty reports whether nothing is selected
+func (s Selection) Empty() bool {
+	return len(s.Scenarios) == 0 && len(s.Avatars) == 0 && len(s.Observers) == 0
+}
+
+// Export builds a pack holding the selected items. Observers whose rubric items a selected
+// scenario maps to its success criteria are added even when they were not selected, so the
+// mapping still scores sessions after the pack is imported elsewhere.
+func Export(stores *models.Stores, selection Selection) *packs.Pack {
+	pack := New(nil, nil, nil)
+
+	scenarioIDs := toSet(selection.Scenarios)
+	for _, scenario := range stores.Scenarios.GetAll() {
+		if scenarioIDs[scenario.ID] {
+			pack.Scenarios = append(pack.Scenarios, scenario)
+		}
+	}
+	avatarIDs := toSet(selection.Avatars)
+	for _, avatar := range stores.Avatars.GetAll() {
+		if avatarIDs[avatar.ID] {
+			pack.Avatars = append(pack.Avatars, avatar)
+		}
+	}
+
+	observerIDs := toSet(selection.Observers)
+	for _, observer := range stores.Observers.GetAll() {
+		if observerIDs[observer.ID] || referencedObserver(pack.Scenarios, observer) {
+			pack.Observers = append(pack.Observers, observer)
+		}
+	}
+	return pack
+}
+
+// referencedObserver reports whether one of the scenarios maps a rubric item of observer
+func referencedObserver(scenarioList []scenarios.Scenario, observer observers.Observer) bool {
+	for _, scenario := range scenarioList {
+		for _, ids := range scenario.RubricItems {
+			for _, id := range ids {
+				if _, ok := observer.RubricItem(id); ok {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func toSet(ids []string) map[string]bool {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// Encode renders a pack as JSON or YAML. Both use the same field names.
+func Encode(pack *packs.Pack, encoding string) ([]byte, error) {
+	data, err := json.MarshalIndent(pack, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if encoding != EncodingYAML {
+		return data, nil
+	}
+
+	// JSON is valid YAML; parsing it into a node keeps the field order
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	resetStyle(&node)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resetStyle switches every node from the JSON flow style to the plain block style
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+// FileName returns the download name for a pack in encoding
+func FileName(encoding string, t time.Time) string {
+	return fmt.Sprintf("content-pack-%s.%s", t.Format("20060102-150405"), encoding)
+}
+
+// ContentType returns the MIME type for an encoding
+func ContentType(encoding string) string {
+	if encoding == EncodingYAML {
+		return "application/yaml"
+	}
+	return "application/json"
+}
+
+// Decode parses a JSON or YAML pack. Items written by an older schema version are
+// upgraded with the same migrations as the data files.
+func Decode(data []byte) (*packs.Pack, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPack, err)
+	}
+	normalized, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPack, err)
+	}
+
+	var envelope struct {
+		Format        string          `json:"format"`
+		Version       int             `json:"version"`
+		SchemaVersion int             `json:"schemaVersion"`
+		ExportedAt    time.Time       `json:"exportedAt"`
+		Scenarios     json.RawMessage `json:"scenarios"`
+		Avatars       json.RawMessage `json:"avatars"`
+		Observers     json.RawMessage `json:"observers"`
+	}
+	if err := json.Unmarshal(normalized, &envelope); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPack, err)
+	}
+	if envelope.Format != Format {
+		return nil, fmt.Errorf("%w: not a %s file", ErrInvalidPack, Format)
+	}
+	if envelope.Version > Version {
+		return nil, fmt.Errorf("%w: pack version %d is newer than supported %d", ErrInvalidPack, envelope.Version, Version)
+	}
+	if envelope.SchemaVersion > models.CurrentSchemaVersion {
+		return nil, fmt.Errorf("%w: schema version %d is newer than supported %d",
+			ErrInvalidPack, envelope.SchemaVersion, models.CurrentSchemaVersion)
+	}
+
+	// Reuse the document migrations by wrapping each list in a versioned document
+	snap := &models.Snapshot{}
+	lists := []struct {
+		kind  string
+		items json.RawMessage
+	}{
+		{models.DocumentScenarios, envelope.Scenarios},
+		{models.DocumentAvatars, envelope.Avatars},
+		{models.DocumentObservers, envelope.Observers},
+	}
+	for _, list := range lists {
+		if len(list.items) == 0 || string(list.items) == "null" {
+			continue
+		}
+		doc, err := json.Marshal(map[string]interface{}{"version": envelope.SchemaVersion, "items": list.items})
+		if err != nil {
+			return nil, err
+		}
+		if err := snap.DecodeDocument(list.kind, doc); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidPack, list.kind, err)
+		}
+	}
+	if err := snap.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPack, err)
+	}
+
+	pack := New(snap.Scenarios, snap.Avatars, snap.Observers)
+	pack.ExportedAt = envelope.ExportedAt
+	if pack.Empty() {
+		return nil, fmt.Errorf("%w: the pack contains no items", ErrInvalidPack)
+	}
+	return pack, nil
+}
+
+// Preview lists every item of the pack and whether its ID is already taken
+func Preview(pack *packs.Pack, stores *models.Stores) []packs.PreviewItem {
+	items := make([]packs.PreviewItem, 0)
+	for _, scenario := range pack.Scenarios {
+		item := packs.PreviewItem{Kind: packs.KindScenario, ID: scenario.ID, Name: scenario.Name}
+		if existing, err := stores.Scenarios.GetByID(scenario.ID); err == nil {
+			item.Conflict, item.ExistingName = true, existing.Name
+		}
+		items = append(items, item)
+	}
+	for _, avatar := range pack.Avatars {
+		item := packs.PreviewItem{Kind: packs.KindAvatar, ID: avatar.ID, Name: avatar.Name}
+		if existing, err := stores.Avatars.GetByID(avatar.ID); err == nil {
+			item.Conflict, item.ExistingName = true, existing.Name
+		}
+		items = append(items, item)
+	}
+	for _, observer := range pack.Observers {
+		item := packs.PreviewItem{Kind: packs.KindObserver, ID: observer.ID, Name: observer.Name}
+		if existing, err := stores.Observers.GetByID(observer.ID); err == nil {
+			item.Conflict, item.ExistingName = true, existing.Name
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// Apply imports the pack. New items keep their IDs; for items whose ID is taken,
+// resolutions maps the PreviewItem key to skip, overwrite or duplicate (skip by default).
+// Duplicates are created under a new ID with "(imported)" appended to the name; copied
+// observers also get new rubric item IDs, which the scenarios of the pack are mapped to
+// next to the original ones. Imported scenarios get a revision attributed to author.
+func Apply(pack *packs.Pack, stores *models.Stores, resolutions map[string]string, author string) packs.ImportResult {
+	var result packs.ImportResult
+
+	resolve := func(kind, id string, exists bool, insert, overwrite, duplicate func() error) {
+		action := "create"
+		if exists {
+			action = resolutions[kind+":"+id]
+			if action == "" {
+				action = packs.ResolveSkip
+			}
+		}
+
+		var err error
+		switch action {
+		case "create":
+			if err = insert(); err == nil {
+				result.Created++
+			}
+		case packs.ResolveOverwrite:
+			if err = overwrite(); err == nil {
+				result.Overwritten++
+			}
+		case packs.ResolveDuplicate:
+			if err = duplicate(); err == nil {
+				result.Duplicated++
+			}
+		default:
+			result.Skipped++
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s %s: %v", kind, id, err))
+		}
+	}
+
+	// Observers go first: the rubric items of copied observers get new IDs, which the
+	// scenarios imported below must be mapped to
+	copiedItems := make(map[string]string)
+	for _, observer := range pack.Observers {
+		observer := observer
+		_, err := stores.Observers.GetByID(observer.ID)
+		resolve(packs.KindObserver, observer.ID, err == nil,
+			func() error { return stores.Observers.Insert(observer) },
+			func() error { return stores.Observers.Update(observer.ID, observer) },
+			func() error {
+				original := observer.Rubric
+				observer.Name = importedName(observer.Name)
+				observer.Rubric = make([]observers.RubricItem, len(original))
+				for i, item := range original {
+					item.ID = ""
+					observer.Rubric[i] = item
+				}
+				created, err := stores.Observers.Create(observer)
+				if err != nil {
+					return err
+				}
+				for i, item := range original {
+					copiedItems[item.ID] = created.Rubric[i].ID
+				}
+				return nil
+			})
+	}
+	for _, avatar := range pack.Avatars {
+		avatar := avatar
+		_, err := stores.Avatars.GetByID(avatar.ID)
+		resolve(packs.KindAvatar, avatar.ID, err == nil,
+			func() error { return stores.Avatars.Insert(avatar) },
+			func() error { return stores.Avatars.Update(avatar.ID, avatar) },
+			func() error {
+				avatar.Name = importedName(avatar.Name)
+				_, err := stores.Avatars.Create(avatar)
+				return err
+			})
+	}
+	for _, scenario := range pack.Scenarios {
+		scenario := scenario
+		scenario.RubricItems = mapCopiedItems(scenario.RubricItems, copiedItems)
+		_, err := stores.Scenarios.GetByID(scenario.ID)
+		resolve(packs.KindScenario, scenario.ID, err == nil,
+			func() error {
+				if err := stores.Scenarios.Insert(scenario); err != nil {
+					return err
+				}
+				_, err := stores.ScenarioRevisions.Add(scenarios.Revision{
+					ScenarioID: scenario.ID, Author: author, Note: importNote, Scenario: scenario,
+				})
+				return err
+			},
+			func() error {
+				_, err := models.UpdateScenario(stores.Scenarios, stores.ScenarioRevisions, scenario.ID, scenario, author, importNote)
+				return err
+			},
+			func() error {
+				scenario.Name = importedName(scenario.Name)
+				_, err := models.CreateScenario(stores.Scenarios, stores.ScenarioRevisions, scenario, author)
+				return err
+			})
+	}
+
+	return result
+}
+
+// mapCopiedItems adds the IDs of copied rubric items next to the originals they were
+// copied from. The originals stay, as the observer they belong to may still be in use.
+func mapCopiedItems(mapping map[string][]string, copied map[string]string) map[string][]string {
+	if len(mapping) == 0 || len(copied) == 0 {
+		return mapping
+	}
+	result := make(map[string][]string, len(mapping))
+	for criterion, ids := range mapping {
+		mapped := append([]string(nil), ids...)
+		for _, id := range ids {
+			if copyID, ok := copied[id]; ok {
+				mapped = append(mapped, copyID)
+			}
+		}
+		result[criterion] = mapped
+	}
+	return result
+}
+
+// importNote is the revision note of scenarios written by an import
+const importNote = "Imported from content pack"
+
+func importedName(name string) string {
+	if strings.HasSuffix(name, " (imported)") {
+		return name
+	}
+	return name + " (imported)"
+}
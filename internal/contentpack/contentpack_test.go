@@ -0,0 +1,101 @@
+// internal/contentpack/contentpack_test.go
+package contentpack
+
+import (
+	"testing"
+
+	"github.com/saladinomario/vr-training-admin/internal/config"
+	"github.com/saladinomario/vr-training-admin/internal/models"
+	"github.com/saladinomario/vr-training-admin/templates/components/observers"
+	"github.com/saladinomario/vr-training-admin/templates/components/packs"
+	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
+)
+
+func openStores(t *testing.T) *models.Stores {
+	t.Helper()
+	stores, err := models.OpenStores(config.Config{DataDir: t.TempDir(), Storage: config.StorageMemory})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stores
+}
+
+// seedRubric adds an observer with one rubric item and a scenario scored with it
+func seedRubric(t *testing.T, stores *models.Stores) (observers.Observer, scenarios.Scenario) {
+	t.Helper()
+	observer, err := stores.Observers.Create(observers.Observer{
+		Name:   "Coach",
+		Rubric: []observers.RubricItem{{Name: "Greeting", Weight: 1, Min: 0, Max: 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scenario, err := stores.Scenarios.Create(scenarios.Scenario{
+		Name:            "Fire drill",
+		SuccessCriteria: "Clear Communication",
+		RubricItems:     map[string][]string{"Clear Communication": {observer.Rubric[0].ID}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return observer, scenario
+}
+
+func TestExportIncludesReferencedObservers(t *testing.T) {
+	stores := openStores(t)
+	observer, scenario := seedRubric(t, stores)
+	if _, err := stores.Observers.Create(observers.Observer{Name: "Unrelated"}); err != nil {
+		t.Fatal(err)
+	}
+
+	pack := Export(stores, Selection{Scenarios: []string{scenario.ID}})
+	if len(pack.Scenarios) != 1 {
+		t.Fatalf("exported %d scenarios, want 1", len(pack.Scenarios))
+	}
+	if len(pack.Observers) != 1 || pack.Observers[0].ID != observer.ID {
+		t.Errorf("exported observers %v, want only %s whose rubric the scenario uses", pack.Observers, observer.ID)
+	}
+}
+
+func TestApplyDuplicateMapsCopiedRubricItems(t *testing.T) {
+	source := openStores(t)
+	observer, scenario := seedRubric(t, source)
+	pack := Export(source, Selection{Scenarios: []string{scenario.ID}})
+
+	// Importing into the same installation makes both IDs conflict
+	result := Apply(pack, source, map[string]string{
+		packs.KindObserver + ":" + observer.ID: packs.ResolveDuplicate,
+		packs.KindScenario + ":" + scenario.ID: packs.ResolveDuplicate,
+	}, "test")
+	if len(result.Errors) > 0 || result.Duplicated != 2 {
+		t.Fatalf("import result %+v, want two copies", result)
+	}
+
+	var copiedObserver observers.Observer
+	for _, o := range source.Observers.GetAll() {
+		if o.Name == "Coach (imported)" {
+			copiedObserver = o
+		}
+	}
+	var copiedScenario scenarios.Scenario
+	for _, s := range source.Scenarios.GetAll() {
+		if s.Name == "Fire drill (imported)" {
+			copiedScenario = s
+		}
+	}
+	if copiedObserver.ID == "" || copiedScenario.ID == "" {
+		t.Fatal("the copies were not created")
+	}
+
+	copiedItem := copiedObserver.Rubric[0].ID
+	if copiedItem == observer.Rubric[0].ID {
+		t.Fatal("the copied observer kept the rubric item ID of the original")
+	}
+	mapped := copiedScenario.RubricItems["Clear Communication"]
+	if !copiedScenario.MapsRubricItem("Clear Communication", copiedItem) || !copiedScenario.MapsRubricItem("Clear Communication", observer.Rubric[0].ID) {
+		t.Errorf("copied scenario maps %v, want both %s and %s", mapped, observer.Rubric[0].ID, copiedItem)
+	}
+	if original, _ := source.Scenarios.GetByID(scenario.ID); len(original.RubricItems["Clear Communication"]) != 1 {
+		t.Errorf("the original scenario's mapping changed to %v", original.RubricItems)
+	}
+}
@@ -0,0 +1,50 @@
+// internal/handlers/archive.go
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+)
+
+// archiveRoute describes one kind of content that can be archived
+type archiveRoute struct {
+	label       string // Name of the kind in log messages
+	path        string // Collection path, e.g. "/scenarios"
+	setArchived func(id string, archived bool) error
+	notFound    error // Error the store returns for an unknown ID
+	renderList  func(w http.ResponseWriter, r *http.Request)
+}
+
+// serveArchive archives an item or brings it back: POST {path}/{id}/archive.
+// The form value archived=false restores the item.
+func serveArchive(w http.ResponseWriter, r *http.Request, route archiveRoute) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, route.path+"/"), "/archive")
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+	archived := r.FormValue("archived") != "false"
+
+	if err := route.setArchived(id, archived); err != nil {
+		if err == route.notFound {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	log.Printf("%s %s archived set to %t", route.label, id, archived)
+
+	if r.Header.Get("HX-Request") == "true" {
+		route.renderList(w, r)
+		return
+	}
+
+	http.Redirect(w, r, route.path, http.StatusSeeOther)
+}
@@ -2,6 +2,7 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -12,12 +13,6 @@ import (
 	"github.com/saladinomario/vr-training-admin/templates/pages"
 )
 
-var AvatarStore *models.AvatarStore
-
-func init() {
-	AvatarStore = models.NewAvatarStore()
-}
-
 // AvatarsHandler handles the avatars index page
 func AvatarsHandler(w http.ResponseWriter, r *http.Request) {
 	if r.URL.Path != "/avatars" {
@@ -189,11 +184,20 @@ func AvatarDeleteHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Delete avatar
-	err := AvatarStore.Delete(idStr)
+	// Delete avatar, unless sessions still refer to it
+	err := models.DeleteAvatar(AvatarStore, SessionStore, idStr)
 	if err != nil {
+		var inUse *models.InUseError
 		if err == models.ErrAvatarNotFound {
 			http.NotFound(w, r)
+		} else if errors.As(err, &inUse) {
+			log.Printf("Refusing to delete avatar %s: %v", idStr, err)
+			if r.Header.Get("HX-Request") == "true" {
+				writeAlert(w, false, err.Error())
+				renderAvatarList(w, r)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusConflict)
 		} else {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 		}
@@ -217,6 +221,28 @@ func AvatarDeleteHandler(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/avatars", http.StatusSeeOther)
 }
 
+// AvatarArchiveHandler archives an avatar or brings it back: POST /avatars/{id}/archive
+func AvatarArchiveHandler(w http.ResponseWriter, r *http.Request) {
+	serveArchive(w, r, archiveRoute{
+		label:       "Avatar",
+		path:        "/avatars",
+		setArchived: AvatarStore.SetArchived,
+		notFound:    models.ErrAvatarNotFound,
+		renderList:  renderAvatarList,
+	})
+}
+
+// renderAvatarList writes the avatar list for HTMX requests
+func renderAvatarList(w http.ResponseWriter, r *http.Request) {
+	component := avatars.AvatarList(AvatarStore.GetAll())
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := component.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering avatar list: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
 // AvatarSearchHandler handles avatar search
 func AvatarSearchHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -286,8 +312,12 @@ func SetupAvatarRoutes(mux *http.ServeMux) {
 	// Edit form
 	mux.HandleFunc("/avatars/edit/", AvatarEditHandler)
 
-	// Update and Delete
+	// Update, Delete and Archive
 	mux.HandleFunc("/avatars/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/archive") {
+			AvatarArchiveHandler(w, r)
+			return
+		}
 		switch r.Method {
 		case http.MethodPut, http.MethodPost:
 			AvatarUpdateHandler(w, r)
@@ -0,0 +1,75 @@
+// internal/handlers/backup.go
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/internal/backup"
+)
+
+// maxBackupUploadSize limits the size of an uploaded backup archive
+const maxBackupUploadSize = 512 << 20
+
+// BackupHandler downloads a backup archive on GET and restores one on POST
+func BackupHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		downloadBackup(w, r)
+	case http.MethodPost:
+		restoreBackup(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// downloadBackup builds the archive in memory so a failure can still be reported as an error.
+// The LLM credentials and station auth tokens are left out unless secrets=include is given.
+func downloadBackup(w http.ResponseWriter, r *http.Request) {
+	snap := stores.Snapshot()
+	if r.URL.Query().Get("secrets") != "include" {
+		snap = snap.WithoutSecrets()
+	}
+
+	var buf bytes.Buffer
+	manifest, err := backup.Write(&buf, snap)
+	if err != nil {
+		log.Printf("Error creating backup: %v", err)
+		http.Error(w, "Failed to create backup", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+backup.FileName(manifest.CreatedAt)+`"`)
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Printf("Error sending backup: %v", err)
+	}
+}
+
+// restoreBackup validates the uploaded archive and replaces all data with its content
+func restoreBackup(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBackupUploadSize)
+	file, _, err := r.FormFile("archive")
+	if err != nil {
+		writeAlert(w, false, "Please choose a backup archive to restore.")
+		return
+	}
+	defer file.Close()
+
+	safety := backup.PreRestorePath(dataDir, time.Now())
+	manifest, err := backup.Restore(file, stores, safety)
+	if err != nil {
+		log.Printf("Error restoring backup: %v", err)
+		writeAlert(w, false, "Restore failed: "+err.Error())
+		return
+	}
+
+	log.Printf("Restored backup created at %s (previous data saved to %s)", manifest.CreatedAt, safety)
+	writeAlert(w, true, fmt.Sprintf("Backup from %s restored successfully. Previous data was saved to %s.",
+		manifest.CreatedAt.Local().Format("2006-01-02 15:04"), safety))
+}
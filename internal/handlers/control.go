@@ -0,0 +1,143 @@
+// internal/handlers/control.go
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/saladinomario/vr-training-admin/internal/models"
+	"github.com/saladinomario/vr-training-admin/internal/unreal"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+	"github.com/saladinomario/vr-training-admin/templates/components/settings"
+)
+
+// statusCommands maps the session statuses a trainer can pick, and the failed status the
+// supervisor sets on timeout, to the command sent to the station
+var statusCommands = map[string]string{
+	sessions.StatusPaused:    unreal.CommandPause,
+	sessions.StatusRunning:   unreal.CommandResume,
+	sessions.StatusCompleted: unreal.CommandComplete,
+	sessions.StatusAborted:   unreal.CommandAbort,
+	sessions.StatusFailed:    unreal.CommandAbort,
+}
+
+// StationAPIHandler routes the station API: /api/ue/stations/{id}/control and
+// /api/ue/stations/{id}/heartbeat
+func StationAPIHandler(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/ue/stations/")
+	stationID, action, ok := strings.Cut(path, "/")
+	if !ok || stationID == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch action {
+	case "control":
+		StationControlHandler(w, r, stationID)
+	case "heartbeat":
+		StationHeartbeatHandler(w, r, stationID)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// StationControlHandler serves the WebSocket control channel of a station:
+// GET /api/ue/stations/{id}/control
+func StationControlHandler(w http.ResponseWriter, r *http.Request, stationID string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	controlHub.ServeStation(w, r, stationID)
+}
+
+// bearerToken returns the bearer token of the request, or "" without one
+func bearerToken(r *http.Request) string {
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return token
+}
+
+// authenticateStation admits an enabled station presenting its own auth token
+func authenticateStation(stationID, token string) error {
+	station, err := models.FindStation(settingsStore, stationID)
+	if err != nil {
+		return err
+	}
+	if !station.Enabled {
+		return fmt.Errorf("station %s is disabled", station.Name)
+	}
+	return checkStationToken(station, token)
+}
+
+// checkStationToken verifies the token presented on behalf of station
+func checkStationToken(station settings.Station, token string) error {
+	if station.AuthToken == "" {
+		return fmt.Errorf("station %s has no auth token configured", station.Name)
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(station.AuthToken)) != 1 {
+		return fmt.Errorf("missing or invalid token for station %s", station.Name)
+	}
+	return nil
+}
+
+// commandStation pushes the command for status down the control channel of the session's
+// station and waits for the station to acknowledge it
+func commandStation(ctx context.Context, session *sessions.Session, status string) error {
+	command, err := stationCommand(session.Status, status)
+	if err != nil || command == "" {
+		return err
+	}
+	station, err := sessionStation(session)
+	if err != nil {
+		return err
+	}
+	if err := controlHub.Send(ctx, station.ID, session.ID, command); err != nil {
+		return fmt.Errorf("%s did not confirm %s: %w", station.Name, command, err)
+	}
+	return nil
+}
+
+// stationCommand returns the command that tells a station a session moves from one status to
+// another, or "" when the station has nothing to be told. Only a paused session is resumed;
+// a pending or starting session marked running has not been paused on its station.
+func stationCommand(from, to string) (string, error) {
+	command, ok := statusCommands[to]
+	if !ok {
+		return "", fmt.Errorf("no station command for status %s", to)
+	}
+	if command == unreal.CommandResume && from != sessions.StatusPaused {
+		return "", nil
+	}
+	return command, nil
+}
+
+// commandErrorStatus returns the HTTP status for a command that was not confirmed
+func commandErrorStatus(err error) int {
+	var rejected *unreal.RejectedError
+	switch {
+	case errors.Is(err, unreal.ErrStationOffline):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, unreal.ErrAckTimeout):
+		return http.StatusGatewayTimeout
+	case errors.As(err, &rejected):
+		return http.StatusConflict
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// SetupControlRoutes registers the WebSocket control channel stations connect to
+// and the heartbeat API for stations that report over HTTP
+func SetupControlRoutes(mux *http.ServeMux) {
+	log.Println("Setting up station control routes...")
+
+	mux.HandleFunc("/api/ue/stations/", StationAPIHandler)
+
+	log.Println("Station control routes registered successfully")
+}
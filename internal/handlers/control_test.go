@@ -0,0 +1,40 @@
+// internal/handlers/control_test.go
+package handlers
+
+import (
+	"testing"
+
+	"github.com/saladinomario/vr-training-admin/internal/unreal"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+)
+
+func TestStationCommand(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     string
+	}{
+		{sessions.StatusPaused, sessions.StatusRunning, unreal.CommandResume},
+		{sessions.StatusStarting, sessions.StatusRunning, ""},
+		{sessions.StatusPending, sessions.StatusRunning, ""},
+		{sessions.StatusRunning, sessions.StatusPaused, unreal.CommandPause},
+		{sessions.StatusStarting, sessions.StatusCompleted, unreal.CommandComplete},
+		{sessions.StatusRunning, sessions.StatusAborted, unreal.CommandAbort},
+		{sessions.StatusStarting, sessions.StatusFailed, unreal.CommandAbort},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			got, err := stationCommand(tt.from, tt.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("stationCommand(%s, %s) = %q, want %q", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := stationCommand(sessions.StatusRunning, sessions.StatusScheduled); err == nil {
+		t.Error("stationCommand accepted a status without a command")
+	}
+}
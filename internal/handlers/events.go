@@ -0,0 +1,204 @@
+// internal/handlers/events.go
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/internal/models"
+	"github.com/saladinomario/vr-training-admin/internal/uepayload"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+)
+
+// maxEventsBody bounds the size of one batch of events sent by a station
+const maxEventsBody = 1 << 20
+
+// inboundEvent is an event as a station sends it
+type inboundEvent struct {
+	ID     string    `json:"id"`
+	Type   string    `json:"type"`
+	At     time.Time `json:"at"`
+	Phase  string    `json:"phase"`
+	Text   string    `json:"text"`
+	Score  *int      `json:"score"`
+	Reason string    `json:"reason"`
+	// Ratings rate the rubric items of the session's observer, by item ID
+	Ratings map[string]int `json:"ratings"`
+}
+
+// eventsResponse tells the station what was done with a batch of events
+type eventsResponse struct {
+	Accepted   int    `json:"accepted"`
+	Duplicates int    `json:"duplicates"`
+	Status     string `json:"status"`
+}
+
+// SessionEventsHandler receives the events a station reports for a session:
+// POST /api/ue/sessions/{id}/events
+//
+// The body is one event, an array of events, or a stream of either separated by newlines.
+// Requests must carry the auth token of the session's station as a bearer token.
+func SessionEventsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract session ID from URL: /api/ue/sessions/{id}/events
+	path := strings.TrimPrefix(r.URL.Path, "/api/ue/sessions/")
+	sessionID, ok := strings.CutSuffix(path, "/events")
+	if !ok || sessionID == "" || strings.Contains(sessionID, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	session, err := SessionStore.GetByID(sessionID)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if status, err := authorizeStation(r, session); err != nil {
+		log.Printf("Rejected events for session %s: %v", sessionID, err)
+		if status == http.StatusUnauthorized {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="vr-training-admin"`)
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	events, err := decodeEvents(http.MaxBytesReader(w, r.Body, maxEventsBody))
+	if err != nil {
+		http.Error(w, "Invalid events: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	added, updated, err := models.RecordSessionEvents(SessionStore, EventStore, sessionID, events)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidEvent):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, models.ErrSessionNotFound):
+			http.NotFound(w, r)
+		default:
+			log.Printf("Error recording events for session %s: %v", sessionID, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	log.Printf("Received %d events for session %s (%d already known), status %s",
+		len(added), sessionID, len(events)-len(added), updated.Status)
+
+	// A session the station finished frees a slot for the queue
+	if !updated.IsActive() {
+		sessionSupervisor.Wake()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(eventsResponse{
+		Accepted:   len(added),
+		Duplicates: len(events) - len(added),
+		Status:     updated.Status,
+	}); err != nil {
+		log.Printf("Error writing events response: %v", err)
+	}
+}
+
+// authorizeStation checks that the request carries the auth token of the station the
+// session runs on. On failure it returns the HTTP status to answer with.
+func authorizeStation(r *http.Request, session *sessions.Session) (int, error) {
+	station, err := sessionStation(session)
+	if err != nil {
+		return http.StatusForbidden, err
+	}
+
+	if err := checkStationToken(station, bearerToken(r)); err != nil {
+		return http.StatusUnauthorized, err
+	}
+	return http.StatusOK, nil
+}
+
+// decodeEvents reads a sequence of JSON values, each an event or an array of events
+func decodeEvents(body io.Reader) ([]sessions.Event, error) {
+	dec := json.NewDecoder(body)
+	events := make([]sessions.Event, 0)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		var batch []inboundEvent
+		if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+			if err := json.Unmarshal(raw, &batch); err != nil {
+				return nil, err
+			}
+		} else {
+			var single inboundEvent
+			if err := json.Unmarshal(raw, &single); err != nil {
+				return nil, err
+			}
+			batch = append(batch, single)
+		}
+
+		for _, in := range batch {
+			events = append(events, sessions.Event{
+				ClientID:   in.ID,
+				Type:       in.Type,
+				OccurredAt: in.At,
+				Phase:      in.Phase,
+				Text:       in.Text,
+				Score:      in.Score,
+				Reason:     in.Reason,
+				Ratings:    in.Ratings,
+			})
+		}
+	}
+
+	if len(events) == 0 {
+		return nil, errors.New("no events in request")
+	}
+	return events, nil
+}
+
+// PayloadSchemaHandler publishes the JSON Schema of a payload version: GET /api/ue/schema/v{N}.json
+func PayloadSchemaHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/api/ue/schema/")
+	var version int
+	if _, err := fmt.Sscanf(name, "v%d.json", &version); err != nil || name != fmt.Sprintf("v%d.json", version) {
+		http.NotFound(w, r)
+		return
+	}
+	schema, err := uepayload.Schema(version)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.Write(schema)
+}
+
+// SetupEventRoutes registers the API the Unreal Engine uses to report session progress
+// and the schema of the payloads it receives
+func SetupEventRoutes(mux *http.ServeMux) {
+	log.Println("Setting up Unreal Engine event routes...")
+
+	mux.HandleFunc("/api/ue/sessions/", SessionEventsHandler)
+	mux.HandleFunc("/api/ue/schema/", PayloadSchemaHandler)
+
+	log.Println("Unreal Engine event routes registered successfully")
+}
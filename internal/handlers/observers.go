@@ -2,6 +2,7 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -12,12 +13,6 @@ import (
 	"github.com/saladinomario/vr-training-admin/templates/pages"
 )
 
-var ObserverStore *models.ObserverStore
-
-func init() {
-	ObserverStore = models.NewObserverStore()
-}
-
 // ObserversHandler handles the observers index page
 func ObserversHandler(w http.ResponseWriter, r *http.Request) {
 	if r.URL.Path != "/observers" {
@@ -189,11 +184,20 @@ func ObserverDeleteHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Delete observer
-	err := ObserverStore.Delete(idStr)
+	// Delete observer, unless sessions still refer to it
+	err := models.DeleteObserver(ObserverStore, SessionStore, idStr)
 	if err != nil {
+		var inUse *models.InUseError
 		if err == models.ErrObserverNotFound {
 			http.NotFound(w, r)
+		} else if errors.As(err, &inUse) {
+			log.Printf("Refusing to delete observer %s: %v", idStr, err)
+			if r.Header.Get("HX-Request") == "true" {
+				writeAlert(w, false, err.Error())
+				renderObserverList(w, r)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusConflict)
 		} else {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 		}
@@ -217,6 +221,28 @@ func ObserverDeleteHandler(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/observers", http.StatusSeeOther)
 }
 
+// ObserverArchiveHandler archives an observer or brings it back: POST /observers/{id}/archive
+func ObserverArchiveHandler(w http.ResponseWriter, r *http.Request) {
+	serveArchive(w, r, archiveRoute{
+		label:       "Observer",
+		path:        "/observers",
+		setArchived: ObserverStore.SetArchived,
+		notFound:    models.ErrObserverNotFound,
+		renderList:  renderObserverList,
+	})
+}
+
+// renderObserverList writes the observer list for HTMX requests
+func renderObserverList(w http.ResponseWriter, r *http.Request) {
+	component := observers.ObserverList(ObserverStore.GetAll())
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := component.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering observer list: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
 // ObserverSearchHandler handles observer search
 func ObserverSearchHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -271,6 +297,52 @@ func parseObserverForm(r *http.Request) observers.Observer {
 		SuccessMetrics:       r.FormValue("success_metrics"),
 		InterventionTriggers: interventionTriggers,
 		Active:               active,
+		Rubric:               parseRubricForm(r),
+	}
+}
+
+// parseRubricForm reads the rubric items of the observer form, one per row of
+// parallel rubric_* fields. Rows without a name are left out.
+func parseRubricForm(r *http.Request) []observers.RubricItem {
+	at := func(field string, i int) string {
+		if values := r.Form[field]; i < len(values) {
+			return strings.TrimSpace(values[i])
+		}
+		return ""
+	}
+
+	rubric := make([]observers.RubricItem, 0)
+	for i := range r.Form["rubric_name"] {
+		item := observers.RubricItem{
+			ID:          at("rubric_id", i),
+			Name:        at("rubric_name", i),
+			Description: at("rubric_description", i),
+		}
+		if item.Name == "" {
+			continue
+		}
+		item.Weight, _ = strconv.Atoi(at("rubric_weight", i))
+		if scale, ok := observers.ParseScale(at("rubric_scale", i)); ok {
+			item.Min, item.Max = scale.Min, scale.Max
+		}
+		rubric = append(rubric, item)
+	}
+	return rubric
+}
+
+// ObserverRubricItemHandler returns an empty row for the rubric of the observer form
+func ObserverRubricItemHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	component := observers.RubricItemRow(observers.RubricItem{Weight: 1, Min: 1, Max: 5})
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := component.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering rubric item: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
 
@@ -300,8 +372,15 @@ func SetupObserverRoutes(mux *http.ServeMux) {
 	// Edit form
 	mux.HandleFunc("/observers/edit/", ObserverEditHandler)
 
-	// Update and Delete
+	// Empty rubric item for the form
+	mux.HandleFunc("/observers/rubric-item", ObserverRubricItemHandler)
+
+	// Update, Delete and Archive
 	mux.HandleFunc("/observers/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/archive") {
+			ObserverArchiveHandler(w, r)
+			return
+		}
 		switch r.Method {
 		case http.MethodPut, http.MethodPost:
 			ObserverUpdateHandler(w, r)
@@ -0,0 +1,197 @@
+// internal/handlers/packs.go
+package handlers
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/saladinomario/vr-training-admin/internal/contentpack"
+	"github.com/saladinomario/vr-training-admin/templates/components/avatars"
+	"github.com/saladinomario/vr-training-admin/templates/components/observers"
+	"github.com/saladinomario/vr-training-admin/templates/components/packs"
+	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
+)
+
+// maxPackSize limits the size of an uploaded content pack
+const maxPackSize = 10 << 20
+
+// PackExportHandler downloads the selected scenarios, avatars and observers as one content pack.
+// The selection is taken from the repeated "scenario", "avatar" and "observer" parameters.
+func PackExportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	selection := contentpack.Selection{
+		Scenarios: query[packs.KindScenario],
+		Avatars:   query[packs.KindAvatar],
+		Observers: query[packs.KindObserver],
+	}
+	if selection.Empty() {
+		http.Error(w, "Select at least one item to export", http.StatusBadRequest)
+		return
+	}
+	pack := contentpack.Export(stores, selection)
+
+	encoding := contentpack.EncodingYAML
+	if query.Get("format") == contentpack.EncodingJSON {
+		encoding = contentpack.EncodingJSON
+	}
+
+	data, err := contentpack.Encode(pack, encoding)
+	if err != nil {
+		log.Printf("Error encoding content pack: %v", err)
+		http.Error(w, "Failed to export", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentpack.ContentType(encoding))
+	w.Header().Set("Content-Disposition", `attachment; filename="`+contentpack.FileName(encoding, time.Now())+`"`)
+	w.Write(data)
+}
+
+// PackOptionsHandler lists every scenario, avatar and observer for the export selection.
+// Items of the kind in the "kind" parameter, the page the panel is on, start selected.
+func PackOptionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	groups := []packs.OptionGroup{
+		{Kind: packs.KindScenario, Options: packs.ScenarioOptions(ScenarioStore.GetAll())},
+		{Kind: packs.KindAvatar, Options: packs.AvatarOptions(AvatarStore.GetAll())},
+		{Kind: packs.KindObserver, Options: packs.ObserverOptions(ObserverStore.GetAll())},
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := packs.ExportOptions(r.URL.Query().Get("kind"), groups).Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering export options: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// PackPreviewHandler shows what importing an uploaded content pack would do
+func PackPreviewHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxPackSize)
+	file, _, err := r.FormFile("pack")
+	if err != nil {
+		writeAlert(w, false, "Please choose a content pack to import.")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		writeAlert(w, false, "Failed to read the uploaded file.")
+		return
+	}
+
+	pack, err := contentpack.Decode(data)
+	if err != nil {
+		writeAlert(w, false, err.Error())
+		return
+	}
+
+	// The decoded pack travels with the form so the import applies exactly what was previewed
+	packData, err := contentpack.Encode(pack, contentpack.EncodingJSON)
+	if err != nil {
+		log.Printf("Error encoding content pack: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	component := packs.ImportPreview(r.FormValue("kind"), contentpack.Preview(pack, stores), string(packData))
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := component.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering import preview: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// PackImportHandler imports a previewed content pack with the chosen conflict resolutions
+func PackImportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxPackSize)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	pack, err := contentpack.Decode([]byte(r.FormValue("pack")))
+	if err != nil {
+		writeAlert(w, false, err.Error())
+		return
+	}
+
+	resolutions := make(map[string]string)
+	for key, values := range r.PostForm {
+		if strings.HasPrefix(key, "resolve:") && len(values) > 0 {
+			resolutions[strings.TrimPrefix(key, "resolve:")] = values[0]
+		}
+	}
+
+	result := contentpack.Apply(pack, stores, resolutions, requestAuthor(r))
+	log.Printf("Imported content pack: %d created, %d overwritten, %d copied, %d skipped, %d errors",
+		result.Created, result.Overwritten, result.Duplicated, result.Skipped, len(result.Errors))
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := packs.ImportSummary(result).Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering import result: %v", err)
+		return
+	}
+
+	// Refresh the list of the page the import was started from
+	kind := r.FormValue("kind")
+	var list templ.Component
+	switch kind {
+	case packs.KindScenario:
+		list = scenarios.ScenarioList(ScenarioStore.GetAll())
+	case packs.KindAvatar:
+		list = avatars.AvatarList(AvatarStore.GetAll())
+	case packs.KindObserver:
+		list = observers.ObserverList(ObserverStore.GetAll())
+	default:
+		return
+	}
+	io.WriteString(w, `<div id="`+kind+`-list" hx-swap-oob="true">`)
+	if err := list.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering %s list: %v", kind, err)
+	}
+	io.WriteString(w, `</div>`)
+}
+
+// SetupPackRoutes registers the content pack import and export routes
+func SetupPackRoutes(mux *http.ServeMux) {
+	log.Println("Setting up content pack routes...")
+
+	log.Println("  Registering route: /packs/export")
+	mux.HandleFunc("/packs/export", PackExportHandler)
+
+	log.Println("  Registering route: /packs/options")
+	mux.HandleFunc("/packs/options", PackOptionsHandler)
+
+	log.Println("  Registering route: /packs/preview")
+	mux.HandleFunc("/packs/preview", PackPreviewHandler)
+
+	log.Println("  Registering route: /packs/import")
+	mux.HandleFunc("/packs/import", PackImportHandler)
+
+	log.Println("Content pack routes registered successfully")
+}
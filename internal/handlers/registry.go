@@ -0,0 +1,218 @@
+// internal/handlers/registry.go
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/internal/models"
+	"github.com/saladinomario/vr-training-admin/internal/supervisor"
+	"github.com/saladinomario/vr-training-admin/internal/unreal"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+	"github.com/saladinomario/vr-training-admin/templates/components/settings"
+	"github.com/saladinomario/vr-training-admin/templates/components/stations"
+	"github.com/saladinomario/vr-training-admin/templates/pages"
+)
+
+// maxHeartbeatBody bounds the size of a heartbeat sent over HTTP
+const maxHeartbeatBody = 64 << 10
+
+// inboundHeartbeat is a heartbeat as a station sends it over HTTP
+type inboundHeartbeat struct {
+	Battery         *int   `json:"battery"`
+	AppVersion      string `json:"appVersion"`
+	SessionID       string `json:"sessionId"`
+	PayloadVersions []int  `json:"payloadVersions"`
+}
+
+// StationsPageHandler shows the live state of the stations: GET /stations
+func StationsPageHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	component := pages.StationsIndex(stationStatuses(), time.Now())
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := component.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering stations page: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// StationsTableHandler renders the station table for the page to refresh: GET /stations/table
+func StationsTableHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	component := stations.StationTable(stationStatuses(), time.Now())
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := component.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering station table: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// StationHeartbeatHandler registers a station and records its heartbeat, for stations
+// that report over HTTP instead of the control channel: POST /api/ue/stations/{id}/heartbeat
+//
+// Requests must carry the auth token of the station as a bearer token.
+func StationHeartbeatHandler(w http.ResponseWriter, r *http.Request, stationID string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := authenticateStation(stationID, bearerToken(r)); err != nil {
+		log.Printf("Rejected heartbeat from station %s: %v", stationID, err)
+		w.Header().Set("WWW-Authenticate", `Bearer realm="vr-training-admin"`)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var heartbeat inboundHeartbeat
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxHeartbeatBody)).Decode(&heartbeat); err != nil {
+		http.Error(w, "Invalid heartbeat: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if heartbeat.Battery != nil && (*heartbeat.Battery < 0 || *heartbeat.Battery > 100) {
+		http.Error(w, "Invalid heartbeat: battery must be between 0 and 100", http.StatusBadRequest)
+		return
+	}
+
+	stationRegistry.Report(stationID, unreal.Report{
+		Battery:         heartbeat.Battery,
+		AppVersion:      heartbeat.AppVersion,
+		SessionID:       heartbeat.SessionID,
+		PayloadVersions: heartbeat.PayloadVersions,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// stationStatuses returns the live state of every configured station
+func stationStatuses() []stations.Status {
+	busy := activeStationSessions()
+	now := time.Now()
+
+	configured := settingsStore.GetStations()
+	statuses := make([]stations.Status, 0, len(configured))
+	for _, station := range configured {
+		statuses = append(statuses, stationStatus(station, busy, now))
+	}
+	return statuses
+}
+
+// enabledStationStatuses returns the live state of the stations sessions can be started on
+func enabledStationStatuses() []stations.Status {
+	statuses := make([]stations.Status, 0)
+	for _, status := range stationStatuses() {
+		if status.State != stations.StateDisabled {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+// findStationStatus returns the live state of one configured station
+func findStationStatus(stationID string) (stations.Status, error) {
+	station, err := models.FindStation(settingsStore, stationID)
+	if err != nil {
+		return stations.Status{}, err
+	}
+	return stationStatus(station, activeStationSessions(), time.Now()), nil
+}
+
+// stationStatus combines what a station last reported with the sessions the admin runs on it.
+// busy maps station IDs to an active session started on them.
+func stationStatus(station settings.Station, busy map[string]string, now time.Time) stations.Status {
+	status := stations.Status{Station: station, State: stations.StateOffline}
+
+	state, known := stationRegistry.Get(station.ID)
+	if known {
+		status.Connected = state.Connected
+		status.Battery = state.Battery
+		status.AppVersion = state.AppVersion
+		status.SessionID = state.SessionID
+		status.PayloadVersions = state.PayloadVersions
+		if !state.LastSeen.IsZero() {
+			lastSeen := state.LastSeen
+			status.LastSeen = &lastSeen
+		}
+	}
+	if status.SessionID == "" {
+		status.SessionID = busy[station.ID]
+	}
+
+	switch {
+	case !station.Enabled:
+		status.State = stations.StateDisabled
+	case status.LastSeen == nil || now.Sub(*status.LastSeen) > unreal.OfflineAfter:
+		status.State = stations.StateOffline
+	case now.Sub(*status.LastSeen) > unreal.StaleAfter:
+		status.State = stations.StateStale
+	case status.SessionID != "":
+		status.State = stations.StateBusy
+	default:
+		status.State = stations.StateIdle
+	}
+	return status
+}
+
+// freeStation returns the station a queued session should be sent to: its own station
+// when that is idle, otherwise the first other idle station that supports the payload
+// version. Queued sessions do not hold a station here, since they are waiting for one.
+func freeStation(session *sessions.Session) (settings.Station, error) {
+	occupied := make(map[string]string)
+	for _, other := range SessionStore.GetAll() {
+		if other.StationID != "" && other.IsActive() && !other.IsQueued() {
+			occupied[other.StationID] = other.ID
+		}
+	}
+
+	now := time.Now()
+	var next *settings.Station
+	for _, station := range settingsStore.GetStations() {
+		if !stationStatus(station, occupied, now).Available() || checkStationVersion(station) != nil {
+			continue
+		}
+		if station.ID == session.StationID {
+			return station, nil
+		}
+		if next == nil {
+			free := station
+			next = &free
+		}
+	}
+	if next == nil {
+		return settings.Station{}, supervisor.ErrNoFreeStation
+	}
+	return *next, nil
+}
+
+// activeStationSessions maps each station to a session that is still active on it.
+// Scheduled sessions do not hold their station until they are due.
+func activeStationSessions() map[string]string {
+	busy := make(map[string]string)
+	for _, session := range SessionStore.GetAll() {
+		if session.StationID != "" && session.IsActive() && session.Status != sessions.StatusScheduled {
+			busy[session.StationID] = session.ID
+		}
+	}
+	return busy
+}
+
+// SetupStationRoutes registers the station overview
+func SetupStationRoutes(mux *http.ServeMux) {
+	log.Println("Setting up station routes...")
+
+	mux.HandleFunc("/stations", StationsPageHandler)
+	mux.HandleFunc("/stations/table", StationsTableHandler)
+
+	log.Println("Station routes registered successfully")
+}
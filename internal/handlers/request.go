@@ -0,0 +1,46 @@
+// internal/handlers/request.go
+package handlers
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// requestAuthor returns who is making a change. A name set by a trusted authenticating
+// reverse proxy wins over the name typed into the form.
+func requestAuthor(r *http.Request) string {
+	if fromTrustedProxy(r) {
+		if user := strings.TrimSpace(r.Header.Get("X-Remote-User")); user != "" {
+			return user
+		}
+	}
+	if author := strings.TrimSpace(r.FormValue("author")); author != "" {
+		return author
+	}
+	return "anonymous"
+}
+
+// fromTrustedProxy reports whether the request came directly from one of the configured
+// reverse proxies. Any client can send X-Remote-User, so only those are believed.
+func fromTrustedProxy(r *http.Request) bool {
+	if len(trustedProxies) == 0 {
+		return false
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, proxy := range trustedProxies {
+		if proxy.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+// internal/handlers/request_test.go
+package handlers
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/saladinomario/vr-training-admin/internal/config"
+)
+
+func TestRequestAuthor(t *testing.T) {
+	proxies, err := config.Config{TrustedProxies: "10.0.0.1, 192.168.1.0/24"}.ProxyPrefixes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		proxies    string
+		remoteAddr string
+		header     string
+		form       string
+		want       string
+	}{
+		{"header from trusted proxy", "yes", "10.0.0.1:5000", "alice", "bob", "alice"},
+		{"header from trusted range", "yes", "192.168.1.20:5000", "alice", "", "alice"},
+		{"header from other client", "yes", "10.0.0.2:5000", "alice", "bob", "bob"},
+		{"header without trusted proxies", "", "10.0.0.1:5000", "alice", "bob", "bob"},
+		{"empty header from trusted proxy", "yes", "10.0.0.1:5000", "", "bob", "bob"},
+		{"nothing given", "yes", "10.0.0.2:5000", "", "", "anonymous"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trustedProxies = nil
+			if tt.proxies != "" {
+				trustedProxies = proxies
+			}
+			t.Cleanup(func() { trustedProxies = nil })
+
+			form := url.Values{}
+			if tt.form != "" {
+				form.Set("author", tt.form)
+			}
+			r := httptest.NewRequest("POST", "/scenarios", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.RemoteAddr = tt.remoteAddr
+			if tt.header != "" {
+				r.Header.Set("X-Remote-User", tt.header)
+			}
+
+			if got := requestAuthor(r); got != tt.want {
+				t.Errorf("requestAuthor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
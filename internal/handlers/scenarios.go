@@ -2,22 +2,19 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/saladinomario/vr-training-admin/internal/models"
+	"github.com/saladinomario/vr-training-admin/templates/components/observers"
 	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
 	"github.com/saladinomario/vr-training-admin/templates/pages"
 )
 
-var ScenarioStore *models.ScenarioStore
-
-func init() {
-	ScenarioStore = models.NewScenarioStore()
-}
-
 // ScenariosHandler handles the scenarios index page
 func ScenariosHandler(w http.ResponseWriter, r *http.Request) {
 	if r.URL.Path != "/scenarios" {
@@ -47,7 +44,7 @@ func ScenarioNewHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	component := pages.ScenarioNew()
+	component := pages.ScenarioNew(rubricObservers())
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	if err := component.Render(r.Context(), w); err != nil {
@@ -77,7 +74,7 @@ func ScenarioEditHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	component := pages.ScenarioEdit(scenario)
+	component := pages.ScenarioEdit(scenario, rubricObservers())
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	if err := component.Render(r.Context(), w); err != nil {
@@ -101,8 +98,8 @@ func ScenarioCreateHandler(w http.ResponseWriter, r *http.Request) {
 	// Parse form values
 	scenario := parseScenarioForm(r)
 
-	// Create scenario
-	_, err := ScenarioStore.Create(scenario)
+	// Create scenario and record its first revision
+	_, err := models.CreateScenario(ScenarioStore, ScenarioRevisionStore, scenario, requestAuthor(r))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -147,8 +144,8 @@ func ScenarioUpdateHandler(w http.ResponseWriter, r *http.Request) {
 	// Parse form values
 	scenario := parseScenarioForm(r)
 
-	// Update scenario
-	err := ScenarioStore.Update(idStr, scenario)
+	// Update scenario, recording the new version as a revision
+	_, err := models.UpdateScenario(ScenarioStore, ScenarioRevisionStore, idStr, scenario, requestAuthor(r), r.FormValue("change_note"))
 	if err != nil {
 		if err == models.ErrScenarioNotFound {
 			http.NotFound(w, r)
@@ -189,11 +186,20 @@ func ScenarioDeleteHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Delete scenario
-	err := ScenarioStore.Delete(idStr)
+	// Delete scenario, unless sessions still refer to it
+	err := models.DeleteScenario(ScenarioStore, SessionStore, idStr)
 	if err != nil {
+		var inUse *models.InUseError
 		if err == models.ErrScenarioNotFound {
 			http.NotFound(w, r)
+		} else if errors.As(err, &inUse) {
+			log.Printf("Refusing to delete scenario %s: %v", idStr, err)
+			if r.Header.Get("HX-Request") == "true" {
+				writeAlert(w, false, err.Error())
+				renderScenarioList(w, r)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusConflict)
 		} else {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 		}
@@ -217,6 +223,28 @@ func ScenarioDeleteHandler(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/scenarios", http.StatusSeeOther)
 }
 
+// ScenarioArchiveHandler archives a scenario or brings it back: POST /scenarios/{id}/archive
+func ScenarioArchiveHandler(w http.ResponseWriter, r *http.Request) {
+	serveArchive(w, r, archiveRoute{
+		label:       "Scenario",
+		path:        "/scenarios",
+		setArchived: ScenarioStore.SetArchived,
+		notFound:    models.ErrScenarioNotFound,
+		renderList:  renderScenarioList,
+	})
+}
+
+// renderScenarioList writes the scenario list for HTMX requests
+func renderScenarioList(w http.ResponseWriter, r *http.Request) {
+	component := scenarios.ScenarioList(ScenarioStore.GetAll())
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := component.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering scenario list: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
 // ScenarioSearchHandler handles scenario search
 func ScenarioSearchHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -236,6 +264,99 @@ func ScenarioSearchHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// ScenarioHistoryHandler serves the revision history of a scenario:
+// GET /scenarios/history/{id}, GET /scenarios/history/{id}/diff and POST /scenarios/history/{id}/rollback
+func ScenarioHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/scenarios/history/"), "/")
+	id := parts[0]
+	action := ""
+	if len(parts) > 1 {
+		action = parts[1]
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		renderScenarioHistory(w, r, id)
+	case action == "diff" && r.Method == http.MethodGet:
+		scenarioRevisionDiff(w, r, id)
+	case action == "rollback" && r.Method == http.MethodPost:
+		scenarioRollback(w, r, id)
+	case action == "" || action == "diff" || action == "rollback":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// renderScenarioHistory renders the history page, or only its content for HTMX requests
+func renderScenarioHistory(w http.ResponseWriter, r *http.Request, id string) {
+	// Make sure the current version is part of the history
+	if _, err := models.CurrentScenarioRevision(ScenarioStore, ScenarioRevisionStore, id); err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	scenario, err := ScenarioStore.GetByID(id)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	revisions := ScenarioRevisionStore.ListByScenario(id)
+
+	component := pages.ScenarioHistory(scenario, revisions)
+	if r.Header.Get("HX-Request") == "true" {
+		component = pages.ScenarioHistoryContent(scenario, revisions)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := component.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering scenario history: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// scenarioRevisionDiff renders the changes between the revisions given as from and to
+func scenarioRevisionDiff(w http.ResponseWriter, r *http.Request, id string) {
+	from, err := ScenarioRevisionStore.GetByID(r.URL.Query().Get("from"))
+	if err != nil || from.ScenarioID != id {
+		http.NotFound(w, r)
+		return
+	}
+	to, err := ScenarioRevisionStore.GetByID(r.URL.Query().Get("to"))
+	if err != nil || to.ScenarioID != id {
+		http.NotFound(w, r)
+		return
+	}
+
+	component := scenarios.RevisionDiff(from, to, scenarios.Diff(from.Scenario, to.Scenario))
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := component.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering revision diff: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// scenarioRollback restores an earlier revision and shows the updated history
+func scenarioRollback(w http.ResponseWriter, r *http.Request, id string) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	rev, err := models.RollbackScenario(ScenarioStore, ScenarioRevisionStore, id, r.FormValue("revision_id"), requestAuthor(r))
+	if err != nil {
+		if err == models.ErrRevisionNotFound || err == models.ErrScenarioNotFound {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	log.Printf("Scenario %s rolled back to %s as revision %d", id, rev.RestoredFrom, rev.Number)
+
+	renderScenarioHistory(w, r, id)
+}
+
 // Helper function to parse scenario form data
 func parseScenarioForm(r *http.Request) scenarios.Scenario {
 	difficulty, _ := strconv.Atoi(r.FormValue("difficulty"))
@@ -252,6 +373,54 @@ func parseScenarioForm(r *http.Request) scenarios.Scenario {
 		BackgroundNoise: backgroundNoise,
 		SuccessCriteria: r.FormValue("success_criteria"),
 		Keywords:        r.FormValue("keywords"),
+		RubricItems:     parseRubricItems(r, r.FormValue("success_criteria")),
+	}
+}
+
+// parseRubricItems reads the rubric items mapped to each of the success criteria
+func parseRubricItems(r *http.Request, successCriteria string) map[string][]string {
+	mapping := make(map[string][]string)
+	for _, criterion := range scenarios.CriteriaOf(successCriteria) {
+		if ids := r.Form[scenarios.RubricItemsField(criterion)]; len(ids) > 0 {
+			mapping[criterion] = ids
+		}
+	}
+	return mapping
+}
+
+// rubricObservers returns the observers that have a scoring rubric, by name
+func rubricObservers() []observers.Observer {
+	result := make([]observers.Observer, 0)
+	for _, observer := range ObserverStore.GetAll() {
+		if len(observer.Rubric) > 0 {
+			result = append(result, observer)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// ScenarioRubricItemsHandler renders the rubric mapping of the scenario form for the
+// success criteria currently selected in it
+func ScenarioRubricItemsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+	scenario := parseScenarioForm(r)
+	component := scenarios.RubricMapping(&scenario, rubricObservers())
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := component.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering rubric mapping: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
 
@@ -278,8 +447,18 @@ func SetupScenarioRoutes(mux *http.ServeMux) {
 	// Edit form
 	mux.HandleFunc("/scenarios/edit/", ScenarioEditHandler)
 
-	// Update and Delete
+	// Rubric mapping of the form
+	mux.HandleFunc("/scenarios/rubric-items", ScenarioRubricItemsHandler)
+
+	// Revision history, diff and rollback
+	mux.HandleFunc("/scenarios/history/", ScenarioHistoryHandler)
+
+	// Update, Delete and Archive
 	mux.HandleFunc("/scenarios/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/archive") {
+			ScenarioArchiveHandler(w, r)
+			return
+		}
 		switch r.Method {
 		case http.MethodPut, http.MethodPost:
 			ScenarioUpdateHandler(w, r)
@@ -0,0 +1,298 @@
+// internal/handlers/schedule.go
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/internal/models"
+	"github.com/saladinomario/vr-training-admin/templates/components/avatars"
+	"github.com/saladinomario/vr-training-admin/templates/components/observers"
+	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+	"github.com/saladinomario/vr-training-admin/templates/components/settings"
+	"github.com/saladinomario/vr-training-admin/templates/components/trainees"
+	"github.com/saladinomario/vr-training-admin/templates/pages"
+)
+
+// scheduleTimeLayout is the format of datetime-local inputs
+const scheduleTimeLayout = "2006-01-02T15:04"
+
+// SchedulePageHandler shows the calendar of scheduled sessions for a week: GET /schedule?week=2026-10-12.
+// Any date in the week selects it; without one the current week is shown. HTMX requests
+// only get the calendar.
+func SchedulePageHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	now := time.Now()
+	weekStart := sessions.WeekStart(now)
+	if week := r.URL.Query().Get("week"); week != "" {
+		day, err := time.ParseInLocation("2006-01-02", week, time.Local)
+		if err != nil {
+			http.Error(w, "Invalid week, expected a date like 2026-10-12", http.StatusBadRequest)
+			return
+		}
+		weekStart = sessions.WeekStart(day)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if r.Header.Get("HX-Request") == "true" {
+		component := sessions.WeekCalendar(weekStart, scheduleWeek(weekStart), stationNames(), now)
+		if err := component.Render(r.Context(), w); err != nil {
+			log.Printf("Error rendering schedule calendar: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	component := pages.ScheduleIndex(weekStart, scheduleWeek(weekStart), stationNames(), now)
+	if err := component.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering schedule page: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// ScheduleFormHandler serves the form to schedule a session: GET /schedule/new?at=2026-10-17T09:00
+func ScheduleFormHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Preset the start time from the calendar day picked, or to the next full hour
+	at := r.URL.Query().Get("at")
+	if _, err := time.ParseInLocation(scheduleTimeLayout, at, time.Local); err != nil {
+		at = time.Now().Truncate(time.Hour).Add(time.Hour).Format(scheduleTimeLayout)
+	}
+
+	// Archived items can no longer be used for new sessions
+	component := pages.ScheduleNew(
+		scenarios.Available(ScenarioStore.GetAll()),
+		avatars.Available(AvatarStore.GetAll()),
+		observers.Available(ObserverStore.GetAll()),
+		trainees.Available(TraineeStore.GetAll()),
+		settings.EnabledStations(settingsStore.GetStations()),
+		at,
+	)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := component.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering schedule form: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// ScheduleSessionHandler books a session for a trainee at a later time: POST /schedule.
+// The session supervisor sends it to its station when the start time is reached.
+func ScheduleSessionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	scenarioID := r.FormValue("scenario_id")
+	avatarID := r.FormValue("avatar_id")
+	observerID := r.FormValue("observer_id")
+	stationID := r.FormValue("station_id")
+	traineeID := r.FormValue("trainee_id")
+
+	if scenarioID == "" || avatarID == "" || observerID == "" || stationID == "" || traineeID == "" {
+		renderStartError(w, r, "Missing required fields", http.StatusBadRequest)
+		return
+	}
+
+	scheduledFor, err := time.ParseInLocation(scheduleTimeLayout, r.FormValue("scheduled_for"), time.Local)
+	if err != nil {
+		renderStartError(w, r, "Invalid start time", http.StatusBadRequest)
+		return
+	}
+	if scheduledFor.Before(time.Now()) {
+		renderStartError(w, r, "The start time is in the past. Start the session from New Session instead.", http.StatusBadRequest)
+		return
+	}
+
+	// Only the station has to exist now; whether it is free is decided at the start time
+	station, err := models.FindStation(settingsStore, stationID)
+	if err != nil {
+		renderStartError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !station.Enabled {
+		renderStartError(w, r, "Station "+station.Name+" is disabled", http.StatusBadRequest)
+		return
+	}
+
+	session, err := models.CreateSession(SessionStore, ScenarioStore, ScenarioRevisionStore, AvatarStore, ObserverStore, TraineeStore, sessions.Session{
+		ScenarioID:   scenarioID,
+		AvatarID:     avatarID,
+		ObserverID:   observerID,
+		StationID:    station.ID,
+		TraineeID:    traineeID,
+		ScheduledFor: &scheduledFor,
+	})
+	if err != nil {
+		switch err {
+		case models.ErrScenarioNotFound, models.ErrAvatarNotFound, models.ErrObserverNotFound, models.ErrTraineeNotFound, models.ErrArchived:
+			renderStartError(w, r, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	log.Printf("Session %s scheduled for %s on station %s", session.ID, scheduledFor.Format(scheduleTimeLayout), station.ID)
+
+	weekStart := sessions.WeekStart(scheduledFor)
+	if r.Header.Get("HX-Request") == "true" {
+		component := pages.ScheduleMainContent(weekStart, scheduleWeek(weekStart), stationNames(), time.Now())
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("HX-Push-Url", "/schedule?week="+weekStart.Format("2006-01-02"))
+		if err := component.Render(r.Context(), w); err != nil {
+			log.Printf("Error rendering schedule: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	http.Redirect(w, r, "/schedule?week="+weekStart.Format("2006-01-02"), http.StatusSeeOther)
+}
+
+// ScheduleCancelHandler cancels a session that has not reached its start time:
+// POST /schedule/{id}/cancel
+func ScheduleCancelHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract session ID from URL: /schedule/{id}/cancel
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 4 {
+		http.Error(w, "Invalid URL", http.StatusBadRequest)
+		return
+	}
+	sessionID := parts[2]
+
+	session, err := SessionStore.GetByID(sessionID)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if session.Status != sessions.StatusScheduled {
+		http.Error(w, "Session is "+session.Status+" and can no longer be cancelled", http.StatusConflict)
+		return
+	}
+
+	_, err = SessionStore.Transition(sessionID, sessions.Transition{
+		To:     sessions.StatusAborted,
+		Actor:  sessions.ActorTrainer,
+		Reason: "scheduled session cancelled",
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrIllegalTransition) {
+			http.Error(w, err.Error(), http.StatusConflict)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	log.Printf("Scheduled session %s cancelled", sessionID)
+
+	weekStart := sessions.WeekStart(session.QueuedSince())
+	if r.Header.Get("HX-Request") == "true" {
+		component := sessions.WeekCalendar(weekStart, scheduleWeek(weekStart), stationNames(), time.Now())
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := component.Render(r.Context(), w); err != nil {
+			log.Printf("Error rendering schedule calendar: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	http.Redirect(w, r, "/schedule?week="+weekStart.Format("2006-01-02"), http.StatusSeeOther)
+}
+
+// scheduleWeek returns the seven days from weekStart with the sessions scheduled on each
+func scheduleWeek(weekStart time.Time) []sessions.ScheduleDay {
+	days := make([]sessions.ScheduleDay, 7)
+	for i := range days {
+		days[i] = sessions.ScheduleDay{Date: weekStart.AddDate(0, 0, i), Sessions: []*sessions.Session{}}
+	}
+
+	weekEnd := weekStart.AddDate(0, 0, 7)
+	for _, session := range SessionStore.GetAll() {
+		if session.ScheduledFor == nil {
+			continue
+		}
+		at := session.ScheduledFor.In(weekStart.Location())
+		if at.Before(weekStart) || !at.Before(weekEnd) {
+			continue
+		}
+		// Find the day by its midnight, as days are not always 24 hours long
+		day := 6
+		for at.Before(days[day].Date) {
+			day--
+		}
+		days[day].Sessions = append(days[day].Sessions, session)
+	}
+
+	for _, day := range days {
+		sort.Slice(day.Sessions, func(i, j int) bool {
+			return day.Sessions[i].ScheduledFor.Before(*day.Sessions[j].ScheduledFor)
+		})
+	}
+	return days
+}
+
+// stationNames maps the ID of every configured station to its name
+func stationNames() map[string]string {
+	names := make(map[string]string)
+	for _, station := range settingsStore.GetStations() {
+		names[station.ID] = station.Name
+	}
+	return names
+}
+
+// SetupScheduleRoutes registers the schedule calendar and booking routes
+func SetupScheduleRoutes(mux *http.ServeMux) {
+	log.Println("Setting up schedule routes...")
+
+	// Calendar and booking
+	mux.HandleFunc("/schedule", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			SchedulePageHandler(w, r)
+		case http.MethodPost:
+			ScheduleSessionHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Booking form
+	mux.HandleFunc("/schedule/new", ScheduleFormHandler)
+
+	// Cancel a scheduled session
+	mux.HandleFunc("/schedule/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/cancel") {
+			ScheduleCancelHandler(w, r)
+			return
+		}
+		http.NotFound(w, r)
+	})
+
+	log.Println("Schedule routes registered successfully")
+}
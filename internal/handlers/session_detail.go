@@ -0,0 +1,179 @@
+// internal/handlers/session_detail.go
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/internal/models"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+	"github.com/saladinomario/vr-training-admin/templates/components/settings"
+	"github.com/saladinomario/vr-training-admin/templates/components/trainees"
+	"github.com/saladinomario/vr-training-admin/templates/pages"
+)
+
+// sessionExport is the document a session is exported as
+type sessionExport struct {
+	ExportedAt time.Time `json:"exportedAt"`
+	*sessions.SessionDetails
+	Events []sessions.Event `json:"events"`
+}
+
+// SessionDetailHandler shows a session with its configuration, timeline and event log:
+// GET /sessions/{id}
+func SessionDetailHandler(w http.ResponseWriter, r *http.Request, sessionID string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	details, err := models.GetSessionDetails(SessionStore, sessionID, ScenarioStore, AvatarStore, ObserverStore)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	component := pages.SessionShow(*details, EventStore.ListBySession(sessionID),
+		stationNames()[details.Session.StationID], enabledStationStatuses(), trainees.Available(TraineeStore.GetAll()))
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := component.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering session detail: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// SessionCompleteHandler shows the form a trainer completes a session with:
+// GET /sessions/{id}/complete
+func SessionCompleteHandler(w http.ResponseWriter, r *http.Request, sessionID string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session, err := SessionStore.GetByID(sessionID)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if !sessions.CanTransition(session.Status, sessions.StatusCompleted) {
+		http.Redirect(w, r, "/sessions/"+sessionID, http.StatusSeeOther)
+		return
+	}
+
+	component := pages.SessionComplete(session)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := component.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering session completion: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// parseRatings reads the ratings a trainer gave the rubric items of a session.
+// Items left blank are not rated.
+func parseRatings(r *http.Request, config *sessions.Configuration) (map[string]int, error) {
+	ratings := make(map[string]int)
+	for _, item := range config.ScoredItems() {
+		value := strings.TrimSpace(r.FormValue(sessions.RatingField(item.ID)))
+		if value == "" {
+			continue
+		}
+		rating, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("the rating of %s is not a whole number", item.Name)
+		}
+		ratings[item.ID] = rating
+	}
+	return ratings, nil
+}
+
+// SessionRerunHandler starts a new session with the configuration of a finished one:
+// POST /sessions/{id}/rerun. The station defaults to the one the session ran on.
+func SessionRerunHandler(w http.ResponseWriter, r *http.Request, sessionID string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	original, err := SessionStore.GetByID(sessionID)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if original.IsActive() {
+		renderStartError(w, r, "Session "+sessionID+" is still "+original.Status+" and cannot be rerun yet", http.StatusConflict)
+		return
+	}
+
+	stationID := r.FormValue("station_id")
+	if stationID == "" {
+		stationID = original.StationID
+	}
+	traineeID := r.FormValue("trainee_id")
+	if traineeID == "" {
+		traineeID = original.TraineeID
+	}
+	if traineeID == "" {
+		renderStartError(w, r, "Choose the trainee to rerun the session for", http.StatusBadRequest)
+		return
+	}
+
+	startSession(w, r, stationID, func(station settings.Station) (*sessions.Session, error) {
+		session, err := models.RerunSession(SessionStore, ScenarioStore, ScenarioRevisionStore, AvatarStore, ObserverStore, TraineeStore, original, station.ID, traineeID)
+		if err == nil {
+			log.Printf("Session %s reruns session %s", session.ID, sessionID)
+		}
+		return session, err
+	})
+}
+
+// SessionExportHandler downloads a session with its configuration and event log as JSON:
+// GET /sessions/{id}/export
+func SessionExportHandler(w http.ResponseWriter, r *http.Request, sessionID string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	details, err := models.GetSessionDetails(SessionStore, sessionID, ScenarioStore, AvatarStore, ObserverStore)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	data, err := json.MarshalIndent(sessionExport{
+		ExportedAt:     time.Now(),
+		SessionDetails: details,
+		Events:         EventStore.ListBySession(sessionID),
+	}, "", "  ")
+	if err != nil {
+		log.Printf("Error exporting session %s: %v", sessionID, err)
+		http.Error(w, "Failed to export", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+sessionID+`.json"`)
+	w.Write(data)
+}
+
+// sessionSubroute splits /sessions/{id}/{action} into the session ID and the action,
+// which is empty for /sessions/{id}
+func sessionSubroute(path string) (sessionID, action string) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(path, "/sessions/"), "/"), "/")
+	sessionID = parts[0]
+	if len(parts) > 1 {
+		action = parts[1]
+	}
+	return sessionID, action
+}
@@ -0,0 +1,44 @@
+// internal/handlers/session_index.go
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+	"github.com/saladinomario/vr-training-admin/templates/pages"
+)
+
+// SessionsIndexHandler lists the sessions matching the filters, sort order and page in the
+// query string: GET /sessions?status=completed&sort=score&desc=true&page=2.
+// HTMX requests only get the results, which keeps the filter form in place.
+func SessionsIndexHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	page := SessionStore.Query(sessions.ParseQuery(r.URL.Query()))
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if r.Header.Get("HX-Request") == "true" {
+		if err := sessions.SessionIndex(page, stationNames()).Render(r.Context(), w); err != nil {
+			log.Printf("Error rendering session index: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		// Keep the sort order of the filter form in step with the results
+		if err := sessions.SortInputs(page.Query, true).Render(r.Context(), w); err != nil {
+			log.Printf("Error rendering session sort: %v", err)
+		}
+		return
+	}
+
+	// Archived content stays filterable, since past sessions may have used it
+	component := pages.SessionsIndex(page, stationNames(),
+		ScenarioStore.GetAll(), AvatarStore.GetAll(), ObserverStore.GetAll(), settingsStore.GetStations(), TraineeStore.GetAll())
+	if err := component.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering sessions page: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
@@ -2,32 +2,25 @@
 package handlers
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
-	"os"
 	"strings"
 
 	"github.com/saladinomario/vr-training-admin/internal/models"
+	"github.com/saladinomario/vr-training-admin/internal/unreal"
+	"github.com/saladinomario/vr-training-admin/templates/components/avatars"
+	"github.com/saladinomario/vr-training-admin/templates/components/observers"
+	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
 	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+	"github.com/saladinomario/vr-training-admin/templates/components/settings"
+	"github.com/saladinomario/vr-training-admin/templates/components/stations"
+	"github.com/saladinomario/vr-training-admin/templates/components/trainees"
 	"github.com/saladinomario/vr-training-admin/templates/pages"
 )
 
-var SessionStore *models.SessionStore
-
-func init() {
-	// Create data directory if it doesn't exist
-	dataDir := "./data"
-	if err := os.MkdirAll(dataDir, 0755); err != nil {
-		log.Printf("Error creating data directory: %v", err)
-	}
-
-	// Initialize session store
-	sessionFilePath := dataDir + "/sessions.json"
-	SessionStore = models.NewSessionStore(sessionFilePath)
-}
-
 // StartSessionHandler handles the session creation form submission
 func StartSessionHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -44,25 +37,57 @@ func StartSessionHandler(w http.ResponseWriter, r *http.Request) {
 	scenarioID := r.FormValue("scenario_id")
 	avatarID := r.FormValue("avatar_id")
 	observerID := r.FormValue("observer_id")
+	stationID := r.FormValue("station_id")
+	traineeID := r.FormValue("trainee_id")
 
 	// Validate required fields
-	if scenarioID == "" || avatarID == "" || observerID == "" {
+	if scenarioID == "" || avatarID == "" || observerID == "" || stationID == "" || traineeID == "" {
 		http.Error(w, "Missing required fields", http.StatusBadRequest)
 		return
 	}
 
-	// Create session
-	session, err := SessionStore.Create(scenarioID, avatarID, observerID)
+	// Create the session with a snapshot of its configuration
+	startSession(w, r, stationID, func(station settings.Station) (*sessions.Session, error) {
+		return models.CreateSession(SessionStore, ScenarioStore, ScenarioRevisionStore, AvatarStore, ObserverStore, TraineeStore, sessions.Session{
+			ScenarioID: scenarioID,
+			AvatarID:   avatarID,
+			ObserverID: observerID,
+			StationID:  station.ID,
+			TraineeID:  traineeID,
+		})
+	})
+}
+
+// startSession starts a session on the station picked by the trainer, which must be idle.
+// create makes the session once the station and the concurrent session limit allow it.
+// The dashboard is shown afterwards.
+func startSession(w http.ResponseWriter, r *http.Request, stationID string, create func(station settings.Station) (*sessions.Session, error)) {
+	session, queued, err := admitSession(stationID, create)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		var refused *startRefusal
+		switch {
+		case errors.As(err, &refused):
+			renderStartError(w, r, refused.message, refused.status)
+		case errors.Is(err, models.ErrStationNotFound):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, models.ErrScenarioNotFound), errors.Is(err, models.ErrAvatarNotFound), errors.Is(err, models.ErrObserverNotFound),
+			errors.Is(err, models.ErrTraineeNotFound), errors.Is(err, models.ErrArchived):
+			renderStartError(w, r, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
-
-	// Start the session in Unreal Engine
-	go startUnrealEngineSession(session.ID)
+	if queued {
+		log.Printf("Session %s queued: the concurrent session limit is reached", session.ID)
+	}
 
 	// Return success response
 	if r.Header.Get("HX-Request") == "true" {
+		if queued {
+			writeAlert(w, true, "The concurrent session limit is reached, so the session was queued. It starts as soon as another session finishes.")
+		}
+
 		// Get updated dashboard content
 		recentSessions := SessionStore.GetRecent(5)
 		component := pages.DashboardContent(recentSessions)
@@ -79,6 +104,77 @@ func StartSessionHandler(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
+// startRefusal is why the station or the concurrent session limit did not allow a start
+type startRefusal struct {
+	message string
+	status  int
+}
+
+func (e *startRefusal) Error() string {
+	return e.message
+}
+
+// admitSession checks that the station is idle and a slot is free, creates the session with
+// create and sends it to the station, all under the supervisor's admission lock. A session
+// over the limit is created queued when OverLimitAction allows it.
+func admitSession(stationID string, create func(station settings.Station) (*sessions.Session, error)) (*sessions.Session, bool, error) {
+	var session *sessions.Session
+	queued := false
+	err := sessionSupervisor.Admit(func(running, limit int, ok bool) error {
+		status, err := findStationStatus(stationID)
+		if err != nil {
+			return err
+		}
+		station := status.Station
+		switch status.State {
+		case stations.StateIdle:
+		case stations.StateBusy:
+			return &startRefusal{fmt.Sprintf("Station %s is busy with session %s", station.Name, status.SessionID), http.StatusConflict}
+		default:
+			return &startRefusal{fmt.Sprintf("Station %s is %s", station.Name, status.State), http.StatusConflict}
+		}
+		if err := checkStationVersion(station); err != nil {
+			return &startRefusal{err.Error(), http.StatusBadRequest}
+		}
+
+		// Beyond MaxConcurrentSessions a session is rejected, or queued until a slot frees up
+		if !ok {
+			if settingsStore.GetGeneralSettings().OverLimitAction != settings.OverLimitQueue {
+				return &startRefusal{fmt.Sprintf("%d of %d concurrent sessions are already running. Wait for one to finish or raise the limit under Settings.", running, limit), http.StatusConflict}
+			}
+			queued = true
+		}
+
+		session, err = create(station)
+		if err != nil {
+			return err
+		}
+
+		// Queued sessions wait for the supervisor. Others take their slot before the lock is
+		// released; a failed send leaves the session queued for the supervisor to retry.
+		if !queued {
+			if err := startUnrealEngineSession(session.ID); err != nil {
+				log.Printf("Session %s stays queued: %v", session.ID, err)
+			}
+		}
+		return nil
+	})
+	return session, queued, err
+}
+
+// renderStartError reports why a session could not be started: HTMX requests get an alert
+// in the session form, which stays on the page, other requests the HTTP status
+func renderStartError(w http.ResponseWriter, r *http.Request, message string, status int) {
+	if r.Header.Get("HX-Request") != "true" {
+		http.Error(w, message, status)
+		return
+	}
+	w.Header().Set("HX-Retarget", "#session-form-response")
+	w.Header().Set("HX-Reswap", "innerHTML")
+	w.Header().Set("HX-Push-Url", "false")
+	writeAlert(w, false, message)
+}
+
 // SessionStatusHandler handles updating session status
 func SessionStatusHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -106,11 +202,12 @@ func SessionStatusHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Only allow valid status values
+	// Only allow the statuses a trainer can pick
 	validStatus := map[string]bool{
 		sessions.StatusRunning:   true,
 		sessions.StatusPaused:    true,
 		sessions.StatusCompleted: true,
+		sessions.StatusAborted:   true,
 	}
 
 	if !validStatus[status] {
@@ -118,19 +215,78 @@ func SessionStatusHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	session, err := SessionStore.GetByID(sessionID)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Reject changes the state machine does not allow before bothering the station
+	if !sessions.CanTransition(session.Status, status) {
+		renderStatusError(w, r, fmt.Errorf("session is %s and cannot be %s", session.Status, status), http.StatusConflict)
+		return
+	}
+
+	// A trainer completing a session may rate its rubric items, which are scored like
+	// the ratings of a station's completed event
+	if status == sessions.StatusCompleted {
+		ratings, err := parseRatings(r, session.Configuration)
+		if err != nil {
+			renderStatusError(w, r, err, http.StatusBadRequest)
+			return
+		}
+		if err := models.RateSession(SessionStore, session, ratings); err != nil {
+			if errors.Is(err, models.ErrInvalidRatings) {
+				renderStatusError(w, r, err, http.StatusBadRequest)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+
+	// Pause and resume only take effect once the station confirms them. A session can
+	// always be completed or aborted, so a station that cannot be reached does not keep it open.
+	// Queued sessions have not been sent to their station, which has nothing to be told,
+	// and only paused sessions are resumed.
+	queued := session.IsQueued()
+	if !queued {
+		if err := commandStation(r.Context(), session, status); err != nil {
+			log.Printf("Session %s: %v", sessionID, err)
+			if status != sessions.StatusCompleted && status != sessions.StatusAborted {
+				renderStatusError(w, r, err, commandErrorStatus(err))
+				return
+			}
+		}
+	}
+
 	// Update session status
-	err := SessionStore.Update(sessionID, status)
+	updated, err := SessionStore.Transition(sessionID, sessions.Transition{
+		To:     status,
+		Actor:  sessions.ActorTrainer,
+		Reason: strings.TrimSpace(r.FormValue("reason")),
+	})
 	if err != nil {
-		if err == models.ErrSessionNotFound {
+		switch {
+		case errors.Is(err, models.ErrSessionNotFound):
 			http.NotFound(w, r)
-		} else {
+		case errors.Is(err, models.ErrIllegalTransition):
+			renderStatusError(w, r, err, http.StatusConflict)
+		default:
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 		}
 		return
 	}
 
 	// Update session in Unreal Engine
-	go updateUnrealEngineSession(sessionID, status)
+	if !queued {
+		go updateUnrealEngineSession(sessionID, status)
+	}
+
+	// A finished session frees a slot for the queue
+	if !updated.IsActive() {
+		sessionSupervisor.Wake()
+	}
 
 	// Return success response
 	if r.Header.Get("HX-Request") == "true" {
@@ -151,6 +307,65 @@ func SessionStatusHandler(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
+// renderStatusError reports a status change that did not happen: HTMX requests get an
+// alert above the refreshed session list, other requests the HTTP status
+func renderStatusError(w http.ResponseWriter, r *http.Request, err error, status int) {
+	if r.Header.Get("HX-Request") != "true" {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	writeAlert(w, false, err.Error())
+	if err := pages.RecentActivity(SessionStore.GetRecent(5)).Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering recent activity: %v", err)
+	}
+}
+
+// LegalHoldHandler places a session on legal hold or releases it
+func LegalHoldHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract session ID from URL: /sessions/{id}/legal-hold
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 4 {
+		http.Error(w, "Invalid URL", http.StatusBadRequest)
+		return
+	}
+	sessionID := parts[2]
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+	hold := r.FormValue("hold") == "true"
+
+	if err := SessionStore.SetLegalHold(sessionID, hold); err != nil {
+		if err == models.ErrSessionNotFound {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	log.Printf("Session %s legal hold set to %t", sessionID, hold)
+
+	if r.Header.Get("HX-Request") == "true" {
+		recentSessions := SessionStore.GetRecent(5)
+		component := pages.RecentActivity(recentSessions)
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := component.Render(r.Context(), w); err != nil {
+			log.Printf("Error rendering recent activity: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
 // SessionFormHandler handles serving the new session form
 func SessionFormHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -159,12 +374,15 @@ func SessionFormHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get all scenarios, avatars, and observers for form dropdowns
-	allScenarios := ScenarioStore.GetAll()
-	allAvatars := AvatarStore.GetAll()
-	allObservers := ObserverStore.GetAll()
+	// Archived items can no longer be used for new sessions
+	allScenarios := scenarios.Available(ScenarioStore.GetAll())
+	allAvatars := avatars.Available(AvatarStore.GetAll())
+	allObservers := observers.Available(ObserverStore.GetAll())
+	allTrainees := trainees.Available(TraineeStore.GetAll())
+	stationList := enabledStationStatuses()
 
 	// Render form page
-	component := pages.SessionNew(allScenarios, allAvatars, allObservers)
+	component := pages.SessionNew(allScenarios, allAvatars, allObservers, allTrainees, stationList)
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	if err := component.Render(r.Context(), w); err != nil {
@@ -177,19 +395,36 @@ func SessionFormHandler(w http.ResponseWriter, r *http.Request) {
 func SetupSessionRoutes(mux *http.ServeMux) {
 	log.Println("Setting up session routes...")
 
+	// Session index
+	mux.HandleFunc("/sessions", SessionsIndexHandler)
+
 	// Session form
 	mux.HandleFunc("/sessions/new", SessionFormHandler)
 
 	// Start session
 	mux.HandleFunc("/sessions/start", StartSessionHandler)
 
-	// Update session status
+	// Session detail, status updates, legal hold, rerun and export
 	mux.HandleFunc("/sessions/", func(w http.ResponseWriter, r *http.Request) {
-		if strings.HasPrefix(r.URL.Path, "/sessions/") && r.Method == http.MethodPost {
+		sessionID, action := sessionSubroute(r.URL.Path)
+		switch {
+		case sessionID == "":
+			http.NotFound(w, r)
+		case action == "legal-hold":
+			LegalHoldHandler(w, r)
+		case action == "rerun":
+			SessionRerunHandler(w, r, sessionID)
+		case action == "export":
+			SessionExportHandler(w, r, sessionID)
+		case action == "complete":
+			SessionCompleteHandler(w, r, sessionID)
+		case action == "" && r.Method == http.MethodPost:
 			SessionStatusHandler(w, r)
-			return
+		case action == "":
+			SessionDetailHandler(w, r, sessionID)
+		default:
+			http.NotFound(w, r)
 		}
-		http.NotFound(w, r)
 	})
 
 	log.Println("Session routes registered successfully")
@@ -197,74 +432,84 @@ func SetupSessionRoutes(mux *http.ServeMux) {
 
 // Unreal Engine integration functions
 
-// startUnrealEngineSession sends a request to start a session in Unreal Engine
-func startUnrealEngineSession(sessionID string) {
-	// Update status to "running"
-	err := SessionStore.Update(sessionID, sessions.StatusRunning)
+// startUnrealEngineSession sends a pending session to its station in Unreal Engine.
+// It fails with models.ErrIllegalTransition when the session was already sent. The payload
+// is built first, so a session whose payload cannot be built stays queued instead of
+// holding a slot and its station.
+func startUnrealEngineSession(sessionID string) error {
+	payload, err := models.CreateUREStartPayload(SessionStore, sessionID, ScenarioStore, AvatarStore, ObserverStore)
+	if err != nil {
+		log.Printf("Error creating UE payload: %v", err)
+		return err
+	}
+
+	// The session runs once the station accepts the payload or reports it started
+	_, err = SessionStore.Transition(sessionID, sessions.Transition{
+		To:     sessions.StatusStarting,
+		Actor:  sessions.ActorSystem,
+		Reason: "session sent to the station",
+	})
 	if err != nil {
 		log.Printf("Error updating session status: %v", err)
-		return
+		return err
 	}
 
-	// Create payload for Unreal Engine
-	payload, err := SessionStore.CreateURESessionPayload(sessionID, ScenarioStore, AvatarStore, ObserverStore)
+	// Queue for delivery to Unreal Engine
+	sendToUnrealEngine(sessionID, payload)
+	return nil
+}
+
+// startQueuedSession sends a queued session to its station. When that station is busy or
+// unreachable the session moves to the next free station first; while no station is free
+// it stays queued and supervisor.ErrNoFreeStation is returned.
+func startQueuedSession(sessionID string) error {
+	session, err := SessionStore.GetByID(sessionID)
 	if err != nil {
-		log.Printf("Error creating UE payload: %v", err)
-		return
+		return err
+	}
+	station, err := freeStation(session)
+	if err != nil {
+		return err
 	}
+	if station.ID != session.StationID {
+		if err := SessionStore.SetStation(sessionID, station.ID); err != nil {
+			return err
+		}
+		log.Printf("Session %s moved from station %s to the free station %s", sessionID, session.StationID, station.ID)
+	}
+	return startUnrealEngineSession(sessionID)
+}
 
-	// Send to Unreal Engine
-	sendToUnrealEngine(payload)
+// notifySessionFinished tells the station of a session the admin finished on its own,
+// such as after a timeout, over the control channel and with a status payload
+func notifySessionFinished(session *sessions.Session) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), unreal.AckTimeout)
+		defer cancel()
+		if err := commandStation(ctx, session, session.Status); err != nil {
+			log.Printf("Session %s: %v", session.ID, err)
+		}
+	}()
+	go updateUnrealEngineSession(session.ID, session.Status)
 }
 
 // updateUnrealEngineSession sends a request to update a session in Unreal Engine
 func updateUnrealEngineSession(sessionID, status string) {
 	// Create payload for Unreal Engine
-	payload, err := SessionStore.CreateURESessionPayload(sessionID, ScenarioStore, AvatarStore, ObserverStore)
+	payload, err := models.CreateURESessionPayload(SessionStore, sessionID, ScenarioStore, AvatarStore, ObserverStore)
 	if err != nil {
 		log.Printf("Error creating UE payload: %v", err)
 		return
 	}
 
-	// Send to Unreal Engine
-	sendToUnrealEngine(payload)
+	// Queue for delivery to Unreal Engine
+	sendToUnrealEngine(sessionID, payload)
 }
 
-// sendToUnrealEngine sends data to the Unreal Engine endpoint
-func sendToUnrealEngine(payload []byte) {
-	// TODO: Get this from settings
-	unrealEndpoint := "http://localhost:8081/api/vr-session"
-
-	// For now, just log the payload
-	log.Printf("Would send to Unreal Engine: %s", unrealEndpoint)
-
-	// Create a nicely formatted version of the payload for logging
-	var prettyJSON bytes.Buffer
-	if err := json.Indent(&prettyJSON, payload, "", "  "); err == nil {
-		log.Printf("Payload: %s", prettyJSON.String())
+// sendToUnrealEngine queues a payload for delivery to the station the session runs on.
+// Delivery is retried in the background; the session is marked failed when it is given up.
+func sendToUnrealEngine(sessionID string, payload []byte) {
+	if err := ueDispatcher.Enqueue(sessionID, payload); err != nil {
+		log.Printf("Error queuing Unreal Engine payload for session %s: %v", sessionID, err)
 	}
-
-	// In production, uncomment this code to actually send the request
-	/*
-		client := &http.Client{
-			Timeout: 10 * time.Second,
-		}
-
-		req, err := http.NewRequest("POST", unrealEndpoint, bytes.NewBuffer(payload))
-		if err != nil {
-			log.Printf("Error creating request: %v", err)
-			return
-		}
-
-		req.Header.Set("Content-Type", "application/json")
-
-		resp, err := client.Do(req)
-		if err != nil {
-			log.Printf("Error sending request to Unreal Engine: %v", err)
-			return
-		}
-		defer resp.Body.Close()
-
-		log.Printf("Unreal Engine response status: %s", resp.Status)
-	*/
 }
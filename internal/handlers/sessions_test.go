@@ -0,0 +1,81 @@
+// internal/handlers/sessions_test.go
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/saladinomario/vr-training-admin/internal/config"
+	"github.com/saladinomario/vr-training-admin/internal/models"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+)
+
+// unreadableSessions fails to look up sessions, so their payload cannot be built
+type unreadableSessions struct {
+	models.SessionRepository
+}
+
+func (unreadableSessions) GetByID(string) (*sessions.Session, error) {
+	return nil, errors.New("disk error")
+}
+
+func TestStartUnrealEngineSession(t *testing.T) {
+	tests := []struct {
+		name       string
+		unreadable bool
+		wantStatus string
+	}{
+		{"payload queued", false, sessions.StatusStarting},
+		{"payload cannot be built", true, sessions.StatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := InitStores(config.Config{DataDir: t.TempDir(), Storage: config.StorageMemory}); err != nil {
+				t.Fatal(err)
+			}
+			store := SessionStore
+			session, err := store.Create(sessions.Session{Configuration: &sessions.Configuration{}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.unreadable {
+				SessionStore = unreadableSessions{store}
+			}
+
+			err = startUnrealEngineSession(session.ID)
+			if gotErr := err != nil; gotErr != tt.unreadable {
+				t.Fatalf("startUnrealEngineSession = %v, want an error: %t", err, tt.unreadable)
+			}
+			started, err := store.GetByID(session.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if started.Status != tt.wantStatus {
+				t.Errorf("session is %s, want %s", started.Status, tt.wantStatus)
+			}
+
+			pending := ueDispatcher.Pending()
+			if tt.unreadable {
+				if len(pending) != 0 {
+					t.Errorf("%d deliveries queued for a session that was not started", len(pending))
+				}
+				return
+			}
+			if len(pending) != 1 {
+				t.Fatalf("%d deliveries queued, want 1", len(pending))
+			}
+			var payload struct {
+				SessionID string `json:"sessionId"`
+				Status    string `json:"status"`
+			}
+			if err := json.Unmarshal(pending[0].Payload, &payload); err != nil {
+				t.Fatal(err)
+			}
+			if payload.SessionID != session.ID || payload.Status != sessions.StatusStarting {
+				t.Errorf("payload describes session %s as %s, want %s as starting", payload.SessionID, payload.Status, session.ID)
+			}
+		})
+	}
+}
@@ -3,10 +3,12 @@
 package handlers
 
 import (
+	"fmt"
+	"html"
 	"log"
 	"net/http"
-	"os"
 	"strconv"
+	"strings"
 
 	"github.com/a-h/templ"
 	"github.com/saladinomario/vr-training-admin/internal/models"
@@ -14,27 +16,6 @@ import (
 	"github.com/saladinomario/vr-training-admin/templates/pages"
 )
 
-var settingsStore *models.SettingsStore
-
-func init() {
-	log.Println("Initializing settings handler...")
-
-	// Create data directory if it doesn't exist
-	dataDir := "./data"
-	if err := os.MkdirAll(dataDir, 0755); err != nil {
-		log.Printf("Error creating data directory: %v", err)
-	} else {
-		log.Printf("Data directory ensured: %s", dataDir)
-	}
-
-	// Initialize settings store
-	settingsFilePath := dataDir + "/settings.json"
-	log.Printf("Using settings file: %s", settingsFilePath)
-	settingsStore = models.NewSettingsStore(settingsFilePath)
-
-	log.Println("Settings handler initialized successfully")
-}
-
 // SettingsHandler handles the settings index page
 func SettingsHandler(w http.ResponseWriter, r *http.Request) {
 	if r.URL.Path != "/settings" {
@@ -50,7 +31,7 @@ func SettingsHandler(w http.ResponseWriter, r *http.Request) {
 	llmSettings := settingsStore.GetLLMSettings()
 	generalSettings := settingsStore.GetGeneralSettings()
 
-	component := pages.SettingsIndex(llmSettings, generalSettings)
+	component := pages.SettingsIndex(llmSettings, generalSettings, settingsStore.GetStations())
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	if err := component.Render(r.Context(), w); err != nil {
@@ -142,7 +123,7 @@ func TestConnectionHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Test connection
-	success, message, response := settingsStore.TestConnection(prompt)
+	success, message, response := models.TestLLMConnection(settingsStore.GetLLMSettings(), prompt)
 
 	// Render test result
 	component := settings.ConnectionResult(success, message, response)
@@ -179,8 +160,11 @@ func parseLLMSettingsForm(r *http.Request) settings.LLMSettings {
 	}
 }
 
-// Helper function to parse general settings form
+// Helper function to parse general settings form.
+// Settings without a form field keep their current value.
 func parseGeneralSettingsForm(r *http.Request) settings.GeneralSettings {
+	generalSettings := settingsStore.GetGeneralSettings()
+
 	sessionTimeout, _ := strconv.Atoi(r.FormValue("session_timeout"))
 	if sessionTimeout == 0 {
 		sessionTimeout = 60 // Default value
@@ -197,14 +181,85 @@ func parseGeneralSettingsForm(r *http.Request) settings.GeneralSettings {
 		logLevel = "INFO"
 	}
 
-	return settings.GeneralSettings{
-		ApplicationName:   applicationName,
-		LogLevel:          logLevel,
-		SessionTimeout:    sessionTimeout,
-		RecordSessions:    true, // Default value
-		StoreSessionData:  true, // Default value
-		DataRetentionDays: 90,   // Default value
+	retentionDays, err := strconv.Atoi(r.FormValue("data_retention_days"))
+	if err != nil || retentionDays < 0 {
+		retentionDays = generalSettings.DataRetentionDays
 	}
+
+	maxConcurrent, err := strconv.Atoi(r.FormValue("max_concurrent_sessions"))
+	if err != nil || maxConcurrent < 0 {
+		maxConcurrent = generalSettings.MaxConcurrentSessions
+	}
+
+	practiceInterval, err := strconv.Atoi(r.FormValue("practice_interval_days"))
+	if err != nil || practiceInterval < 0 {
+		practiceInterval = generalSettings.PracticeIntervalDays
+	}
+
+	overLimitAction := r.FormValue("over_limit_action")
+	if overLimitAction != settings.OverLimitQueue {
+		overLimitAction = settings.OverLimitReject
+	}
+
+	timeoutAction := r.FormValue("timeout_action")
+	if timeoutAction != settings.TimeoutComplete {
+		timeoutAction = settings.TimeoutFail
+	}
+
+	retentionAction := r.FormValue("retention_action")
+	if retentionAction != settings.RetentionAnonymize {
+		retentionAction = settings.RetentionDelete
+	}
+
+	generalSettings.ApplicationName = applicationName
+	generalSettings.LogLevel = logLevel
+	generalSettings.SessionTimeout = sessionTimeout
+	generalSettings.MaxConcurrentSessions = maxConcurrent
+	generalSettings.OverLimitAction = overLimitAction
+	generalSettings.TimeoutAction = timeoutAction
+	generalSettings.RecordSessions = r.FormValue("record_sessions") == "on"
+	generalSettings.StoreSessionData = r.FormValue("store_session_data") == "on"
+	generalSettings.DataRetentionDays = retentionDays
+	generalSettings.RetentionAction = retentionAction
+	generalSettings.PracticeIntervalDays = practiceInterval
+	return generalSettings
+}
+
+// RunRetentionHandler applies the data retention policy immediately
+func RunRetentionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	report := retentionWorker.RunOnce()
+	if len(report.Errors) > 0 {
+		writeAlert(w, false, fmt.Sprintf("Retention finished with %d errors: %s",
+			len(report.Errors), strings.Join(report.Errors, "; ")))
+		return
+	}
+	if len(report.Actions) == 0 {
+		writeAlert(w, true, fmt.Sprintf("Nothing to purge. %d sessions are on legal hold.", report.Held))
+		return
+	}
+	writeAlert(w, true, fmt.Sprintf("Retention applied: %d actions, %d sessions on legal hold. See the server log for details.",
+		len(report.Actions), report.Held))
+}
+
+// writeAlert renders the result of an action as an alert
+func writeAlert(w http.ResponseWriter, success bool, message string) {
+	class, icon := "alert-success", `M9 12l2 2 4-4m6 2a9 9 0 11-18 0 9 9 0 0118 0z`
+	if !success {
+		class, icon = "alert-error", `M10 14l2-2m0 0l2-2m-2 2l-2-2m2 2l2 2m7-2a9 9 0 11-18 0 9 9 0 0118 0z`
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `
+    <div class="alert %s">
+        <svg xmlns="http://www.w3.org/2000/svg" class="stroke-current shrink-0 h-6 w-6" fill="none" viewBox="0 0 24 24"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="%s" /></svg>
+        <span>%s</span>
+    </div>
+    `, class, icon, html.EscapeString(message))
 }
 
 // SetupSettingsRoutes registers all settings-related routes
@@ -231,6 +286,19 @@ func SetupSettingsRoutes(mux *http.ServeMux) {
 	log.Println("  Registering route: /settings/provider-fields")
 	mux.HandleFunc("/settings/provider-fields", ProviderFieldsHandler)
 
+	// Run the retention policy now
+	log.Println("  Registering route: /settings/retention")
+	mux.HandleFunc("/settings/retention", RunRetentionHandler)
+
+	// VR stations
+	log.Println("  Registering route: /settings/stations")
+	mux.HandleFunc("/settings/stations", StationsHandler)
+	mux.HandleFunc("/settings/stations/", StationHandler)
+
+	// Backup download and restore
+	log.Println("  Registering route: /settings/backup")
+	mux.HandleFunc("/settings/backup", BackupHandler)
+
 	log.Println("Settings routes registered successfully")
 }
 
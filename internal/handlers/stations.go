@@ -0,0 +1,193 @@
+// internal/handlers/stations.go
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/saladinomario/vr-training-admin/internal/models"
+	"github.com/saladinomario/vr-training-admin/internal/uepayload"
+	"github.com/saladinomario/vr-training-admin/internal/unreal"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+	"github.com/saladinomario/vr-training-admin/templates/components/settings"
+)
+
+// StationsHandler handles the VR station list: POST /settings/stations adds a station
+func StationsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	station, err := models.SaveStation(settingsStore, parseStationForm(r), false)
+	if err != nil {
+		renderStationList(w, r, err.Error())
+		return
+	}
+	log.Printf("Added VR station %s (%s)", station.ID, station.Name)
+	renderStationList(w, r, "")
+}
+
+// StationHandler handles a single VR station:
+// POST /settings/stations/{id} updates it, DELETE removes it and POST /settings/stations/{id}/test checks it
+func StationHandler(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/settings/stations/")
+	if strings.HasSuffix(path, "/test") {
+		TestStationHandler(w, r, strings.TrimSuffix(path, "/test"))
+		return
+	}
+	id := path
+
+	switch r.Method {
+	case http.MethodPost, http.MethodPut:
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+		station := parseStationForm(r)
+		station.ID = id
+		// The form never shows the saved token, so a blank one means "unchanged"
+		keepToken := station.AuthToken == "" && r.FormValue("clear_auth_token") != "on"
+		if _, err := models.SaveStation(settingsStore, station, keepToken); err != nil {
+			if err == models.ErrStationNotFound {
+				http.NotFound(w, r)
+				return
+			}
+			renderStationList(w, r, err.Error())
+			return
+		}
+		log.Printf("Updated VR station %s", id)
+	case http.MethodDelete:
+		if err := models.DeleteStation(settingsStore, id); err != nil {
+			if err == models.ErrStationNotFound {
+				http.NotFound(w, r)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		stationRegistry.Forget(id)
+		log.Printf("Deleted VR station %s", id)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	renderStationList(w, r, "")
+}
+
+// TestStationHandler checks that a station answers at its endpoint, like TestConnectionHandler for the LLM
+func TestStationHandler(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	station, err := models.FindStation(settingsStore, id)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), unreal.DefaultTimeout)
+	defer cancel()
+
+	var component templ.Component
+	elapsed, err := ueClient.Check(ctx, stationTarget(station))
+	if err != nil {
+		component = settings.ConnectionResult(false, fmt.Sprintf("%s did not answer at %s: %v", station.Name, station.Endpoint, err), "")
+	} else {
+		component = settings.ConnectionResult(true, fmt.Sprintf("%s answered in %d ms.", station.Name, elapsed.Milliseconds()), "")
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := component.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering station test result: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// renderStationList writes the station list, with an error message when an edit was rejected
+func renderStationList(w http.ResponseWriter, r *http.Request, message string) {
+	component := settings.StationList(settingsStore.GetStations(), message)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := component.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering station list: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// Helper function to parse station form data
+func parseStationForm(r *http.Request) settings.Station {
+	return settings.Station{
+		Name:      strings.TrimSpace(r.FormValue("name")),
+		Endpoint:  strings.TrimSpace(r.FormValue("endpoint")),
+		AuthToken: strings.TrimSpace(r.FormValue("auth_token")),
+		Enabled:   r.FormValue("enabled") == "on",
+	}
+}
+
+// stationTarget returns where payloads for station are sent
+func stationTarget(station settings.Station) unreal.Target {
+	return unreal.Target{Endpoint: station.Endpoint, AuthToken: station.AuthToken}
+}
+
+// sessionTarget resolves where the payloads of a session are sent
+func sessionTarget(sessionID string) (unreal.Target, error) {
+	session, err := SessionStore.GetByID(sessionID)
+	if err != nil {
+		return unreal.Target{}, err
+	}
+	station, err := sessionStation(session)
+	if err != nil {
+		return unreal.Target{}, err
+	}
+	if err := checkStationVersion(station); err != nil {
+		return unreal.Target{}, err
+	}
+	return stationTarget(station), nil
+}
+
+// checkStationVersion refuses a station that advertised payload versions without the
+// one the admin sends. Stations that have not advertised any are assumed compatible.
+func checkStationVersion(station settings.Station) error {
+	state, ok := stationRegistry.Get(station.ID)
+	if !ok || len(state.PayloadVersions) == 0 {
+		return nil
+	}
+	if err := uepayload.CheckCompatible(state.PayloadVersions); err != nil {
+		return fmt.Errorf("%s: %w", station.Name, err)
+	}
+	return nil
+}
+
+// sessionStation returns the enabled station a session runs on. Sessions started before
+// stations existed run on the first enabled station.
+func sessionStation(session *sessions.Session) (settings.Station, error) {
+	if session.StationID == "" {
+		enabled := settings.EnabledStations(settingsStore.GetStations())
+		if len(enabled) == 0 {
+			return settings.Station{}, fmt.Errorf("no VR station is enabled")
+		}
+		return enabled[0], nil
+	}
+
+	station, err := models.FindStation(settingsStore, session.StationID)
+	if err != nil {
+		return settings.Station{}, fmt.Errorf("station %s: %w", session.StationID, err)
+	}
+	if !station.Enabled {
+		return settings.Station{}, fmt.Errorf("station %s is disabled", station.Name)
+	}
+	return station, nil
+}
@@ -0,0 +1,91 @@
+// internal/handlers/stores.go
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/netip"
+
+	"github.com/saladinomario/vr-training-admin/internal/config"
+	"github.com/saladinomario/vr-training-admin/internal/models"
+	"github.com/saladinomario/vr-training-admin/internal/retention"
+	"github.com/saladinomario/vr-training-admin/internal/supervisor"
+	"github.com/saladinomario/vr-training-admin/internal/unreal"
+)
+
+// Stores shared by all handlers, wired up by InitStores
+var (
+	ScenarioStore         models.ScenarioRepository
+	ScenarioRevisionStore models.ScenarioRevisionRepository
+	AvatarStore           models.AvatarRepository
+	ObserverStore         models.ObserverRepository
+	TraineeStore          models.TraineeRepository
+	SessionStore          models.SessionRepository
+	EventStore            models.EventRepository
+	settingsStore         models.SettingsRepository
+
+	// stores and dataDir back the whole-data operations such as backup and restore
+	stores  *models.Stores
+	dataDir string
+
+	// trustedProxies are the reverse proxies whose X-Remote-User header is believed
+	trustedProxies []netip.Prefix
+
+	retentionWorker   *retention.Worker
+	sessionSupervisor *supervisor.Supervisor
+	ueClient          *unreal.Client
+	ueDispatcher      *unreal.Dispatcher
+	controlHub        *unreal.Hub
+	stationRegistry   *unreal.Registry
+)
+
+// InitStores opens the storage backend selected in cfg and makes it available to the handlers.
+// It must be called before any routes are served.
+func InitStores(cfg config.Config) error {
+	log.Println("Initializing stores...")
+
+	proxies, err := cfg.ProxyPrefixes()
+	if err != nil {
+		return err
+	}
+	trustedProxies = proxies
+
+	opened, err := models.OpenStores(cfg)
+	if err != nil {
+		return err
+	}
+	stores = opened
+	dataDir = cfg.DataDir
+
+	ScenarioStore = stores.Scenarios
+	ScenarioRevisionStore = stores.ScenarioRevisions
+	AvatarStore = stores.Avatars
+	ObserverStore = stores.Observers
+	TraineeStore = stores.Trainees
+	SessionStore = stores.Sessions
+	EventStore = stores.Events
+	settingsStore = stores.Settings
+
+	retentionWorker = retention.NewWorker(SessionStore, settingsStore)
+	retentionWorker.Register(retention.NewEventLog(EventStore))
+	ueClient = unreal.NewClient(nil)
+	ueDispatcher = unreal.NewDispatcher(ueClient, stores.Outbox, SessionStore, sessionTarget, unreal.DefaultRetryPolicy)
+	stationRegistry = unreal.NewRegistry()
+	controlHub = unreal.NewHub(authenticateStation, stationRegistry)
+	sessionSupervisor = supervisor.New(SessionStore, settingsStore, notifySessionFinished, startQueuedSession)
+
+	log.Println("Stores initialized successfully")
+	return nil
+}
+
+// StartBackgroundJobs starts the workers that run alongside the HTTP server until ctx is cancelled
+func StartBackgroundJobs(ctx context.Context) {
+	log.Println("Starting retention worker")
+	retentionWorker.Start(ctx, retention.DefaultInterval)
+
+	log.Println("Starting Unreal Engine dispatcher")
+	ueDispatcher.Start(ctx)
+
+	log.Println("Starting session supervisor")
+	sessionSupervisor.Start(ctx, supervisor.DefaultInterval)
+}
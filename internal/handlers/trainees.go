@@ -0,0 +1,334 @@
+// internal/handlers/trainees.go
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/internal/models"
+	"github.com/saladinomario/vr-training-admin/templates/components/trainees"
+	"github.com/saladinomario/vr-training-admin/templates/pages"
+)
+
+// TraineesHandler handles the trainees index page
+func TraineesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/trainees" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	component := pages.TraineesIndex(TraineeStore.GetAll())
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := component.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering trainees page: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// TraineeNewHandler handles the new trainee form
+func TraineeNewHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	component := pages.TraineeNew()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := component.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering new trainee form: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// TraineeShowHandler shows the progress of a trainee: GET /trainees/{id}
+func TraineeShowHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/trainees/")
+	trainee, err := TraineeStore.GetByID(id)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	interval := settingsStore.GetGeneralSettings().PracticeIntervalDays
+	progress := models.TraineeProgress(SessionStore, ScenarioStore, trainee, interval, time.Now())
+	component := pages.TraineeShow(progress, SessionStore.ListByTrainee(id))
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := component.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering trainee progress: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// TraineeEditHandler handles the edit trainee form
+func TraineeEditHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract ID from URL
+	idStr := strings.TrimPrefix(r.URL.Path, "/trainees/edit/")
+	if idStr == r.URL.Path {
+		http.NotFound(w, r)
+		return
+	}
+
+	trainee, err := TraineeStore.GetByID(idStr)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	component := pages.TraineeEdit(trainee)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := component.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering edit trainee form: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// TraineeCreateHandler handles trainee creation
+func TraineeCreateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := TraineeStore.Create(parseTraineeForm(r)); err != nil {
+		renderTraineeFormError(w, r, err)
+		return
+	}
+
+	renderTraineesContent(w, r)
+}
+
+// TraineeUpdateHandler handles trainee updates
+func TraineeUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract ID from URL
+	idStr := strings.TrimPrefix(r.URL.Path, "/trainees/")
+	if idStr == r.URL.Path {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	if err := TraineeStore.Update(idStr, parseTraineeForm(r)); err != nil {
+		if err == models.ErrTraineeNotFound {
+			http.NotFound(w, r)
+		} else {
+			renderTraineeFormError(w, r, err)
+		}
+		return
+	}
+
+	renderTraineesContent(w, r)
+}
+
+// renderTraineesContent shows the trainee list after a trainee was saved
+func renderTraineesContent(w http.ResponseWriter, r *http.Request) {
+	// If this is an HTMX request, return the main content
+	if r.Header.Get("HX-Request") == "true" {
+		component := pages.TraineesMainContent(TraineeStore.GetAll())
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := component.Render(r.Context(), w); err != nil {
+			log.Printf("Error rendering trainees content: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Standard redirect for non-HTMX requests
+	http.Redirect(w, r, "/trainees", http.StatusSeeOther)
+}
+
+// renderTraineeFormError reports why a trainee could not be saved. HTMX requests get an
+// alert in the form, which stays on the page with what was entered.
+func renderTraineeFormError(w http.ResponseWriter, r *http.Request, err error) {
+	if r.Header.Get("HX-Request") != "true" {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("HX-Retarget", "#trainee-form-response")
+	w.Header().Set("HX-Reswap", "innerHTML")
+	w.Header().Set("HX-Push-Url", "false")
+	writeAlert(w, false, err.Error())
+}
+
+// TraineeDeleteHandler handles trainee deletion
+func TraineeDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract ID from URL
+	idStr := strings.TrimPrefix(r.URL.Path, "/trainees/")
+	if idStr == r.URL.Path {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Delete trainee, unless sessions still refer to it
+	err := models.DeleteTrainee(TraineeStore, SessionStore, idStr)
+	if err != nil {
+		var inUse *models.InUseError
+		if err == models.ErrTraineeNotFound {
+			http.NotFound(w, r)
+		} else if errors.As(err, &inUse) {
+			log.Printf("Refusing to delete trainee %s: %v", idStr, err)
+			if r.Header.Get("HX-Request") == "true" {
+				writeAlert(w, false, err.Error())
+				renderTraineeList(w, r)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusConflict)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		renderTraineeList(w, r)
+		return
+	}
+
+	// Standard redirect for non-HTMX requests
+	http.Redirect(w, r, "/trainees", http.StatusSeeOther)
+}
+
+// TraineeArchiveHandler archives a trainee or brings them back: POST /trainees/{id}/archive
+func TraineeArchiveHandler(w http.ResponseWriter, r *http.Request) {
+	serveArchive(w, r, archiveRoute{
+		label:       "Trainee",
+		path:        "/trainees",
+		setArchived: TraineeStore.SetArchived,
+		notFound:    models.ErrTraineeNotFound,
+		renderList:  renderTraineeList,
+	})
+}
+
+// renderTraineeList writes the trainee list for HTMX requests
+func renderTraineeList(w http.ResponseWriter, r *http.Request) {
+	component := trainees.TraineeList(TraineeStore.GetAll())
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := component.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering trainee list: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// TraineeSearchHandler handles trainee search
+func TraineeSearchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	component := trainees.TraineeList(TraineeStore.Search(r.URL.Query().Get("q")))
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := component.Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering trainee search results: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// Helper function to parse trainee form data
+func parseTraineeForm(r *http.Request) trainees.Trainee {
+	// Selected common needs come first, then any others, one per line
+	needs := append([]string{}, r.Form["accessibility_needs"]...)
+	for _, line := range strings.Split(r.FormValue("custom_accessibility_needs"), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			needs = append(needs, line)
+		}
+	}
+
+	return trainees.Trainee{
+		Name:               strings.TrimSpace(r.FormValue("name")),
+		EmployeeID:         strings.TrimSpace(r.FormValue("employee_id")),
+		Department:         strings.TrimSpace(r.FormValue("department")),
+		Language:           r.FormValue("language"),
+		AccessibilityNeeds: needs,
+	}
+}
+
+// SetupTraineeRoutes registers all trainee-related routes
+func SetupTraineeRoutes(mux *http.ServeMux) {
+	log.Println("Setting up trainee routes...")
+
+	// List and Create
+	mux.HandleFunc("/trainees", func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("Handling trainee request: %s %s", r.Method, r.URL.Path)
+		switch r.Method {
+		case http.MethodGet:
+			TraineesHandler(w, r)
+		case http.MethodPost:
+			TraineeCreateHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// New form
+	mux.HandleFunc("/trainees/new", TraineeNewHandler)
+
+	// Search
+	mux.HandleFunc("/trainees/search", TraineeSearchHandler)
+
+	// Edit form
+	mux.HandleFunc("/trainees/edit/", TraineeEditHandler)
+
+	// Progress, Update, Delete and Archive
+	mux.HandleFunc("/trainees/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/archive") {
+			TraineeArchiveHandler(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			TraineeShowHandler(w, r)
+		case http.MethodPut, http.MethodPost:
+			TraineeUpdateHandler(w, r)
+		case http.MethodDelete:
+			TraineeDeleteHandler(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	log.Println("Trainee routes registered successfully")
+}
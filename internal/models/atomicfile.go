@@ -0,0 +1,78 @@
+// internal/models/atomicfile.go
+package models
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// writeFileAtomic replaces the file at path with data so that readers and crashes only
+// ever observe the old or the new content. The data goes to a temporary file in the same
+// directory, which is fsynced and then renamed over path. The previous version is kept
+// as path+".bak" so that a damaged file can be recovered on the next start.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // no-op once the rename succeeded
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing temp file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("syncing temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+
+	// Keep the last good version around before replacing it
+	if _, err := os.Stat(path); err == nil {
+		backup := path + ".bak"
+		os.Remove(backup)
+		if err := os.Link(path, backup); err != nil {
+			log.Printf("Warning: could not keep backup of %s: %v", path, err)
+		}
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("replacing %s: %w", path, err)
+	}
+
+	return syncDir(dir)
+}
+
+// syncDir flushes directory metadata so that a completed rename survives a crash
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil && !os.IsPermission(err) {
+		return err
+	}
+	return nil
+}
+
+// removeStaleTempFiles deletes temporary files left behind by an interrupted write to path
+func removeStaleTempFiles(path string) {
+	matches, _ := filepath.Glob(path + ".*.tmp")
+	for _, m := range matches {
+		os.Remove(m)
+	}
+}
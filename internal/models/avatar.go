@@ -3,9 +3,9 @@ package models
 
 import (
 	"errors"
+	"log"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/saladinomario/vr-training-admin/templates/components/avatars"
 )
@@ -13,6 +13,7 @@ import (
 var (
 	ErrAvatarNotFound = errors.New("avatar not found")
 	ErrInvalidAvatar  = errors.New("invalid avatar data")
+	ErrAvatarExists   = errors.New("avatar already exists")
 )
 
 // AvatarStore implements an in-memory storage for avatars
@@ -106,12 +107,28 @@ func (s *AvatarStore) Create(avatar avatars.Avatar) (avatars.Avatar, error) {
 	return avatar, nil
 }
 
+// Insert adds an avatar under its own ID, as done when importing content
+func (s *AvatarStore) Insert(avatar avatars.Avatar) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if avatar.ID == "" || avatar.Name == "" {
+		return ErrInvalidAvatar
+	}
+	if _, ok := s.avatars[avatar.ID]; ok {
+		return ErrAvatarExists
+	}
+	s.avatars[avatar.ID] = avatar
+	return nil
+}
+
 // Update modifies an existing avatar
 func (s *AvatarStore) Update(id string, avatar avatars.Avatar) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, ok := s.avatars[id]; !ok {
+	existing, ok := s.avatars[id]
+	if !ok {
 		return ErrAvatarNotFound
 	}
 
@@ -120,8 +137,23 @@ func (s *AvatarStore) Update(id string, avatar avatars.Avatar) error {
 		return ErrInvalidAvatar
 	}
 
-	// Preserve the ID
+	// Preserve the ID and archive state
 	avatar.ID = id
+	avatar.Archived = existing.Archived
+	s.avatars[id] = avatar
+	return nil
+}
+
+// SetArchived archives an avatar, or brings it back
+func (s *AvatarStore) SetArchived(id string, archived bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	avatar, ok := s.avatars[id]
+	if !ok {
+		return ErrAvatarNotFound
+	}
+	avatar.Archived = archived
 	s.avatars[id] = avatar
 	return nil
 }
@@ -162,7 +194,104 @@ func (s *AvatarStore) Search(query string) []avatars.Avatar {
 	return result
 }
 
+// replaceAll swaps the whole content of the store
+func (s *AvatarStore) replaceAll(list []avatars.Avatar) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.avatars = make(map[string]avatars.Avatar, len(list))
+	for _, avatar := range list {
+		s.avatars[avatar.ID] = avatar
+	}
+	return nil
+}
+
 // Helper to generate a simple ID
 func generateAvatarID() string {
-	return "avatar_" + time.Now().Format("20060102150405")
+	return newTimestampID("avatar_")
+}
+
+// FileAvatarStore persists avatars to a JSON file on top of the in-memory store
+type FileAvatarStore struct {
+	*AvatarStore
+	filePath string
+	writeMu  sync.Mutex
+}
+
+// NewFileAvatarStore loads avatars from filePath, seeding it with the sample data when the file does not exist yet
+func NewFileAvatarStore(filePath string) (*FileAvatarStore, error) {
+	store := &FileAvatarStore{
+		AvatarStore: NewAvatarStore(),
+		filePath:    filePath,
+	}
+
+	var doc listDocument[avatars.Avatar]
+	found, err := readDocument(DocumentAvatars, filePath, &doc)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return store, store.save()
+	}
+	list := doc.Items
+
+	store.AvatarStore.replaceAll(list)
+	log.Printf("Loaded %d avatars from %s", len(list), filePath)
+
+	return store, nil
+}
+
+// Create adds a new avatar and persists the store
+func (s *FileAvatarStore) Create(avatar avatars.Avatar) (avatars.Avatar, error) {
+	var created avatars.Avatar
+	err := s.change(func() (err error) {
+		created, err = s.AvatarStore.Create(avatar)
+		return err
+	})
+	return created, err
+}
+
+// Insert adds an avatar under its own ID and persists the store
+func (s *FileAvatarStore) Insert(avatar avatars.Avatar) error {
+	return s.change(func() error {
+		return s.AvatarStore.Insert(avatar)
+	})
+}
+
+// Update modifies an existing avatar and persists the store
+func (s *FileAvatarStore) Update(id string, avatar avatars.Avatar) error {
+	return s.change(func() error {
+		return s.AvatarStore.Update(id, avatar)
+	})
+}
+
+// Delete removes an avatar and persists the store
+func (s *FileAvatarStore) Delete(id string) error {
+	return s.change(func() error {
+		return s.AvatarStore.Delete(id)
+	})
+}
+
+// SetArchived archives an avatar, or brings it back, and persists the store
+func (s *FileAvatarStore) SetArchived(id string, archived bool) error {
+	return s.change(func() error {
+		return s.AvatarStore.SetArchived(id, archived)
+	})
+}
+
+// change applies a change to the store and persists it, restoring the previous content
+// when the file cannot be written
+func (s *FileAvatarStore) change(apply func() error) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	return changeListFile(s.filePath, s.AvatarStore.GetAll, s.AvatarStore.replaceAll, apply)
+}
+
+// save writes a snapshot of the store to disk
+func (s *FileAvatarStore) save() error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	return writeJSONFile(s.filePath, newListDocument(s.GetAll()))
 }
@@ -0,0 +1,330 @@
+// internal/models/events.go
+package models
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+)
+
+var ErrInvalidEvent = errors.New("invalid session event")
+
+// EventStore keeps the event logs of sessions in memory, in the order they were received
+type EventStore struct {
+	events map[string][]sessions.Event
+	mu     sync.RWMutex
+}
+
+// NewEventStore creates an empty event store
+func NewEventStore() *EventStore {
+	return &EventStore{events: make(map[string][]sessions.Event)}
+}
+
+// GetAll returns the events of every session, each log in the order it was received
+func (s *EventStore) GetAll() []sessions.Event {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return flattenLogs(s.events)
+}
+
+// flattenLogs lists the events of every log, ordered by session ID
+func flattenLogs(logs map[string][]sessions.Event) []sessions.Event {
+	ids := make([]string, 0, len(logs))
+	for id := range logs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	result := make([]sessions.Event, 0)
+	for _, id := range ids {
+		result = append(result, logs[id]...)
+	}
+	return result
+}
+
+// ListBySession returns the event log of a session
+func (s *EventStore) ListBySession(sessionID string) []sessions.Event {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]sessions.Event{}, s.events[sessionID]...)
+}
+
+// Append adds events to the logs of their sessions and returns the events that were stored.
+// Events whose ClientID is already in the session's log are skipped.
+func (s *EventStore) Append(events []sessions.Event) ([]sessions.Event, error) {
+	return s.append(events, nil)
+}
+
+// append adds events to their logs. When persist is set it is called with the logs as they
+// will be, and nothing changes if it fails, so a retried batch is not mistaken for a duplicate.
+func (s *EventStore) append(events []sessions.Event, persist func(logs map[string][]sessions.Event) error) ([]sessions.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// next shares the unchanged logs; changed ones are clipped so appending copies them
+	next := make(map[string][]sessions.Event, len(s.events))
+	for id, list := range s.events {
+		next[id] = slices.Clip(list)
+	}
+
+	now := time.Now()
+	added := make([]sessions.Event, 0, len(events))
+	for _, event := range events {
+		if containsClientID(next[event.SessionID], event.ClientID) {
+			continue
+		}
+		stampEvent(&event, now)
+		next[event.SessionID] = append(next[event.SessionID], event)
+		added = append(added, event)
+	}
+
+	if len(added) > 0 && persist != nil {
+		if err := persist(next); err != nil {
+			return nil, err
+		}
+	}
+	s.events = next
+	return added, nil
+}
+
+// DeleteBySession removes the event log of a session and returns the number of events removed
+func (s *EventStore) DeleteBySession(sessionID string) (int, error) {
+	return s.deleteBySession(sessionID, nil)
+}
+
+// deleteBySession removes the event log of a session, unless persist fails for the logs without it
+func (s *EventStore) deleteBySession(sessionID string, persist func(logs map[string][]sessions.Event) error) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.events[sessionID])
+	if n == 0 {
+		return 0, nil
+	}
+	if persist != nil {
+		next := make(map[string][]sessions.Event, len(s.events))
+		for id, list := range s.events {
+			if id != sessionID {
+				next[id] = list
+			}
+		}
+		if err := persist(next); err != nil {
+			return 0, err
+		}
+	}
+	delete(s.events, sessionID)
+	return n, nil
+}
+
+// replaceAll swaps the whole content of the store
+func (s *EventStore) replaceAll(list []sessions.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = make(map[string][]sessions.Event)
+	for _, event := range list {
+		s.events[event.SessionID] = append(s.events[event.SessionID], event)
+	}
+	return nil
+}
+
+// containsClientID reports whether list holds an event the station sent with clientID
+func containsClientID(list []sessions.Event, clientID string) bool {
+	if clientID == "" {
+		return false
+	}
+	for _, event := range list {
+		if event.ClientID == clientID {
+			return true
+		}
+	}
+	return false
+}
+
+// stampEvent assigns the ID and reception time of a new event
+func stampEvent(event *sessions.Event, now time.Time) {
+	event.ID = newTimestampID("event_")
+	event.ReceivedAt = now
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = now
+	}
+}
+
+// FileEventStore persists the event logs to a JSON file on top of the in-memory store.
+// Every change rewrites the whole file, so installations that keep long event logs are
+// better served by the SQLite backend, which appends rows instead.
+type FileEventStore struct {
+	*EventStore
+	filePath string
+}
+
+// NewFileEventStore loads the event logs from filePath
+func NewFileEventStore(filePath string) (*FileEventStore, error) {
+	store := &FileEventStore{
+		EventStore: NewEventStore(),
+		filePath:   filePath,
+	}
+
+	var doc listDocument[sessions.Event]
+	found, err := readDocument(DocumentSessionEvents, filePath, &doc)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		store.EventStore.replaceAll(doc.Items)
+		log.Printf("Loaded %d session events from %s", len(doc.Items), filePath)
+	}
+	return store, nil
+}
+
+// Append adds events to their logs once the file holding them has been written
+func (s *FileEventStore) Append(events []sessions.Event) ([]sessions.Event, error) {
+	return s.EventStore.append(events, s.write)
+}
+
+// DeleteBySession removes the event log of a session once the file without it has been written
+func (s *FileEventStore) DeleteBySession(sessionID string) (int, error) {
+	return s.EventStore.deleteBySession(sessionID, s.write)
+}
+
+// write saves logs to disk. It runs under the store lock, which keeps writes in order.
+func (s *FileEventStore) write(logs map[string][]sessions.Event) error {
+	return writeJSONFile(s.filePath, newListDocument(flattenLogs(logs)))
+}
+
+// eventStatuses returns the statuses an event moves an active session through, in order,
+// or nothing when the event does not change the status from the current one. A session
+// reported completed before it was reported started runs first.
+func eventStatuses(event sessions.Event, current string) []string {
+	switch event.Type {
+	case sessions.EventStarted:
+		if current != sessions.StatusRunning {
+			return []string{sessions.StatusRunning}
+		}
+	case sessions.EventPaused:
+		if current == sessions.StatusRunning {
+			return []string{sessions.StatusPaused}
+		}
+	case sessions.EventCompleted:
+		if current == sessions.StatusPending || current == sessions.StatusStarting {
+			return []string{sessions.StatusRunning, sessions.StatusCompleted}
+		}
+		return []string{sessions.StatusCompleted}
+	case sessions.EventCrashed:
+		return []string{sessions.StatusFailed}
+	}
+	return nil
+}
+
+// eventReason describes why an event changed the status of a session
+func eventReason(event sessions.Event) string {
+	if event.Reason != "" {
+		return fmt.Sprintf("%s event: %s", event.Type, event.Reason)
+	}
+	return event.Type + " event"
+}
+
+// recordMu serializes recording, so the events found to be new are still new when they
+// are appended
+var recordMu sync.Mutex
+
+// RecordSessionEvents appends events reported by a station to the log of a session and
+// applies the status changes they imply. Once a session has finished, further events are
+// still logged but no longer change it. It returns the events that were stored, without
+// the ones already received, and the session as it is afterwards.
+//
+// The score and status changes are applied before the events are appended. When applying
+// them fails nothing is logged, so the station's retry of the batch applies them again
+// instead of being skipped as a duplicate.
+func RecordSessionEvents(sessionStore SessionRepository, eventStore EventRepository, sessionID string, events []sessions.Event) ([]sessions.Event, *sessions.Session, error) {
+	session, err := sessionStore.GetByID(sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range events {
+		if !sessions.IsValidEventType(events[i].Type) {
+			return nil, nil, fmt.Errorf("%w: unknown type %q", ErrInvalidEvent, events[i].Type)
+		}
+		if events[i].Score != nil && events[i].Type != sessions.EventCompleted {
+			return nil, nil, fmt.Errorf("%w: only completed events carry a score", ErrInvalidEvent)
+		}
+		if len(events[i].Ratings) > 0 && events[i].Type != sessions.EventCompleted {
+			return nil, nil, fmt.Errorf("%w: only completed events carry ratings", ErrInvalidEvent)
+		}
+		if err := checkRatings(session.Configuration, events[i].Ratings); err != nil {
+			return nil, nil, fmt.Errorf("%w: %w", ErrInvalidEvent, err)
+		}
+		events[i].SessionID = sessionID
+	}
+
+	recordMu.Lock()
+	defer recordMu.Unlock()
+
+	fresh := newEvents(eventStore.ListBySession(sessionID), events)
+	if err := applyEvents(sessionStore, session, fresh); err != nil {
+		return nil, nil, err
+	}
+	added, err := eventStore.Append(fresh)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	session, err = sessionStore.GetByID(sessionID)
+	return added, session, err
+}
+
+// newEvents returns the events of batch whose ClientID is neither stored nor earlier in batch
+func newEvents(stored, batch []sessions.Event) []sessions.Event {
+	fresh := make([]sessions.Event, 0, len(batch))
+	for _, event := range batch {
+		if containsClientID(stored, event.ClientID) || containsClientID(fresh, event.ClientID) {
+			continue
+		}
+		fresh = append(fresh, event)
+	}
+	return fresh
+}
+
+// applyEvents sets the score and moves the status of session as events imply.
+// state follows the status through the events without reading the session back.
+func applyEvents(sessionStore SessionRepository, session *sessions.Session, events []sessions.Event) error {
+	state := *session
+	for _, event := range events {
+		if !state.IsActive() {
+			log.Printf("Session %s already %s; logged %s event without changing it", session.ID, state.Status, event.Type)
+			continue
+		}
+
+		if score, breakdown := sessionScore(state.Configuration, event); score != nil {
+			if err := sessionStore.SetScore(session.ID, *score, breakdown); err != nil {
+				return err
+			}
+		}
+		for _, next := range eventStatuses(event, state.Status) {
+			updated, err := sessionStore.Transition(session.ID, sessions.Transition{
+				To:     next,
+				Actor:  sessions.ActorStation,
+				Reason: eventReason(event),
+			})
+			if errors.Is(err, ErrIllegalTransition) {
+				log.Printf("Session %s: ignoring %s event: %v", session.ID, event.Type, err)
+				break
+			}
+			if err != nil {
+				return err
+			}
+			log.Printf("Session %s moved from %s to %s by a %s event", session.ID, state.Status, next, event.Type)
+			state = *updated
+		}
+	}
+	return nil
+}
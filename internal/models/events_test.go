@@ -0,0 +1,162 @@
+// internal/models/events_test.go
+package models
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+)
+
+// repairDataDir undoes breakDataDir
+func repairDataDir(t *testing.T, path string) {
+	t.Helper()
+	dir := filepath.Dir(path)
+	if err := os.Remove(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileEventStoreAppendFailureKeepsBatchRetryable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "session_events.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewFileEventStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := []sessions.Event{{SessionID: "session_1", ClientID: "c1", Type: sessions.EventStarted}}
+
+	breakDataDir(t, path)
+	if _, err := store.Append(batch); err == nil {
+		t.Fatal("Append succeeded although the file could not be written")
+	}
+	if got := store.ListBySession("session_1"); len(got) != 0 {
+		t.Fatalf("a failed append left %d events in memory, want 0", len(got))
+	}
+
+	// The station retries the same batch once the disk is back
+	repairDataDir(t, path)
+	added, err := store.Append(batch)
+	if err != nil {
+		t.Fatalf("retried Append: %v", err)
+	}
+	if len(added) != 1 {
+		t.Errorf("the retried batch stored %d events, want 1", len(added))
+	}
+
+	reloaded, err := NewFileEventStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reloaded.ListBySession("session_1"); len(got) != 1 {
+		t.Errorf("reloaded %d events, want 1", len(got))
+	}
+}
+
+func TestFileEventStoreDeleteFailureKeepsLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "session_events.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewFileEventStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Append([]sessions.Event{{SessionID: "session_1", Type: sessions.EventStarted}}); err != nil {
+		t.Fatal(err)
+	}
+
+	breakDataDir(t, path)
+	if _, err := store.DeleteBySession("session_1"); err == nil {
+		t.Fatal("DeleteBySession succeeded although the file could not be written")
+	}
+	if got := store.ListBySession("session_1"); len(got) != 1 {
+		t.Errorf("a failed delete left %d events in memory, want 1", len(got))
+	}
+}
+
+func TestEventStoreAppendDoesNotChangeEarlierResults(t *testing.T) {
+	store := NewEventStore()
+	if _, err := store.Append([]sessions.Event{{SessionID: "session_1", Type: sessions.EventStarted}}); err != nil {
+		t.Fatal(err)
+	}
+	before := store.ListBySession("session_1")
+	if _, err := store.Append([]sessions.Event{{SessionID: "session_1", Type: sessions.EventPaused}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(before) != 1 || before[0].Type != sessions.EventStarted {
+		t.Errorf("an earlier result changed to %+v", before)
+	}
+	if got := store.ListBySession("session_1"); len(got) != 2 {
+		t.Errorf("log holds %d events, want 2", len(got))
+	}
+}
+
+// flakySessions fails the next transitions to status failTo
+type flakySessions struct {
+	SessionRepository
+	failTo string
+}
+
+func (s *flakySessions) Transition(id string, t sessions.Transition) (*sessions.Session, error) {
+	if t.To == s.failTo {
+		s.failTo = ""
+		return nil, errors.New("disk full")
+	}
+	return s.SessionRepository.Transition(id, t)
+}
+
+func TestRecordSessionEventsRetryAfterFailedTransition(t *testing.T) {
+	store := NewSessionStore(filepath.Join(t.TempDir(), "sessions.json"))
+	session, err := store.Create(sessions.Session{Configuration: &sessions.Configuration{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Transition(session.ID, sessions.Transition{To: sessions.StatusStarting, Actor: sessions.ActorSystem}); err != nil {
+		t.Fatal(err)
+	}
+	sessionStore := &flakySessions{SessionRepository: store, failTo: sessions.StatusCompleted}
+	eventStore := NewEventStore()
+	score := 80
+	batch := func() []sessions.Event {
+		return []sessions.Event{
+			{ClientID: "c1", Type: sessions.EventStarted},
+			{ClientID: "c2", Type: sessions.EventCompleted, Score: &score},
+		}
+	}
+
+	if _, _, err := RecordSessionEvents(sessionStore, eventStore, session.ID, batch()); err == nil {
+		t.Fatal("RecordSessionEvents succeeded although the transition failed")
+	}
+	if got := eventStore.ListBySession(session.ID); len(got) != 0 {
+		t.Fatalf("a failed batch logged %d events, want 0", len(got))
+	}
+
+	// The station retries the same batch
+	added, updated, err := RecordSessionEvents(sessionStore, eventStore, session.ID, batch())
+	if err != nil {
+		t.Fatalf("retried batch: %v", err)
+	}
+	if len(added) != 2 {
+		t.Errorf("the retried batch logged %d events, want 2", len(added))
+	}
+	if updated.Status != sessions.StatusCompleted {
+		t.Errorf("session is %s after the retry, want %s", updated.Status, sessions.StatusCompleted)
+	}
+	if updated.Score == nil || *updated.Score != score {
+		t.Errorf("session score = %v after the retry, want %d", updated.Score, score)
+	}
+
+	// A further retry changes nothing
+	added, _, err = RecordSessionEvents(sessionStore, eventStore, session.ID, batch())
+	if err != nil || len(added) != 0 {
+		t.Errorf("a duplicate batch logged %d events (err %v), want none", len(added), err)
+	}
+}
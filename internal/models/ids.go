@@ -0,0 +1,32 @@
+// internal/models/ids.go
+package models
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Timestamp based IDs are unique per second; repeated IDs within a second get a suffix
+var (
+	idMu     sync.Mutex
+	lastIDs  = make(map[string]string)
+	idCounts = make(map[string]int)
+)
+
+// newTimestampID returns prefix followed by the current time, with a numeric suffix
+// when an ID with the same prefix was already generated within the same second
+func newTimestampID(prefix string) string {
+	id := prefix + time.Now().Format("20060102150405")
+
+	idMu.Lock()
+	defer idMu.Unlock()
+
+	if lastIDs[prefix] != id {
+		lastIDs[prefix] = id
+		idCounts[prefix] = 0
+		return id
+	}
+	idCounts[prefix]++
+	return fmt.Sprintf("%s_%d", id, idCounts[prefix])
+}
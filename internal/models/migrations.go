@@ -0,0 +1,379 @@
+// internal/models/migrations.go
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/saladinomario/vr-training-admin/internal/config"
+)
+
+// CurrentSchemaVersion is the version written into every persisted JSON document
+const CurrentSchemaVersion = 3
+
+// Persisted JSON document kinds
+const (
+	DocumentScenarios         = "scenarios"
+	DocumentScenarioRevisions = "scenario_revisions"
+	DocumentAvatars           = "avatars"
+	DocumentObservers         = "observers"
+	DocumentSessions          = "sessions"
+	DocumentSettings          = "settings"
+	DocumentOutbox            = "ue_outbox"
+	DocumentSessionEvents     = "session_events"
+	DocumentTrainees          = "trainees"
+)
+
+// documentFiles maps each document kind to its file name in the data directory
+var documentFiles = map[string]string{
+	DocumentScenarios:         "scenarios.json",
+	DocumentScenarioRevisions: "scenario_revisions.json",
+	DocumentAvatars:           "avatars.json",
+	DocumentObservers:         "observers.json",
+	DocumentSessions:          "sessions.json",
+	DocumentSettings:          "settings.json",
+	DocumentOutbox:            "ue_outbox.json",
+	DocumentSessionEvents:     "session_events.json",
+	DocumentTrainees:          "trainees.json",
+}
+
+// listDocument is the on-disk envelope for documents holding a list of items
+type listDocument[T any] struct {
+	Version int `json:"version"`
+	Items   []T `json:"items"`
+}
+
+// newListDocument wraps items in an envelope stamped with the current schema version
+func newListDocument[T any](items []T) listDocument[T] {
+	return listDocument[T]{Version: CurrentSchemaVersion, Items: items}
+}
+
+// DocumentMigration upgrades a JSON document of one kind to Version.
+// Migrations work on the generic JSON tree rather than on the Go types, so they keep
+// working when the types evolve later. Apply returns a description of every change.
+type DocumentMigration struct {
+	Kind        string
+	Version     int
+	Description string
+	Apply       func(doc map[string]interface{}) ([]string, error)
+}
+
+// documentMigrations is the registry of JSON document migrations.
+// Version 1 is the unversioned layout written before schema versioning existed.
+var documentMigrations = []DocumentMigration{
+	{
+		Kind:        DocumentSettings,
+		Version:     2,
+		Description: "rename settings fields from Go names to camelCase",
+		Apply: func(doc map[string]interface{}) ([]string, error) {
+			changes := renameFields(doc, "llm", llmSettingsRenames)
+			return append(changes, renameFields(doc, "general", generalSettingsRenames)...), nil
+		},
+	},
+	{
+		Kind:        DocumentScenarios,
+		Version:     2,
+		Description: "rename scenario fields from Go names to camelCase",
+		Apply:       renameItemFields(scenarioRenames),
+	},
+	{
+		Kind:        DocumentAvatars,
+		Version:     2,
+		Description: "rename avatar fields from Go names to camelCase",
+		Apply:       renameItemFields(avatarRenames),
+	},
+	{
+		Kind:        DocumentObservers,
+		Version:     2,
+		Description: "rename observer fields from Go names to camelCase",
+		Apply:       renameItemFields(observerRenames),
+	},
+	{
+		Kind:        DocumentSessions,
+		Version:     2,
+		Description: "wrap sessions in a versioned document",
+		Apply: func(doc map[string]interface{}) ([]string, error) {
+			return nil, nil
+		},
+	},
+	{
+		Kind:        DocumentSettings,
+		Version:     3,
+		Description: "move the Unreal Engine endpoint into a list of VR stations",
+		Apply: func(doc map[string]interface{}) ([]string, error) {
+			if _, ok := doc["stations"]; ok {
+				return nil, nil
+			}
+			general, _ := doc["general"].(map[string]interface{})
+			if general == nil {
+				general = map[string]interface{}{}
+			}
+			stations, changes := stationsFromGeneral(general)
+			doc["stations"] = stations
+			return changes, nil
+		},
+	},
+}
+
+// stationsFromGeneral builds the initial station list from the general settings object,
+// removing the single endpoint setting that stations replace
+func stationsFromGeneral(general map[string]interface{}) ([]map[string]interface{}, []string) {
+	endpoint, _ := general["unrealEndpoint"].(string)
+	delete(general, "unrealEndpoint")
+
+	changes := []string{"added the default VR station"}
+	if endpoint == "" {
+		endpoint = "http://localhost:8081/api/vr-session"
+	} else {
+		changes = []string{"general.unrealEndpoint -> stations[0].endpoint"}
+	}
+	return []map[string]interface{}{{
+		"id": "station_default", "name": "Station 1", "endpoint": endpoint, "authToken": "", "enabled": true,
+	}}, changes
+}
+
+// Field renames applied by the version 2 migrations. These are frozen copies of the
+// names at the time of the migration and must not follow later changes to the types.
+var (
+	llmSettingsRenames = map[string]string{
+		"ID": "id", "Provider": "provider", "APIKey": "apiKey", "Model": "model",
+		"MaxTokens": "maxTokens", "Temperature": "temperature", "TopP": "topP",
+		"FrequencyPenalty": "frequencyPenalty", "PresencePenalty": "presencePenalty",
+		"ProjectID": "projectId", "Location": "location", "Endpoint": "endpoint",
+		"ServiceAccountKey": "serviceAccountKey",
+	}
+	generalSettingsRenames = map[string]string{
+		"ApplicationName": "applicationName", "LogLevel": "logLevel",
+		"MaxConcurrentSessions": "maxConcurrentSessions", "SessionTimeout": "sessionTimeout",
+		"RecordSessions": "recordSessions", "StoreSessionData": "storeSessionData",
+		"DataRetentionDays": "dataRetentionDays",
+	}
+	scenarioRenames = map[string]string{
+		"ID": "id", "Name": "name", "Description": "description", "Category": "category",
+		"Difficulty": "difficulty", "Duration": "duration", "Scene": "scene",
+		"BackgroundNoise": "backgroundNoise", "SuccessCriteria": "successCriteria",
+		"Keywords": "keywords",
+	}
+	avatarRenames = map[string]string{
+		"ID": "id", "Name": "name", "Description": "description",
+		"PersonalityType": "personalityType", "CommunicationStyle": "communicationStyle",
+		"KnowledgeLevel": "knowledgeLevel", "AggressivenessLevel": "aggressivenessLevel",
+		"PatienceLevel": "patienceLevel", "EmotionalReactivity": "emotionalReactivity",
+		"VoiceType": "voiceType", "SpeakingSpeed": "speakingSpeed", "ImageURL": "imageUrl",
+		"Keywords": "keywords",
+	}
+	observerRenames = map[string]string{
+		"ID": "id", "Name": "name", "Description": "description", "FeedbackStyle": "feedbackStyle",
+		"InterventionLevel": "interventionLevel", "DetailLevel": "detailLevel",
+		"FeedbackTone": "feedbackTone", "SuccessMetrics": "successMetrics",
+		"InterventionTriggers": "interventionTriggers", "Active": "active",
+	}
+)
+
+// MigrationReport describes the migrations applied, or pending in dry-run mode, for one target
+type MigrationReport struct {
+	Target      string
+	FromVersion int
+	ToVersion   int
+	Changes     []string
+}
+
+// Pending reports whether the target needs to be upgraded
+func (r MigrationReport) Pending() bool {
+	return r.FromVersion < r.ToVersion
+}
+
+// MigrateDataDir upgrades every persisted document of the configured backend.
+// With dryRun set nothing is written and the reports describe what would change.
+func MigrateDataDir(cfg config.Config, dryRun bool) ([]MigrationReport, error) {
+	if cfg.Storage == config.StorageSQLite {
+		report, err := migrateSQLiteFile(filepath.Join(cfg.DataDir, "admin.db"), dryRun)
+		if err != nil {
+			return nil, err
+		}
+		return []MigrationReport{report}, nil
+	}
+
+	kinds := []string{DocumentSessions, DocumentSettings, DocumentOutbox}
+	if cfg.Storage == config.StorageFile {
+		kinds = append(kinds, DocumentScenarios, DocumentScenarioRevisions, DocumentAvatars, DocumentObservers, DocumentSessionEvents, DocumentTrainees)
+	}
+
+	reports := make([]MigrationReport, 0, len(kinds))
+	for _, kind := range kinds {
+		report, err := migrateFile(kind, filepath.Join(cfg.DataDir, documentFiles[kind]), dryRun)
+		if err != nil {
+			return reports, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// migrateFile upgrades the document of kind stored at path. A missing file is not an error.
+// Before an upgrade is written the original is kept next to it with its version as suffix.
+func migrateFile(kind, path string, dryRun bool) (MigrationReport, error) {
+	report := MigrationReport{Target: path, FromVersion: CurrentSchemaVersion, ToVersion: CurrentSchemaVersion}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return report, nil
+	}
+	if err != nil {
+		return report, err
+	}
+
+	doc, report, err := upgradeDocument(kind, data)
+	report.Target = path
+	if err != nil {
+		return report, fmt.Errorf("migrating %s: %w", path, err)
+	}
+	if !report.Pending() || dryRun {
+		return report, nil
+	}
+
+	original := fmt.Sprintf("%s.v%d", path, report.FromVersion)
+	if err := os.WriteFile(original, data, 0644); err != nil {
+		return report, fmt.Errorf("keeping original of %s: %w", path, err)
+	}
+
+	upgraded, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return report, err
+	}
+	if err := writeFileAtomic(path, upgraded, 0644); err != nil {
+		return report, err
+	}
+
+	log.Printf("Migrated %s from version %d to %d (original kept as %s)",
+		path, report.FromVersion, report.ToVersion, original)
+	return report, nil
+}
+
+// upgradeDocument applies every pending migration of kind to the raw JSON document
+func upgradeDocument(kind string, data []byte) (map[string]interface{}, MigrationReport, error) {
+	report := MigrationReport{ToVersion: CurrentSchemaVersion}
+
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, report, err
+	}
+
+	// Unversioned documents are version 1; list documents used to be bare arrays
+	var doc map[string]interface{}
+	switch v := raw.(type) {
+	case []interface{}:
+		doc = map[string]interface{}{"version": float64(1), "items": v}
+	case map[string]interface{}:
+		doc = v
+		if _, ok := doc["version"]; !ok {
+			doc["version"] = float64(1)
+		}
+	case nil:
+		doc = map[string]interface{}{"version": float64(1), "items": []interface{}{}}
+	default:
+		return nil, report, fmt.Errorf("unexpected document type %T", raw)
+	}
+
+	version, ok := doc["version"].(float64)
+	if !ok {
+		return nil, report, fmt.Errorf("invalid version %v", doc["version"])
+	}
+	report.FromVersion = int(version)
+	if report.FromVersion > CurrentSchemaVersion {
+		return nil, report, fmt.Errorf("document version %d is newer than supported version %d",
+			report.FromVersion, CurrentSchemaVersion)
+	}
+
+	for _, m := range migrationsFor(kind, report.FromVersion) {
+		changes, err := m.Apply(doc)
+		if err != nil {
+			return nil, report, fmt.Errorf("migration to version %d: %w", m.Version, err)
+		}
+		doc["version"] = float64(m.Version)
+		report.Changes = append(report.Changes, fmt.Sprintf("v%d: %s", m.Version, m.Description))
+		for _, change := range changes {
+			report.Changes = append(report.Changes, "  "+change)
+		}
+	}
+
+	// Kinds without a migration for the latest versions are unchanged by them
+	doc["version"] = float64(CurrentSchemaVersion)
+	return doc, report, nil
+}
+
+// migrationsFor returns the migrations of kind newer than version, in order
+func migrationsFor(kind string, version int) []DocumentMigration {
+	result := make([]DocumentMigration, 0)
+	for _, m := range documentMigrations {
+		if m.Kind == kind && m.Version > version {
+			result = append(result, m)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+	return result
+}
+
+// renameItemFields builds a migration that renames fields in every element of "items"
+func renameItemFields(renames map[string]string) func(doc map[string]interface{}) ([]string, error) {
+	return func(doc map[string]interface{}) ([]string, error) {
+		items, _ := doc["items"].([]interface{})
+		renamed := 0
+		for _, item := range items {
+			obj, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("unexpected item type %T", item)
+			}
+			renamed += len(renameKeys(obj, renames))
+		}
+		if renamed == 0 {
+			return nil, nil
+		}
+		return []string{fmt.Sprintf("renamed %d fields across %d items", renamed, len(items))}, nil
+	}
+}
+
+// renameFields renames the keys of the object stored under key in doc
+func renameFields(doc map[string]interface{}, key string, renames map[string]string) []string {
+	obj, ok := doc[key].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	changes := make([]string, 0)
+	for _, change := range renameKeys(obj, renames) {
+		changes = append(changes, key+"."+change)
+	}
+	return changes
+}
+
+// renameKeys renames keys of obj in place and describes each rename.
+// A key that already exists under its new name is left alone.
+func renameKeys(obj map[string]interface{}, renames map[string]string) []string {
+	changes := make([]string, 0)
+	for oldKey, newKey := range renames {
+		value, ok := obj[oldKey]
+		if !ok {
+			continue
+		}
+		delete(obj, oldKey)
+		if _, exists := obj[newKey]; !exists {
+			obj[newKey] = value
+		}
+		changes = append(changes, oldKey+" -> "+newKey)
+	}
+	sort.Strings(changes)
+	return changes
+}
+
+// readDocument migrates the document of kind at path if needed and decodes it into v.
+// It reports whether the file existed.
+func readDocument(kind, path string, v interface{}) (bool, error) {
+	if _, err := migrateFile(kind, path, false); err != nil {
+		return true, err
+	}
+	return readJSONFile(path, v)
+}
@@ -0,0 +1,267 @@
+// internal/models/migrations_test.go
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/saladinomario/vr-training-admin/internal/config"
+)
+
+// decodeJSON decodes a JSON fixture into the generic tree the migrations work on
+func decodeJSON(t *testing.T, data string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatalf("fixture %s: %v", data, err)
+	}
+	return v
+}
+
+func TestUpgradeDocument(t *testing.T) {
+	defaultStation := `[{"id": "station_default", "name": "Station 1", "endpoint": "http://localhost:8081/api/vr-session", "authToken": "", "enabled": true}]`
+
+	tests := []struct {
+		name        string
+		kind        string
+		fixture     string
+		want        string // the upgraded document
+		fromVersion int
+		changes     []string // lines the report must contain
+	}{
+		{
+			name:        "version 1 settings",
+			kind:        DocumentSettings,
+			fixture:     `{"llm": {"APIKey": "sk-1", "Model": "gpt-4", "TopP": 0.9}, "general": {"ApplicationName": "Academy", "SessionTimeout": 45}}`,
+			want:        `{"version": 3, "llm": {"apiKey": "sk-1", "model": "gpt-4", "topP": 0.9}, "general": {"applicationName": "Academy", "sessionTimeout": 45}, "stations": ` + defaultStation + `}`,
+			fromVersion: 1,
+			changes:     []string{"  llm.APIKey -> apiKey", "  general.SessionTimeout -> sessionTimeout", "  added the default VR station"},
+		},
+		{
+			name:        "version 2 settings with an Unreal Engine endpoint",
+			kind:        DocumentSettings,
+			fixture:     `{"version": 2, "llm": {}, "general": {"applicationName": "Academy", "unrealEndpoint": "http://bay1:9000/api"}}`,
+			want:        `{"version": 3, "llm": {}, "general": {"applicationName": "Academy"}, "stations": [{"id": "station_default", "name": "Station 1", "endpoint": "http://bay1:9000/api", "authToken": "", "enabled": true}]}`,
+			fromVersion: 2,
+			changes:     []string{"  general.unrealEndpoint -> stations[0].endpoint"},
+		},
+		{
+			name:        "version 2 settings without general settings",
+			kind:        DocumentSettings,
+			fixture:     `{"version": 2, "llm": {}}`,
+			want:        `{"version": 3, "llm": {}, "stations": ` + defaultStation + `}`,
+			fromVersion: 2,
+			changes:     []string{"  added the default VR station"},
+		},
+		{
+			name:        "settings that already have stations",
+			kind:        DocumentSettings,
+			fixture:     `{"version": 2, "general": {"unrealEndpoint": "http://bay1:9000/api"}, "stations": []}`,
+			want:        `{"version": 3, "general": {"unrealEndpoint": "http://bay1:9000/api"}, "stations": []}`,
+			fromVersion: 2,
+		},
+		{
+			name:        "renamed field already present under its new name",
+			kind:        DocumentSettings,
+			fixture:     `{"llm": {"APIKey": "old", "apiKey": "new"}, "general": {}, "stations": []}`,
+			want:        `{"version": 3, "llm": {"apiKey": "new"}, "general": {}, "stations": []}`,
+			fromVersion: 1,
+			changes:     []string{"  llm.APIKey -> apiKey"},
+		},
+		{
+			name:        "version 1 scenarios",
+			kind:        DocumentScenarios,
+			fixture:     `[{"ID": "scenario_1", "Name": "Fire drill", "BackgroundNoise": true, "SuccessCriteria": "Clear Communication"}, {"ID": "scenario_2", "Name": "Queue"}]`,
+			want:        `{"version": 3, "items": [{"id": "scenario_1", "name": "Fire drill", "backgroundNoise": true, "successCriteria": "Clear Communication"}, {"id": "scenario_2", "name": "Queue"}]}`,
+			fromVersion: 1,
+			changes:     []string{"  renamed 6 fields across 2 items"},
+		},
+		{
+			name:        "version 1 avatars",
+			kind:        DocumentAvatars,
+			fixture:     `[{"ID": "avatar_1", "Name": "Alex", "PatienceLevel": 3, "ImageURL": "/a.png"}]`,
+			want:        `{"version": 3, "items": [{"id": "avatar_1", "name": "Alex", "patienceLevel": 3, "imageUrl": "/a.png"}]}`,
+			fromVersion: 1,
+			changes:     []string{"  renamed 4 fields across 1 items"},
+		},
+		{
+			name:        "version 1 observers",
+			kind:        DocumentObservers,
+			fixture:     `[{"ID": "observer_1", "Name": "Coach", "InterventionTriggers": ["silence"], "Active": true}]`,
+			want:        `{"version": 3, "items": [{"id": "observer_1", "name": "Coach", "interventionTriggers": ["silence"], "active": true}]}`,
+			fromVersion: 1,
+			changes:     []string{"  renamed 4 fields across 1 items"},
+		},
+		{
+			name:        "version 1 sessions",
+			kind:        DocumentSessions,
+			fixture:     `[{"id": "session_1", "status": "completed"}]`,
+			want:        `{"version": 3, "items": [{"id": "session_1", "status": "completed"}]}`,
+			fromVersion: 1,
+			changes:     []string{"v2: wrap sessions in a versioned document"},
+		},
+		{
+			name:        "empty version 1 document",
+			kind:        DocumentSessions,
+			fixture:     `null`,
+			want:        `{"version": 3, "items": []}`,
+			fromVersion: 1,
+		},
+		{
+			name:        "kind without migrations",
+			kind:        DocumentOutbox,
+			fixture:     `[{"id": "delivery_1"}]`,
+			want:        `{"version": 3, "items": [{"id": "delivery_1"}]}`,
+			fromVersion: 1,
+		},
+		{
+			name:        "current version",
+			kind:        DocumentScenarios,
+			fixture:     `{"version": 3, "items": [{"ID": "left alone"}]}`,
+			want:        `{"version": 3, "items": [{"ID": "left alone"}]}`,
+			fromVersion: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, report, err := upgradeDocument(tt.kind, []byte(tt.fixture))
+			if err != nil {
+				t.Fatalf("upgradeDocument: %v", err)
+			}
+			if report.FromVersion != tt.fromVersion || report.ToVersion != CurrentSchemaVersion {
+				t.Errorf("report goes from version %d to %d, want %d to %d", report.FromVersion, report.ToVersion, tt.fromVersion, CurrentSchemaVersion)
+			}
+			if got := decodeJSON(t, mustMarshal(t, doc)); !reflect.DeepEqual(got, decodeJSON(t, tt.want)) {
+				t.Errorf("upgraded document = %s\nwant %s", mustMarshal(t, doc), tt.want)
+			}
+			for _, change := range tt.changes {
+				if !containsString(report.Changes, change) {
+					t.Errorf("report %q lacks %q", report.Changes, change)
+				}
+			}
+		})
+	}
+}
+
+func TestUpgradeDocumentRejectsUnknownVersions(t *testing.T) {
+	tests := map[string]string{
+		"newer version":   `{"version": 4, "items": []}`,
+		"invalid version": `{"version": "two", "items": []}`,
+		"not a document":  `"sessions"`,
+		"not JSON":        `{"version": 1,`,
+	}
+	for name, fixture := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := upgradeDocument(DocumentSessions, []byte(fixture)); err == nil {
+				t.Errorf("upgradeDocument(%s) succeeded", fixture)
+			}
+		})
+	}
+}
+
+func TestMigrateFileKeepsOriginal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), documentFiles[DocumentScenarios])
+	original := []byte(`[{"ID": "scenario_1", "Name": "Fire drill"}]`)
+	if err := os.WriteFile(path, original, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := migrateFile(DocumentScenarios, path, false)
+	if err != nil {
+		t.Fatalf("migrateFile: %v", err)
+	}
+	if !report.Pending() || report.Target != path {
+		t.Errorf("report = %+v, want an upgrade of %s", report, path)
+	}
+	if kept, err := os.ReadFile(path + ".v1"); err != nil || !bytes.Equal(kept, original) {
+		t.Errorf("original kept as %s = %q, %v, want the version 1 file", path+".v1", kept, err)
+	}
+
+	var doc listDocument[map[string]interface{}]
+	if _, err := readJSONFile(path, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Version != CurrentSchemaVersion || len(doc.Items) != 1 || doc.Items[0]["name"] != "Fire drill" {
+		t.Errorf("migrated file = %+v, want the renamed scenario at version %d", doc, CurrentSchemaVersion)
+	}
+
+	// An upgraded file is left alone
+	if report, err := migrateFile(DocumentScenarios, path, false); err != nil || report.Pending() {
+		t.Errorf("second migrateFile = %+v, %v, want nothing pending", report, err)
+	}
+	if report, err := migrateFile(DocumentScenarios, filepath.Join(t.TempDir(), "missing.json"), false); err != nil || report.Pending() {
+		t.Errorf("migrateFile of a missing file = %+v, %v, want nothing pending", report, err)
+	}
+}
+
+func TestMigrateDataDirDryRunWritesNothing(t *testing.T) {
+	dir := t.TempDir()
+	fixtures := map[string]string{
+		DocumentSettings:  `{"llm": {"APIKey": "sk-1"}, "general": {"ApplicationName": "Academy"}}`,
+		DocumentScenarios: `[{"ID": "scenario_1", "Name": "Fire drill"}]`,
+		DocumentAvatars:   `[{"ID": "avatar_1", "Name": "Alex"}]`,
+		DocumentObservers: `[{"ID": "observer_1", "Name": "Coach"}]`,
+		DocumentSessions:  `[]`,
+	}
+	for kind, fixture := range fixtures {
+		if err := os.WriteFile(filepath.Join(dir, documentFiles[kind]), []byte(fixture), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reports, err := MigrateDataDir(config.Config{DataDir: dir, Storage: config.StorageFile}, true)
+	if err != nil {
+		t.Fatalf("MigrateDataDir: %v", err)
+	}
+	pending := 0
+	for _, report := range reports {
+		if report.Pending() {
+			pending++
+		}
+	}
+	if pending != len(fixtures) {
+		t.Errorf("%d documents would be migrated, want %d", pending, len(fixtures))
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(fixtures) {
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		t.Errorf("data directory holds %s after a dry run, want only the fixtures", strings.Join(names, ", "))
+	}
+	for kind, fixture := range fixtures {
+		data, err := os.ReadFile(filepath.Join(dir, documentFiles[kind]))
+		if err != nil || string(data) != fixture {
+			t.Errorf("%s = %q, %v after a dry run, want it unchanged", kind, data, err)
+		}
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -3,9 +3,9 @@ package models
 
 import (
 	"errors"
+	"log"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/saladinomario/vr-training-admin/templates/components/observers"
 )
@@ -13,6 +13,7 @@ import (
 var (
 	ErrObserverNotFound = errors.New("observer not found")
 	ErrInvalidObserver  = errors.New("invalid observer data")
+	ErrObserverExists   = errors.New("observer already exists")
 )
 
 // ObserverStore implements an in-memory storage for observers
@@ -46,6 +47,11 @@ func NewObserverStore() *ObserverStore {
 				"Policy misinterpretation",
 			},
 			Active: true,
+			Rubric: []observers.RubricItem{
+				{ID: "rubric_1_procedure", Name: "Procedure adherence", Description: "Follows the service procedure step by step", Weight: 3, Min: 1, Max: 5},
+				{ID: "rubric_1_documentation", Name: "Documentation accuracy", Description: "Checks and records documents correctly", Weight: 2, Min: 1, Max: 5},
+				{ID: "rubric_1_clarity", Name: "Clarity of requirements", Description: "Explains what the citizen needs to provide", Weight: 2, Min: 1, Max: 5},
+			},
 		},
 		{
 			ID:                "2",
@@ -63,6 +69,12 @@ func NewObserverStore() *ObserverStore {
 				"Proper referral needed",
 			},
 			Active: true,
+			Rubric: []observers.RubricItem{
+				{ID: "rubric_2_empathy", Name: "Empathy", Description: "Acknowledges the citizen's situation and feelings", Weight: 2, Min: 1, Max: 5},
+				{ID: "rubric_2_plain_language", Name: "Plain language", Description: "Avoids jargon and checks understanding", Weight: 2, Min: 1, Max: 5},
+				{ID: "rubric_2_deescalation", Name: "De-escalation", Description: "Calms tense moments before they escalate", Weight: 3, Min: 0, Max: 10},
+				{ID: "rubric_2_referral", Name: "Referral made", Description: "Refers the citizen to the right service", Weight: 1, Min: 0, Max: 1},
+			},
 		},
 	}
 
@@ -106,6 +118,9 @@ func (s *ObserverStore) Create(observer observers.Observer) (observers.Observer,
 	if observer.Name == "" {
 		return observers.Observer{}, ErrInvalidObserver
 	}
+	if err := prepareRubric(&observer); err != nil {
+		return observers.Observer{}, err
+	}
 
 	// Generate a simple ID based on timestamp
 	observer.ID = generateObserverID()
@@ -113,12 +128,31 @@ func (s *ObserverStore) Create(observer observers.Observer) (observers.Observer,
 	return observer, nil
 }
 
+// Insert adds an observer under its own ID, as done when importing content
+func (s *ObserverStore) Insert(observer observers.Observer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if observer.ID == "" || observer.Name == "" {
+		return ErrInvalidObserver
+	}
+	if err := prepareRubric(&observer); err != nil {
+		return err
+	}
+	if _, ok := s.observers[observer.ID]; ok {
+		return ErrObserverExists
+	}
+	s.observers[observer.ID] = observer
+	return nil
+}
+
 // Update modifies an existing observer
 func (s *ObserverStore) Update(id string, observer observers.Observer) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, ok := s.observers[id]; !ok {
+	existing, ok := s.observers[id]
+	if !ok {
 		return ErrObserverNotFound
 	}
 
@@ -126,9 +160,27 @@ func (s *ObserverStore) Update(id string, observer observers.Observer) error {
 	if observer.Name == "" {
 		return ErrInvalidObserver
 	}
+	if err := prepareRubric(&observer); err != nil {
+		return err
+	}
 
-	// Preserve the ID
+	// Preserve the ID and archive state
 	observer.ID = id
+	observer.Archived = existing.Archived
+	s.observers[id] = observer
+	return nil
+}
+
+// SetArchived archives an observer, or brings it back
+func (s *ObserverStore) SetArchived(id string, archived bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	observer, ok := s.observers[id]
+	if !ok {
+		return ErrObserverNotFound
+	}
+	observer.Archived = archived
 	s.observers[id] = observer
 	return nil
 }
@@ -169,7 +221,104 @@ func (s *ObserverStore) Search(query string) []observers.Observer {
 	return result
 }
 
+// replaceAll swaps the whole content of the store
+func (s *ObserverStore) replaceAll(list []observers.Observer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.observers = make(map[string]observers.Observer, len(list))
+	for _, observer := range list {
+		s.observers[observer.ID] = observer
+	}
+	return nil
+}
+
 // Helper to generate a simple ID
 func generateObserverID() string {
-	return "observer_" + time.Now().Format("20060102150405")
+	return newTimestampID("observer_")
+}
+
+// FileObserverStore persists observers to a JSON file on top of the in-memory store
+type FileObserverStore struct {
+	*ObserverStore
+	filePath string
+	writeMu  sync.Mutex
+}
+
+// NewFileObserverStore loads observers from filePath, seeding it with the sample data when the file does not exist yet
+func NewFileObserverStore(filePath string) (*FileObserverStore, error) {
+	store := &FileObserverStore{
+		ObserverStore: NewObserverStore(),
+		filePath:      filePath,
+	}
+
+	var doc listDocument[observers.Observer]
+	found, err := readDocument(DocumentObservers, filePath, &doc)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return store, store.save()
+	}
+	list := doc.Items
+
+	store.ObserverStore.replaceAll(list)
+	log.Printf("Loaded %d observers from %s", len(list), filePath)
+
+	return store, nil
+}
+
+// Create adds a new observer and persists the store
+func (s *FileObserverStore) Create(observer observers.Observer) (observers.Observer, error) {
+	var created observers.Observer
+	err := s.change(func() (err error) {
+		created, err = s.ObserverStore.Create(observer)
+		return err
+	})
+	return created, err
+}
+
+// Insert adds an observer under its own ID and persists the store
+func (s *FileObserverStore) Insert(observer observers.Observer) error {
+	return s.change(func() error {
+		return s.ObserverStore.Insert(observer)
+	})
+}
+
+// Update modifies an existing observer and persists the store
+func (s *FileObserverStore) Update(id string, observer observers.Observer) error {
+	return s.change(func() error {
+		return s.ObserverStore.Update(id, observer)
+	})
+}
+
+// Delete removes an observer and persists the store
+func (s *FileObserverStore) Delete(id string) error {
+	return s.change(func() error {
+		return s.ObserverStore.Delete(id)
+	})
+}
+
+// SetArchived archives an observer, or brings it back, and persists the store
+func (s *FileObserverStore) SetArchived(id string, archived bool) error {
+	return s.change(func() error {
+		return s.ObserverStore.SetArchived(id, archived)
+	})
+}
+
+// change applies a change to the store and persists it, restoring the previous content
+// when the file cannot be written
+func (s *FileObserverStore) change(apply func() error) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	return changeListFile(s.filePath, s.ObserverStore.GetAll, s.ObserverStore.replaceAll, apply)
+}
+
+// save writes a snapshot of the store to disk
+func (s *FileObserverStore) save() error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	return writeJSONFile(s.filePath, newListDocument(s.GetAll()))
 }
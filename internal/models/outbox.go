@@ -0,0 +1,174 @@
+// internal/models/outbox.go
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrDeliveryNotFound = errors.New("delivery not found")
+
+// Delivery is a payload for the Unreal Engine that has not been accepted yet.
+// Deliveries stay in the outbox until they are delivered or given up, so they survive restarts.
+type Delivery struct {
+	ID          string          `json:"id"`
+	SessionID   string          `json:"sessionId"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt time.Time       `json:"nextAttempt"`
+	LastError   string          `json:"lastError,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+}
+
+// OutboxStore keeps pending deliveries in memory
+type OutboxStore struct {
+	deliveries map[string]Delivery
+	mu         sync.RWMutex
+}
+
+// NewOutboxStore creates an empty outbox
+func NewOutboxStore() *OutboxStore {
+	return &OutboxStore{deliveries: make(map[string]Delivery)}
+}
+
+// GetAll returns the pending deliveries, oldest first
+func (s *OutboxStore) GetAll() []Delivery {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]Delivery, 0, len(s.deliveries))
+	for _, d := range s.deliveries {
+		result = append(result, d)
+	}
+	sortDeliveries(result)
+	return result
+}
+
+// Add queues a new delivery that is due immediately
+func (s *OutboxStore) Add(d Delivery) (Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stampDelivery(&d, time.Now())
+	s.deliveries[d.ID] = d
+	return d, nil
+}
+
+// Update stores the retry state of a pending delivery
+func (s *OutboxStore) Update(d Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.deliveries[d.ID]; !ok {
+		return ErrDeliveryNotFound
+	}
+	s.deliveries[d.ID] = d
+	return nil
+}
+
+// Delete removes a delivery once it was delivered or given up
+func (s *OutboxStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.deliveries[id]; !ok {
+		return ErrDeliveryNotFound
+	}
+	delete(s.deliveries, id)
+	return nil
+}
+
+// replaceAll swaps the whole content of the outbox
+func (s *OutboxStore) replaceAll(list []Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deliveries = make(map[string]Delivery, len(list))
+	for _, d := range list {
+		s.deliveries[d.ID] = d
+	}
+	return nil
+}
+
+// stampDelivery assigns the ID and timestamps of a new delivery
+func stampDelivery(d *Delivery, now time.Time) {
+	d.ID = newTimestampID("delivery_")
+	d.CreatedAt = now
+	d.NextAttempt = now
+	d.Attempts = 0
+}
+
+// sortDeliveries orders deliveries by creation time so that a session's payloads go out in order
+func sortDeliveries(list []Delivery) {
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+}
+
+// FileOutboxStore persists the outbox to a JSON file on top of the in-memory store
+type FileOutboxStore struct {
+	*OutboxStore
+	filePath string
+	writeMu  sync.Mutex
+}
+
+// NewFileOutboxStore loads the pending deliveries from filePath
+func NewFileOutboxStore(filePath string) (*FileOutboxStore, error) {
+	store := &FileOutboxStore{
+		OutboxStore: NewOutboxStore(),
+		filePath:    filePath,
+	}
+
+	var doc listDocument[Delivery]
+	found, err := readDocument(DocumentOutbox, filePath, &doc)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		store.OutboxStore.replaceAll(doc.Items)
+		if len(doc.Items) > 0 {
+			log.Printf("Loaded %d pending Unreal Engine deliveries from %s", len(doc.Items), filePath)
+		}
+	}
+	return store, nil
+}
+
+// Add queues a new delivery and persists the outbox
+func (s *FileOutboxStore) Add(d Delivery) (Delivery, error) {
+	var added Delivery
+	err := s.change(func() (err error) {
+		added, err = s.OutboxStore.Add(d)
+		return err
+	})
+	return added, err
+}
+
+// Update stores the retry state of a delivery and persists the outbox
+func (s *FileOutboxStore) Update(d Delivery) error {
+	return s.change(func() error {
+		return s.OutboxStore.Update(d)
+	})
+}
+
+// Delete removes a delivery and persists the outbox
+func (s *FileOutboxStore) Delete(id string) error {
+	return s.change(func() error {
+		return s.OutboxStore.Delete(id)
+	})
+}
+
+// change applies a change to the store and persists it, restoring the previous content
+// when the file cannot be written
+func (s *FileOutboxStore) change(apply func() error) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	return changeListFile(s.filePath, s.OutboxStore.GetAll, s.OutboxStore.replaceAll, apply)
+}
@@ -0,0 +1,108 @@
+// internal/models/progress.go
+package models
+
+import (
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+	"github.com/saladinomario/vr-training-admin/templates/components/trainees"
+)
+
+// TraineeProgress summarises the sessions of a trainee by scenario category and success
+// criterion. Only completed sessions count as practice. With intervalDays above zero,
+// competencies not practiced within that many days before now are flagged as overdue.
+func TraineeProgress(sessionStore SessionRepository, scenarioStore ScenarioRepository, trainee trainees.Trainee, intervalDays int, now time.Time) trainees.Progress {
+	progress := trainees.Progress{
+		Trainee:              trainee,
+		Categories:           newCompetencies(scenarios.ScenarioCategories()),
+		Criteria:             newCompetencies(scenarios.SuccessCriteriaTypes()),
+		PracticeIntervalDays: intervalDays,
+	}
+
+	list := sessionStore.ListByTrainee(trainee.ID)
+	progress.Sessions = len(list)
+
+	// Oldest first, so that scores are appended in the order they were reached
+	for i := len(list) - 1; i >= 0; i-- {
+		session := list[i]
+		if session.Status != sessions.StatusCompleted {
+			continue
+		}
+		progress.Completed++
+
+		at := session.StartTime
+		if session.EndTime != nil {
+			at = *session.EndTime
+		}
+		var point *trainees.ScorePoint
+		if session.Score != nil {
+			point = &trainees.ScorePoint{SessionID: session.ID, At: at, Score: *session.Score}
+			progress.Scores = append(progress.Scores, *point)
+		}
+
+		scenario := sessionScenario(scenarioStore, session)
+		practice(progress.Categories, []string{scenario.Category}, at, point)
+		for _, criterion := range scenarios.CriteriaOf(scenario.SuccessCriteria) {
+			practice(progress.Criteria, []string{criterion}, at, criterionPoint(session, criterion, at, point))
+		}
+	}
+
+	if intervalDays > 0 {
+		cutoff := now.AddDate(0, 0, -intervalDays)
+		for _, list := range [][]trainees.Competency{progress.Categories, progress.Criteria} {
+			for i := range list {
+				list[i].Overdue = list[i].LastPracticed == nil || list[i].LastPracticed.Before(cutoff)
+			}
+		}
+	}
+	return progress
+}
+
+// newCompetencies returns an empty competency for each name
+func newCompetencies(names []string) []trainees.Competency {
+	result := make([]trainees.Competency, len(names))
+	for i, name := range names {
+		result[i] = trainees.Competency{Name: name, Scores: []trainees.ScorePoint{}}
+	}
+	return result
+}
+
+// practice records a completed session at time at for the competencies named in names.
+// point is the score reached, or nil for an unscored session.
+func practice(list []trainees.Competency, names []string, at time.Time, point *trainees.ScorePoint) {
+	for _, name := range names {
+		for i := range list {
+			if list[i].Name != name {
+				continue
+			}
+			list[i].Practiced++
+			practicedAt := at
+			list[i].LastPracticed = &practicedAt
+			if point != nil {
+				list[i].Scores = append(list[i].Scores, *point)
+			}
+		}
+	}
+}
+
+// criterionPoint returns the score a session reached on a success criterion: the
+// criterion score of its rubric breakdown, falling back to the session score point
+func criterionPoint(session *sessions.Session, criterion string, at time.Time, point *trainees.ScorePoint) *trainees.ScorePoint {
+	for _, score := range session.ScoreBreakdown {
+		if score.Criterion == criterion {
+			return &trainees.ScorePoint{SessionID: session.ID, At: at, Score: score.Score}
+		}
+	}
+	return point
+}
+
+// sessionScenario returns the scenario a session ran, as captured when it started.
+// Sessions from before configurations were captured fall back to the current scenario.
+func sessionScenario(scenarioStore ScenarioRepository, session *sessions.Session) scenarios.Scenario {
+	if session.Configuration != nil {
+		return session.Configuration.Scenario
+	}
+	scenario, _ := scenarioStore.GetByID(session.ScenarioID)
+	return scenario
+}
@@ -0,0 +1,167 @@
+// internal/models/progress_test.go
+package models
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+	"github.com/saladinomario/vr-training-admin/templates/components/trainees"
+)
+
+const (
+	complaints = "Complaint Handling"
+	payments   = "Payment Processing"
+	conflicts  = "Effective Conflict Resolution"
+	standards  = "Service Standards Met"
+)
+
+// addSession creates a session of traineeID running a scenario of category scored on
+// criteria, moves it to status and scores it with score and breakdown when score is set
+func addSession(t *testing.T, store SessionRepository, traineeID, category, criteria, status string, score *int, breakdown ...sessions.CriterionScore) *sessions.Session {
+	t.Helper()
+	session, err := store.Create(sessions.Session{
+		TraineeID: traineeID,
+		Configuration: &sessions.Configuration{
+			Scenario: scenarios.Scenario{Category: category, SuccessCriteria: criteria},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := []string{sessions.StatusStarting, sessions.StatusRunning, status}
+	if status == sessions.StatusFailed {
+		path = []string{sessions.StatusStarting, status}
+	}
+	for _, to := range path {
+		if session, err = store.Transition(session.ID, sessions.Transition{To: to, Actor: sessions.ActorSystem}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if score != nil {
+		if err := store.SetScore(session.ID, *score, breakdown); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return session
+}
+
+// competency returns the competency called name
+func competency(t *testing.T, list []trainees.Competency, name string) trainees.Competency {
+	t.Helper()
+	for _, c := range list {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("no competency %s", name)
+	return trainees.Competency{}
+}
+
+// scoresOf returns the scores of points, in order
+func scoresOf(points []trainees.ScorePoint) []int {
+	result := make([]int, 0, len(points))
+	for _, point := range points {
+		result = append(result, point.Score)
+	}
+	return result
+}
+
+func TestTraineeProgress(t *testing.T) {
+	store := NewSessionStore(filepath.Join(t.TempDir(), "sessions.json"))
+	scenarioStore := NewScenarioStore()
+	trainee := trainees.Trainee{ID: "trainee_1", Name: "Sam"}
+
+	addSession(t, store, trainee.ID, complaints, communication+" and "+conflicts, sessions.StatusCompleted, intPtr(60),
+		sessions.CriterionScore{Criterion: communication, Score: 50})
+	addSession(t, store, trainee.ID, complaints, communication, sessions.StatusCompleted, intPtr(80))
+	addSession(t, store, trainee.ID, payments, standards, sessions.StatusCompleted, nil)
+	addSession(t, store, trainee.ID, complaints, communication, sessions.StatusFailed, nil)
+	addSession(t, store, "trainee_2", payments, communication, sessions.StatusCompleted, intPtr(10))
+
+	progress := TraineeProgress(store, scenarioStore, trainee, 0, time.Now())
+
+	if progress.Sessions != 4 || progress.Completed != 3 {
+		t.Errorf("progress counts %d sessions, %d completed, want 4 and 3", progress.Sessions, progress.Completed)
+	}
+	if got := scoresOf(progress.Scores); !reflect.DeepEqual(got, []int{60, 80}) {
+		t.Errorf("session scores = %v, want [60 80] oldest first", got)
+	}
+	if len(progress.Categories) != len(scenarios.ScenarioCategories()) || len(progress.Criteria) != len(scenarios.SuccessCriteriaTypes()) {
+		t.Errorf("progress has %d categories and %d criteria, want every one", len(progress.Categories), len(progress.Criteria))
+	}
+
+	tests := []struct {
+		name      string
+		list      []trainees.Competency
+		practiced int
+		scores    []int
+	}{
+		{complaints, progress.Categories, 2, []int{60, 80}},
+		{payments, progress.Categories, 1, []int{}},
+		{"Language Barrier", progress.Categories, 0, []int{}},
+		// The rubric breakdown scores the criterion; without one the session score counts
+		{communication, progress.Criteria, 2, []int{50, 80}},
+		{conflicts, progress.Criteria, 1, []int{60}},
+		{standards, progress.Criteria, 1, []int{}},
+		{procedure, progress.Criteria, 0, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := competency(t, tt.list, tt.name)
+			if c.Practiced != tt.practiced {
+				t.Errorf("practiced %d times, want %d", c.Practiced, tt.practiced)
+			}
+			if got := scoresOf(c.Scores); !reflect.DeepEqual(got, tt.scores) {
+				t.Errorf("scores = %v, want %v", got, tt.scores)
+			}
+			if (c.LastPracticed != nil) != (tt.practiced > 0) {
+				t.Errorf("last practiced = %v with %d sessions", c.LastPracticed, tt.practiced)
+			}
+			if c.Overdue {
+				t.Error("flagged as overdue without a practice interval")
+			}
+		})
+	}
+}
+
+func TestTraineeProgressOverdue(t *testing.T) {
+	store := NewSessionStore(filepath.Join(t.TempDir(), "sessions.json"))
+	trainee := trainees.Trainee{ID: "trainee_1", Name: "Sam"}
+	session := addSession(t, store, trainee.ID, complaints, communication, sessions.StatusCompleted, nil)
+	practiced := *session.EndTime
+
+	tests := []struct {
+		name     string
+		interval int
+		now      time.Time
+		overdue  bool
+	}{
+		{"within the interval", 30, practiced.AddDate(0, 0, 29), false},
+		{"exactly at the cutoff", 30, practiced.AddDate(0, 0, 30), false},
+		{"past the interval", 30, practiced.AddDate(0, 0, 30).Add(time.Second), true},
+		{"no interval", 0, practiced.AddDate(10, 0, 0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			progress := TraineeProgress(store, NewScenarioStore(), trainee, tt.interval, tt.now)
+
+			for _, name := range []string{complaints, communication} {
+				list := progress.Categories
+				if name == communication {
+					list = progress.Criteria
+				}
+				if c := competency(t, list, name); c.Overdue != tt.overdue {
+					t.Errorf("%s overdue: %t, want %t", name, c.Overdue, tt.overdue)
+				}
+			}
+			// Competencies never practiced are due as soon as there is an interval
+			if c := competency(t, progress.Categories, payments); c.Overdue != (tt.interval > 0) {
+				t.Errorf("unpracticed %s overdue: %t, want %t", payments, c.Overdue, tt.interval > 0)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// internal/models/references.go
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+)
+
+var (
+	// ErrInUse is returned when deleting an item that sessions still refer to
+	ErrInUse = errors.New("still used by sessions")
+	// ErrArchived is returned when starting a session with an archived item
+	ErrArchived = errors.New("archived items cannot be used for new sessions")
+)
+
+// maxListedSessions limits how many dependent sessions an InUseError names
+const maxListedSessions = 10
+
+// InUseError lists the sessions that prevent an item from being deleted
+type InUseError struct {
+	Kind     string
+	ID       string
+	Sessions []string
+}
+
+func (e *InUseError) Error() string {
+	listed := e.Sessions
+	more := ""
+	if len(listed) > maxListedSessions {
+		listed = listed[:maxListedSessions]
+		more = fmt.Sprintf(" and %d more", len(e.Sessions)-maxListedSessions)
+	}
+	return fmt.Sprintf("%s %s is used by %d sessions (%s%s); archive it instead",
+		e.Kind, e.ID, len(e.Sessions), strings.Join(listed, ", "), more)
+}
+
+func (e *InUseError) Unwrap() error {
+	return ErrInUse
+}
+
+// dependentSessions returns the IDs of the sessions for which uses reports true, newest first
+func dependentSessions(sessionStore SessionRepository, uses func(*sessions.Session) bool) []string {
+	ids := make([]string, 0)
+	for _, session := range sessionStore.GetAll() {
+		if uses(session) {
+			ids = append(ids, session.ID)
+		}
+	}
+	return ids
+}
+
+// DeleteScenario removes a scenario that no session refers to
+func DeleteScenario(scenarioStore ScenarioRepository, sessionStore SessionRepository, id string) error {
+	if _, err := scenarioStore.GetByID(id); err != nil {
+		return err
+	}
+	if ids := dependentSessions(sessionStore, func(s *sessions.Session) bool { return s.ScenarioID == id }); len(ids) > 0 {
+		return &InUseError{Kind: "scenario", ID: id, Sessions: ids}
+	}
+	return scenarioStore.Delete(id)
+}
+
+// DeleteAvatar removes an avatar that no session refers to
+func DeleteAvatar(avatarStore AvatarRepository, sessionStore SessionRepository, id string) error {
+	if _, err := avatarStore.GetByID(id); err != nil {
+		return err
+	}
+	if ids := dependentSessions(sessionStore, func(s *sessions.Session) bool { return s.AvatarID == id }); len(ids) > 0 {
+		return &InUseError{Kind: "avatar", ID: id, Sessions: ids}
+	}
+	return avatarStore.Delete(id)
+}
+
+// DeleteObserver removes an observer that no session refers to
+func DeleteObserver(observerStore ObserverRepository, sessionStore SessionRepository, id string) error {
+	if _, err := observerStore.GetByID(id); err != nil {
+		return err
+	}
+	if ids := dependentSessions(sessionStore, func(s *sessions.Session) bool { return s.ObserverID == id }); len(ids) > 0 {
+		return &InUseError{Kind: "observer", ID: id, Sessions: ids}
+	}
+	return observerStore.Delete(id)
+}
+
+// DeleteTrainee removes a trainee that no session refers to
+func DeleteTrainee(traineeStore TraineeRepository, sessionStore SessionRepository, id string) error {
+	if _, err := traineeStore.GetByID(id); err != nil {
+		return err
+	}
+	if ids := dependentSessions(sessionStore, func(s *sessions.Session) bool { return s.TraineeID == id }); len(ids) > 0 {
+		return &InUseError{Kind: "trainee", ID: id, Sessions: ids}
+	}
+	return traineeStore.Delete(id)
+}
@@ -0,0 +1,126 @@
+// internal/models/rubric.go
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/observers"
+	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+)
+
+// prepareRubric validates the rubric of an observer and gives new items an ID.
+// Every item needs a name, a positive weight and a scale with more than one rating.
+func prepareRubric(observer *observers.Observer) error {
+	for i := range observer.Rubric {
+		item := &observer.Rubric[i]
+		if item.Name == "" || item.Weight < 1 || item.Max <= item.Min {
+			return fmt.Errorf("%w: rubric item %q needs a name, a positive weight and a scale", ErrInvalidObserver, item.Name)
+		}
+		if item.ID == "" {
+			item.ID = newTimestampID("rubric_")
+		}
+	}
+	return nil
+}
+
+// ErrInvalidRatings is returned for ratings that do not fit the rubric of a session
+var ErrInvalidRatings = errors.New("invalid rubric ratings")
+
+// checkRatings validates the ratings of a completed session against the rubric of the
+// observer the session was started with. Sessions that predate configuration snapshots
+// cannot be checked and are scored from the score of the event alone.
+func checkRatings(config *sessions.Configuration, ratings map[string]int) error {
+	if config == nil {
+		return nil
+	}
+	for id, rating := range ratings {
+		item, ok := config.Observer.RubricItem(id)
+		if !ok {
+			return fmt.Errorf("%w: rating for unknown rubric item %q", ErrInvalidRatings, id)
+		}
+		if !item.InScale(rating) {
+			return fmt.Errorf("%w: rating %d of rubric item %q is outside its scale %s", ErrInvalidRatings, rating, item.Name, item.ScaleLabel())
+		}
+	}
+	return nil
+}
+
+// ScoreRubric scores a session from the ratings of its observer's rubric items.
+// Each success criterion of the scenario scores the weighted average of the rated items
+// mapped to it, and the session score is the average of the criterion scores weighted
+// by the items they count; an item mapped to two criteria counts towards both.
+// Scores are rounded for display only: the session score averages the unrounded sums.
+// It returns no breakdown when none of the rated items is mapped to a criterion.
+func ScoreRubric(config *sessions.Configuration, ratings map[string]int) ([]sessions.CriterionScore, int) {
+	if config == nil || len(ratings) == 0 {
+		return nil, 0
+	}
+
+	breakdown := make([]sessions.CriterionScore, 0)
+	total, totalWeight := 0.0, 0
+	for _, criterion := range scenarios.CriteriaOf(config.Scenario.SuccessCriteria) {
+		score := sessions.CriterionScore{Criterion: criterion, Items: []sessions.ItemScore{}}
+		sum := 0.0
+		for _, id := range config.Scenario.RubricItems[criterion] {
+			item, ok := config.Observer.RubricItem(id)
+			rating, rated := ratings[id]
+			if !ok || !rated {
+				continue
+			}
+			score.Items = append(score.Items, sessions.ItemScore{
+				ItemID: item.ID,
+				Name:   item.Name,
+				Weight: item.Weight,
+				Min:    item.Min,
+				Max:    item.Max,
+				Rating: rating,
+				Score:  item.Percent(rating),
+			})
+			score.Weight += item.Weight
+			sum += float64(item.Weight) * item.ExactPercent(rating)
+		}
+		if score.Weight == 0 {
+			continue
+		}
+		score.Score = weightedAverage(sum, score.Weight)
+		breakdown = append(breakdown, score)
+		total += sum
+		totalWeight += score.Weight
+	}
+
+	if len(breakdown) == 0 {
+		return nil, 0
+	}
+	return breakdown, weightedAverage(total, totalWeight)
+}
+
+// sessionScore returns the score a completed event gives its session: the rubric score
+// when the ratings cover a success criterion, otherwise the score the station reported
+func sessionScore(config *sessions.Configuration, event sessions.Event) (*int, []sessions.CriterionScore) {
+	if breakdown, score := ScoreRubric(config, event.Ratings); len(breakdown) > 0 {
+		return &score, breakdown
+	}
+	return event.Score, nil
+}
+
+// RateSession scores a session from the ratings a trainer gave its rubric items when
+// completing it, the same way as the ratings of a station's completed event. Ratings that
+// cover no success criterion leave the score as it is.
+func RateSession(sessionStore SessionRepository, session *sessions.Session, ratings map[string]int) error {
+	if err := checkRatings(session.Configuration, ratings); err != nil {
+		return err
+	}
+	breakdown, score := ScoreRubric(session.Configuration, ratings)
+	if len(breakdown) == 0 {
+		return nil
+	}
+	return sessionStore.SetScore(session.ID, score, breakdown)
+}
+
+// weightedAverage rounds a sum of weighted scores divided by their total weight
+func weightedAverage(sum float64, weight int) int {
+	return int(math.Round(sum / float64(weight)))
+}
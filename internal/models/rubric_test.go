@@ -0,0 +1,227 @@
+// internal/models/rubric_test.go
+package models
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/observers"
+	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+)
+
+const (
+	communication = "Clear Communication"
+	procedure     = "Proper Procedure Following"
+)
+
+// rubricConfig returns a configuration whose observer has the given items and whose
+// scenario scores its success criteria with mapping
+func rubricConfig(items []observers.RubricItem, mapping map[string][]string) *sessions.Configuration {
+	criteria := ""
+	for _, criterion := range scenarios.SuccessCriteriaTypes() {
+		if _, ok := mapping[criterion]; ok {
+			if criteria != "" {
+				criteria += " and "
+			}
+			criteria += criterion
+		}
+	}
+	return &sessions.Configuration{
+		Scenario: scenarios.Scenario{SuccessCriteria: criteria, RubricItems: mapping},
+		Observer: observers.Observer{Rubric: items},
+	}
+}
+
+func TestScoreRubric(t *testing.T) {
+	items := []observers.RubricItem{
+		{ID: "greeting", Name: "Greeting", Weight: 1, Min: 0, Max: 10},
+		{ID: "clarity", Name: "Clarity", Weight: 3, Min: 0, Max: 10},
+		{ID: "steps", Name: "Steps", Weight: 2, Min: 1, Max: 5},
+		{ID: "pass", Name: "Pass", Weight: 1, Min: 0, Max: 2},
+		{ID: "thirds", Name: "Thirds", Weight: 1, Min: 0, Max: 3},
+	}
+
+	tests := []struct {
+		name      string
+		mapping   map[string][]string
+		ratings   map[string]int
+		criteria  map[string]int // expected score of each criterion in the breakdown
+		want      int
+		breakdown bool
+	}{
+		{
+			name:      "one item",
+			mapping:   map[string][]string{communication: {"greeting"}},
+			ratings:   map[string]int{"greeting": 7},
+			criteria:  map[string]int{communication: 70},
+			want:      70,
+			breakdown: true,
+		},
+		{
+			name:      "items weighted within a criterion",
+			mapping:   map[string][]string{communication: {"greeting", "clarity"}},
+			ratings:   map[string]int{"greeting": 10, "clarity": 6},
+			criteria:  map[string]int{communication: 70},
+			want:      70,
+			breakdown: true,
+		},
+		{
+			name:      "scale offset by its minimum",
+			mapping:   map[string][]string{procedure: {"steps"}},
+			ratings:   map[string]int{"steps": 2},
+			criteria:  map[string]int{procedure: 25},
+			want:      25,
+			breakdown: true,
+		},
+		{
+			name:      "criteria weighted by their items",
+			mapping:   map[string][]string{communication: {"greeting"}, procedure: {"steps"}},
+			ratings:   map[string]int{"greeting": 10, "steps": 1},
+			criteria:  map[string]int{communication: 100, procedure: 0},
+			want:      33,
+			breakdown: true,
+		},
+		{
+			name:      "item mapped to two criteria counts towards both",
+			mapping:   map[string][]string{communication: {"greeting", "clarity"}, procedure: {"clarity"}},
+			ratings:   map[string]int{"greeting": 10, "clarity": 0},
+			criteria:  map[string]int{communication: 25, procedure: 0},
+			want:      14,
+			breakdown: true,
+		},
+		{
+			name:      "unrated and unknown items are skipped",
+			mapping:   map[string][]string{communication: {"greeting", "clarity", "removed"}, procedure: {"steps"}},
+			ratings:   map[string]int{"greeting": 4},
+			criteria:  map[string]int{communication: 40},
+			want:      40,
+			breakdown: true,
+		},
+		{
+			// The criteria score 0 and 66.67; rounding them first would give 34
+			name:      "session score averages unrounded criterion scores",
+			mapping:   map[string][]string{communication: {"pass"}, procedure: {"thirds"}},
+			ratings:   map[string]int{"pass": 0, "thirds": 2},
+			criteria:  map[string]int{communication: 0, procedure: 67},
+			want:      33,
+			breakdown: true,
+		},
+		{
+			name:    "no rated item is mapped",
+			mapping: map[string][]string{communication: {"greeting"}},
+			ratings: map[string]int{"clarity": 5},
+		},
+		{
+			name:    "no ratings",
+			mapping: map[string][]string{communication: {"greeting"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breakdown, score := ScoreRubric(rubricConfig(items, tt.mapping), tt.ratings)
+			if got := len(breakdown) > 0; got != tt.breakdown {
+				t.Fatalf("breakdown = %+v, want one: %t", breakdown, tt.breakdown)
+			}
+			if score != tt.want {
+				t.Errorf("session score = %d, want %d", score, tt.want)
+			}
+			if len(breakdown) != len(tt.criteria) {
+				t.Errorf("breakdown has %d criteria, want %d", len(breakdown), len(tt.criteria))
+			}
+			for _, criterion := range breakdown {
+				if want, ok := tt.criteria[criterion.Criterion]; !ok || criterion.Score != want {
+					t.Errorf("%s scores %d, want %d", criterion.Criterion, criterion.Score, want)
+				}
+			}
+		})
+	}
+}
+
+func TestScoreRubricWithoutConfiguration(t *testing.T) {
+	if breakdown, score := ScoreRubric(nil, map[string]int{"greeting": 5}); breakdown != nil || score != 0 {
+		t.Errorf("ScoreRubric(nil) = %v, %d, want no score", breakdown, score)
+	}
+}
+
+func TestStaleRubricItems(t *testing.T) {
+	observerList := []observers.Observer{
+		{Rubric: []observers.RubricItem{{ID: "greeting"}}},
+		{Rubric: []observers.RubricItem{{ID: "steps"}}},
+	}
+	scenario := scenarios.Scenario{RubricItems: map[string][]string{
+		communication: {"greeting", "removed"},
+		procedure:     {"steps"},
+	}}
+
+	tests := []struct {
+		criterion string
+		want      []string
+	}{
+		{communication, []string{"removed"}},
+		{procedure, []string{}},
+		{"Service Standards Met", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.criterion, func(t *testing.T) {
+			got := scenario.StaleRubricItems(tt.criterion, observerList)
+			if len(got) != len(tt.want) || len(got) > 0 && got[0] != tt.want[0] {
+				t.Errorf("stale items = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateSession(t *testing.T) {
+	items := []observers.RubricItem{
+		{ID: "greeting", Name: "Greeting", Weight: 1, Min: 0, Max: 10},
+		{ID: "unmapped", Name: "Unmapped", Weight: 1, Min: 0, Max: 10},
+	}
+	config := rubricConfig(items, map[string][]string{communication: {"greeting"}})
+
+	tests := []struct {
+		name      string
+		ratings   map[string]int
+		wantErr   error
+		wantScore *int
+	}{
+		{"rated item", map[string]int{"greeting": 9}, nil, intPtr(90)},
+		{"no ratings leave the score unset", map[string]int{}, nil, nil},
+		{"unmapped item scores nothing", map[string]int{"unmapped": 9}, nil, nil},
+		{"rating outside the scale", map[string]int{"greeting": 11}, ErrInvalidRatings, nil},
+		{"unknown item", map[string]int{"removed": 1}, ErrInvalidRatings, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewSessionStore(filepath.Join(t.TempDir(), "sessions.json"))
+			session, err := store.Create(sessions.Session{Configuration: config})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = RateSession(store, session, tt.ratings)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RateSession = %v, want %v", err, tt.wantErr)
+			}
+			rated, err := store.GetByID(session.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch {
+			case tt.wantScore == nil && rated.Score != nil:
+				t.Errorf("score = %d, want none", *rated.Score)
+			case tt.wantScore != nil && (rated.Score == nil || *rated.Score != *tt.wantScore):
+				t.Errorf("score = %v, want %d", rated.Score, *tt.wantScore)
+			case tt.wantScore != nil && len(rated.ScoreBreakdown) != 1:
+				t.Errorf("breakdown has %d criteria, want 1", len(rated.ScoreBreakdown))
+			}
+		})
+	}
+}
+
+func intPtr(n int) *int {
+	return &n
+}
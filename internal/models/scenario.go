@@ -3,9 +3,9 @@ package models
 
 import (
 	"errors"
+	"log"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
 )
@@ -13,6 +13,7 @@ import (
 var (
 	ErrScenarioNotFound = errors.New("scenario not found")
 	ErrInvalidScenario  = errors.New("invalid scenario data")
+	ErrScenarioExists   = errors.New("scenario already exists")
 )
 
 // ScenarioStore implements an in-memory storage for scenarios
@@ -40,6 +41,10 @@ func NewScenarioStore() *ScenarioStore {
 			BackgroundNoise: true,
 			SuccessCriteria: "Clear Communication and Proper Procedure Following",
 			Keywords:        "digital services, online forms, identity verification, patience, step-by-step guidance",
+			RubricItems: map[string][]string{
+				"Clear Communication":        {"rubric_1_clarity", "rubric_2_plain_language", "rubric_2_empathy"},
+				"Proper Procedure Following": {"rubric_1_procedure", "rubric_1_documentation"},
+			},
 		},
 		{
 			ID:              "2",
@@ -52,6 +57,9 @@ func NewScenarioStore() *ScenarioStore {
 			BackgroundNoise: false,
 			SuccessCriteria: "Effective Communication and Accurate Information Provided",
 			Keywords:        "translation, clear speech, visual aids, documentation requirements, verification",
+			RubricItems: map[string][]string{
+				"Accurate Information Provided": {"rubric_1_documentation", "rubric_1_clarity", "rubric_2_plain_language"},
+			},
 		},
 		{
 			ID:              "3",
@@ -64,6 +72,10 @@ func NewScenarioStore() *ScenarioStore {
 			BackgroundNoise: true,
 			SuccessCriteria: "Effective Conflict Resolution and Appropriate Referral Made",
 			Keywords:        "urgency, empathy, crisis management, service coordination, immediate response",
+			RubricItems: map[string][]string{
+				"Effective Conflict Resolution": {"rubric_2_deescalation", "rubric_2_empathy"},
+				"Appropriate Referral Made":     {"rubric_2_referral", "rubric_1_procedure"},
+			},
 		},
 	}
 
@@ -114,12 +126,28 @@ func (s *ScenarioStore) Create(scenario scenarios.Scenario) (scenarios.Scenario,
 	return scenario, nil
 }
 
+// Insert adds a scenario under its own ID, as done when importing content
+func (s *ScenarioStore) Insert(scenario scenarios.Scenario) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if scenario.ID == "" || scenario.Name == "" {
+		return ErrInvalidScenario
+	}
+	if _, ok := s.scenarios[scenario.ID]; ok {
+		return ErrScenarioExists
+	}
+	s.scenarios[scenario.ID] = scenario
+	return nil
+}
+
 // Update modifies an existing scenario
 func (s *ScenarioStore) Update(id string, scenario scenarios.Scenario) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, ok := s.scenarios[id]; !ok {
+	existing, ok := s.scenarios[id]
+	if !ok {
 		return ErrScenarioNotFound
 	}
 
@@ -128,8 +156,23 @@ func (s *ScenarioStore) Update(id string, scenario scenarios.Scenario) error {
 		return ErrInvalidScenario
 	}
 
-	// Preserve the ID
+	// Preserve the ID and archive state
 	scenario.ID = id
+	scenario.Archived = existing.Archived
+	s.scenarios[id] = scenario
+	return nil
+}
+
+// SetArchived archives a scenario, or brings it back
+func (s *ScenarioStore) SetArchived(id string, archived bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scenario, ok := s.scenarios[id]
+	if !ok {
+		return ErrScenarioNotFound
+	}
+	scenario.Archived = archived
 	s.scenarios[id] = scenario
 	return nil
 }
@@ -170,7 +213,104 @@ func (s *ScenarioStore) Search(query string) []scenarios.Scenario {
 	return result
 }
 
+// replaceAll swaps the whole content of the store
+func (s *ScenarioStore) replaceAll(list []scenarios.Scenario) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scenarios = make(map[string]scenarios.Scenario, len(list))
+	for _, scenario := range list {
+		s.scenarios[scenario.ID] = scenario
+	}
+	return nil
+}
+
 // Helper to generate a simple ID
 func generateID() string {
-	return "scenario_" + time.Now().Format("20060102150405")
+	return newTimestampID("scenario_")
+}
+
+// FileScenarioStore persists scenarios to a JSON file on top of the in-memory store
+type FileScenarioStore struct {
+	*ScenarioStore
+	filePath string
+	writeMu  sync.Mutex
+}
+
+// NewFileScenarioStore loads scenarios from filePath, seeding it with the sample data when the file does not exist yet
+func NewFileScenarioStore(filePath string) (*FileScenarioStore, error) {
+	store := &FileScenarioStore{
+		ScenarioStore: NewScenarioStore(),
+		filePath:      filePath,
+	}
+
+	var doc listDocument[scenarios.Scenario]
+	found, err := readDocument(DocumentScenarios, filePath, &doc)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return store, store.save()
+	}
+	list := doc.Items
+
+	store.ScenarioStore.replaceAll(list)
+	log.Printf("Loaded %d scenarios from %s", len(list), filePath)
+
+	return store, nil
+}
+
+// Create adds a new scenario and persists the store
+func (s *FileScenarioStore) Create(scenario scenarios.Scenario) (scenarios.Scenario, error) {
+	var created scenarios.Scenario
+	err := s.change(func() (err error) {
+		created, err = s.ScenarioStore.Create(scenario)
+		return err
+	})
+	return created, err
+}
+
+// Insert adds a scenario under its own ID and persists the store
+func (s *FileScenarioStore) Insert(scenario scenarios.Scenario) error {
+	return s.change(func() error {
+		return s.ScenarioStore.Insert(scenario)
+	})
+}
+
+// Update modifies an existing scenario and persists the store
+func (s *FileScenarioStore) Update(id string, scenario scenarios.Scenario) error {
+	return s.change(func() error {
+		return s.ScenarioStore.Update(id, scenario)
+	})
+}
+
+// Delete removes a scenario and persists the store
+func (s *FileScenarioStore) Delete(id string) error {
+	return s.change(func() error {
+		return s.ScenarioStore.Delete(id)
+	})
+}
+
+// SetArchived archives a scenario, or brings it back, and persists the store
+func (s *FileScenarioStore) SetArchived(id string, archived bool) error {
+	return s.change(func() error {
+		return s.ScenarioStore.SetArchived(id, archived)
+	})
+}
+
+// change applies a change to the store and persists it, restoring the previous content
+// when the file cannot be written
+func (s *FileScenarioStore) change(apply func() error) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	return changeListFile(s.filePath, s.ScenarioStore.GetAll, s.ScenarioStore.replaceAll, apply)
+}
+
+// save writes a snapshot of the store to disk
+func (s *FileScenarioStore) save() error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	return writeJSONFile(s.filePath, newListDocument(s.GetAll()))
 }
@@ -0,0 +1,232 @@
+// internal/models/scenario_revisions.go
+package models
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
+)
+
+var ErrRevisionNotFound = errors.New("scenario revision not found")
+
+// SystemAuthor is recorded for revisions that were not made by a person,
+// such as the baseline revision of a scenario created before revisions existed
+const SystemAuthor = "system"
+
+// ScenarioRevisionStore keeps the immutable revisions of every scenario in memory
+type ScenarioRevisionStore struct {
+	revisions map[string]scenarios.Revision
+	mu        sync.RWMutex
+}
+
+// NewScenarioRevisionStore creates an empty revision store
+func NewScenarioRevisionStore() *ScenarioRevisionStore {
+	return &ScenarioRevisionStore{revisions: make(map[string]scenarios.Revision)}
+}
+
+// ListByScenario returns the revisions of a scenario, newest first
+func (s *ScenarioRevisionStore) ListByScenario(scenarioID string) []scenarios.Revision {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]scenarios.Revision, 0)
+	for _, rev := range s.revisions {
+		if rev.ScenarioID == scenarioID {
+			result = append(result, rev)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Number > result[j].Number })
+	return result
+}
+
+// GetAll returns every revision of every scenario
+func (s *ScenarioRevisionStore) GetAll() []scenarios.Revision {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]scenarios.Revision, 0, len(s.revisions))
+	for _, rev := range s.revisions {
+		result = append(result, rev)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// GetByID returns a revision by ID
+func (s *ScenarioRevisionStore) GetByID(id string) (scenarios.Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rev, ok := s.revisions[id]
+	if !ok {
+		return scenarios.Revision{}, ErrRevisionNotFound
+	}
+	return rev, nil
+}
+
+// Add stores a new revision, numbering it after the latest revision of its scenario
+func (s *ScenarioRevisionStore) Add(rev scenarios.Revision) (scenarios.Revision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rev.ScenarioID == "" {
+		return scenarios.Revision{}, ErrInvalidScenario
+	}
+
+	number := 0
+	for _, existing := range s.revisions {
+		if existing.ScenarioID == rev.ScenarioID && existing.Number > number {
+			number = existing.Number
+		}
+	}
+	stampRevision(&rev, number+1)
+	s.revisions[rev.ID] = rev
+	return rev, nil
+}
+
+// replaceAll swaps every revision
+func (s *ScenarioRevisionStore) replaceAll(list []scenarios.Revision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.revisions = make(map[string]scenarios.Revision, len(list))
+	for _, rev := range list {
+		s.revisions[rev.ID] = rev
+	}
+	return nil
+}
+
+// stampRevision assigns the number, ID and timestamp of a new revision
+func stampRevision(rev *scenarios.Revision, number int) {
+	rev.Number = number
+	rev.ID = fmt.Sprintf("%s_r%d", rev.ScenarioID, number)
+	rev.Scenario.ID = rev.ScenarioID
+	if rev.CreatedAt.IsZero() {
+		rev.CreatedAt = time.Now()
+	}
+	if rev.Author == "" {
+		rev.Author = SystemAuthor
+	}
+}
+
+// FileScenarioRevisionStore persists revisions to a JSON file on top of the in-memory store
+type FileScenarioRevisionStore struct {
+	*ScenarioRevisionStore
+	filePath string
+	writeMu  sync.Mutex
+}
+
+// NewFileScenarioRevisionStore loads revisions from filePath
+func NewFileScenarioRevisionStore(filePath string) (*FileScenarioRevisionStore, error) {
+	store := &FileScenarioRevisionStore{
+		ScenarioRevisionStore: NewScenarioRevisionStore(),
+		filePath:              filePath,
+	}
+
+	var doc listDocument[scenarios.Revision]
+	found, err := readDocument(DocumentScenarioRevisions, filePath, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("loading scenario revisions: %w", err)
+	}
+	if found {
+		store.ScenarioRevisionStore.replaceAll(doc.Items)
+		log.Printf("Loaded %d scenario revisions from %s", len(doc.Items), filePath)
+	}
+	return store, nil
+}
+
+// Add stores a new revision and persists the store
+func (s *FileScenarioRevisionStore) Add(rev scenarios.Revision) (scenarios.Revision, error) {
+	var added scenarios.Revision
+	err := s.change(func() (err error) {
+		added, err = s.ScenarioRevisionStore.Add(rev)
+		return err
+	})
+	return added, err
+}
+
+// change applies a change to the store and persists it, restoring the previous content
+// when the file cannot be written
+func (s *FileScenarioRevisionStore) change(apply func() error) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	return changeListFile(s.filePath, s.ScenarioRevisionStore.GetAll, s.ScenarioRevisionStore.replaceAll, apply)
+}
+
+// CreateScenario creates a scenario and records its first revision
+func CreateScenario(scenarioStore ScenarioRepository, revisionStore ScenarioRevisionRepository, scenario scenarios.Scenario, author string) (scenarios.Scenario, error) {
+	created, err := scenarioStore.Create(scenario)
+	if err != nil {
+		return created, err
+	}
+	if _, err := revisionStore.Add(scenarios.Revision{ScenarioID: created.ID, Author: author, Note: "Created", Scenario: created}); err != nil {
+		return created, fmt.Errorf("recording revision: %w", err)
+	}
+	return created, nil
+}
+
+// UpdateScenario saves a new version of a scenario and records it as a revision.
+// The version being replaced is recorded first if it has no revision yet.
+func UpdateScenario(scenarioStore ScenarioRepository, revisionStore ScenarioRevisionRepository, id string, scenario scenarios.Scenario, author, note string) (scenarios.Revision, error) {
+	if _, err := CurrentScenarioRevision(scenarioStore, revisionStore, id); err != nil {
+		return scenarios.Revision{}, err
+	}
+	if err := scenarioStore.Update(id, scenario); err != nil {
+		return scenarios.Revision{}, err
+	}
+	scenario.ID = id
+	return revisionStore.Add(scenarios.Revision{ScenarioID: id, Author: author, Note: note, Scenario: scenario})
+}
+
+// RollbackScenario restores the content of an earlier revision. The rollback is itself
+// recorded as a new revision, so the history is never rewritten.
+func RollbackScenario(scenarioStore ScenarioRepository, revisionStore ScenarioRevisionRepository, scenarioID, revisionID, author string) (scenarios.Revision, error) {
+	target, err := revisionStore.GetByID(revisionID)
+	if err != nil {
+		return scenarios.Revision{}, err
+	}
+	if target.ScenarioID != scenarioID {
+		return scenarios.Revision{}, ErrRevisionNotFound
+	}
+
+	if _, err := CurrentScenarioRevision(scenarioStore, revisionStore, scenarioID); err != nil {
+		return scenarios.Revision{}, err
+	}
+	if err := scenarioStore.Update(scenarioID, target.Scenario); err != nil {
+		return scenarios.Revision{}, err
+	}
+	return revisionStore.Add(scenarios.Revision{
+		ScenarioID:   scenarioID,
+		Author:       author,
+		Note:         fmt.Sprintf("Rolled back to revision %d", target.Number),
+		RestoredFrom: target.ID,
+		Scenario:     target.Scenario,
+	})
+}
+
+// CurrentScenarioRevision returns the revision matching the current content of a scenario.
+// Scenarios that have no revision yet, or were changed without one (for example by a
+// restore), get a revision recorded by the system so every version can be referenced.
+func CurrentScenarioRevision(scenarioStore ScenarioRepository, revisionStore ScenarioRevisionRepository, scenarioID string) (scenarios.Revision, error) {
+	scenario, err := scenarioStore.GetByID(scenarioID)
+	if err != nil {
+		return scenarios.Revision{}, err
+	}
+
+	revisions := revisionStore.ListByScenario(scenarioID)
+	if len(revisions) > 0 && scenarios.SameContent(revisions[0].Scenario, scenario) {
+		return revisions[0], nil
+	}
+
+	note := "Baseline"
+	if len(revisions) > 0 {
+		note = "Changed outside the editor"
+	}
+	return revisionStore.Add(scenarios.Revision{ScenarioID: scenarioID, Author: SystemAuthor, Note: note, Scenario: scenario})
+}
@@ -2,30 +2,34 @@
 package models
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
 
-	"github.com/saladinomario/vr-training-admin/templates/components/avatars"
-	"github.com/saladinomario/vr-training-admin/templates/components/observers"
-	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
+	"github.com/saladinomario/vr-training-admin/internal/uepayload"
 	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
 )
 
 var (
-	ErrSessionNotFound = errors.New("session not found")
-	ErrInvalidSession  = errors.New("invalid session data")
+	ErrSessionNotFound   = errors.New("session not found")
+	ErrInvalidSession    = errors.New("invalid session data")
+	ErrIllegalTransition = errors.New("illegal session status change")
 )
 
-// TrainingSessionStore manages VR training sessions
+// SessionStore manages VR training sessions and persists them to a JSON file
 type SessionStore struct {
 	sessions map[string]*sessions.Session
 	mu       sync.RWMutex
 	filePath string
+
+	// writeMu serializes saves so that snapshots reach the disk in the order they were taken
+	writeMu sync.Mutex
 }
 
 // NewSessionStore creates a new session store
@@ -41,63 +45,238 @@ func NewSessionStore(filePath string) *SessionStore {
 		log.Printf("Error creating directory for sessions: %v", err)
 	}
 
-	// Load existing sessions if file exists
-	if _, err := os.Stat(filePath); err == nil {
-		store.loadSessions()
-	}
+	// Load existing sessions, recovering from a damaged file if needed
+	store.loadSessions()
 
 	return store
 }
 
-// loadSessions loads sessions from disk
+// loadSessions loads sessions from disk. When the sessions file is corrupt or truncated
+// it is moved aside and the store falls back to the backup kept by the last save, or to
+// whatever sessions can be salvaged from the damaged file.
 func (s *SessionStore) loadSessions() {
+	removeStaleTempFiles(s.filePath)
+
+	// Upgrade older layouts first; a damaged file is handled by the recovery below
+	if _, err := migrateFile(DocumentSessions, s.filePath, false); err != nil {
+		log.Printf("Error migrating sessions file: %v", err)
+	}
+
 	data, err := os.ReadFile(s.filePath)
+	recovered := false
+	if os.IsNotExist(err) {
+		// A crash between keeping the backup and renaming can leave only the backup
+		data, err = os.ReadFile(s.filePath + ".bak")
+		if os.IsNotExist(err) {
+			return
+		}
+		log.Printf("Sessions file missing, restoring from backup")
+		recovered = true
+	}
 	if err != nil {
 		log.Printf("Error reading sessions file: %v", err)
 		return
 	}
 
-	var sessions []*sessions.Session
-	if err := json.Unmarshal(data, &sessions); err != nil {
-		log.Printf("Error unmarshaling sessions: %v", err)
+	loaded, err := decodeSessions(data)
+	if err != nil {
+		loaded = s.recoverSessions(data, err)
+		recovered = true
+	}
+
+	s.setAll(loaded)
+	if recovered {
+		if err := s.saveSessions(); err != nil {
+			log.Printf("Error rewriting recovered sessions: %v", err)
+		}
 		return
 	}
+	log.Printf("Loaded %d sessions from disk", len(loaded))
+}
+
+// recoverSessions handles a sessions file that failed to decode
+func (s *SessionStore) recoverSessions(data []byte, decodeErr error) []*sessions.Session {
+	log.Printf("Sessions file %s is corrupt: %v", s.filePath, decodeErr)
+
+	corruptPath := s.filePath + ".corrupt-" + time.Now().Format("20060102150405")
+	if err := os.WriteFile(corruptPath, data, 0644); err != nil {
+		log.Printf("Error preserving corrupt sessions file: %v", err)
+	} else {
+		log.Printf("Preserved corrupt sessions file as %s", corruptPath)
+	}
+
+	salvaged := salvageSessions(data)
+
+	if backup, err := os.ReadFile(s.filePath + ".bak"); err == nil {
+		if fromBackup, err := decodeSessions(backup); err == nil && len(fromBackup) >= len(salvaged) {
+			log.Printf("Recovered %d sessions from backup", len(fromBackup))
+			return fromBackup
+		}
+	}
+
+	log.Printf("Recovered %d sessions from the readable part of the damaged file", len(salvaged))
+	return salvaged
+}
+
+// decodeSessions strictly decodes a sessions document, upgrading older layouts in memory
+func decodeSessions(data []byte) ([]*sessions.Session, error) {
+	raw, _, err := upgradeDocument(DocumentSessions, data)
+	if err != nil {
+		return nil, err
+	}
+	upgraded, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc listDocument[*sessions.Session]
+	if err := json.Unmarshal(upgraded, &doc); err != nil {
+		return nil, err
+	}
+	return doc.Items, nil
+}
+
+// salvageSessions decodes sessions one by one and keeps every complete entry
+// that precedes the first damaged one. Both the versioned document and the
+// legacy bare array layout are understood.
+func salvageSessions(data []byte) []*sessions.Session {
+	result := make([]*sessions.Session, 0)
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return result
+	}
+
+	// Skip ahead to the items array of a versioned document
+	if tok == json.Delim('{') {
+		for {
+			key, err := dec.Token()
+			if err != nil || key == json.Delim('}') {
+				return result
+			}
+			if key == "items" {
+				break
+			}
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return result
+			}
+		}
+		if tok, err = dec.Token(); err != nil {
+			return result
+		}
+	}
+	if tok != json.Delim('[') {
+		return result
+	}
 
+	for dec.More() {
+		var session sessions.Session
+		if err := dec.Decode(&session); err != nil {
+			break
+		}
+		if session.ID != "" {
+			result = append(result, &session)
+		}
+	}
+	return result
+}
+
+// setAll replaces the in-memory sessions
+func (s *SessionStore) setAll(list []*sessions.Session) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for _, session := range sessions {
-		s.sessions[session.ID] = session
+	s.sessions = make(map[string]*sessions.Session, len(list))
+	for _, session := range list {
+		s.sessions[session.ID] = session.Clone()
 	}
+}
+
+// replaceAll swaps every session in memory; Stores.Restore writes the file
+func (s *SessionStore) replaceAll(list []*sessions.Session) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
 
-	log.Printf("Loaded %d sessions from disk", len(sessions))
+	s.setAll(list)
+	return nil
 }
 
-// saveSessions saves sessions to disk
+// update applies change to a copy of session id and commits the copy. Changes that fail,
+// or cannot be written to disk, leave the session as it was.
+func (s *SessionStore) update(id string, change func(*sessions.Session) error) (*sessions.Session, error) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.mu.RLock()
+	current, ok := s.sessions[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
 
+	updated := current.Clone()
+	if err := change(updated); err != nil {
+		return nil, err
+	}
+	if err := s.commit(id, updated); err != nil {
+		return nil, err
+	}
+	return updated.Clone(), nil
+}
+
+// commit puts next in place of session id, or removes the session when next is nil, and
+// writes all sessions to disk. When the write fails the previous session is restored, so
+// the store never reports a change the disk does not have. The caller holds writeMu.
+func (s *SessionStore) commit(id string, next *sessions.Session) error {
+	s.mu.Lock()
+	previous, existed := s.sessions[id]
+	if next == nil {
+		delete(s.sessions, id)
+	} else {
+		s.sessions[id] = next
+	}
+	s.mu.Unlock()
+
+	if err := s.writeSessions(); err != nil {
+		s.mu.Lock()
+		if existed {
+			s.sessions[id] = previous
+		} else {
+			delete(s.sessions, id)
+		}
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// saveSessions atomically writes a snapshot of all sessions to disk
 func (s *SessionStore) saveSessions() error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	return s.writeSessions()
+}
+
+// writeSessions writes a snapshot of all sessions to disk. The caller holds writeMu, so
+// a later write never persists older data.
+func (s *SessionStore) writeSessions() error {
 	s.mu.RLock()
 	sessionsList := make([]*sessions.Session, 0, len(s.sessions))
 	for _, session := range s.sessions {
 		sessionsList = append(sessionsList, session)
 	}
+	data, err := json.MarshalIndent(newListDocument(sessionsList), "", "  ")
 	s.mu.RUnlock()
 
-	data, err := json.MarshalIndent(sessionsList, "", "  ")
 	if err != nil {
 		log.Printf("Error marshaling sessions: %v", err)
 		return err
 	}
 
-	// Make sure the directory exists
-	dir := filepath.Dir(s.filePath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		log.Printf("Error creating directory for sessions: %v", err)
-		return err
-	}
-
-	// Write file with explicit sync
-	if err := os.WriteFile(s.filePath, data, 0644); err != nil {
+	if err := writeFileAtomic(s.filePath, data, 0644); err != nil {
 		log.Printf("Error writing sessions to file: %v", err)
 		return err
 	}
@@ -113,21 +292,20 @@ func (s *SessionStore) GetAll() []*sessions.Session {
 
 	result := make([]*sessions.Session, 0, len(s.sessions))
 	for _, session := range s.sessions {
-		result = append(result, session)
+		result = append(result, session.Clone())
 	}
 
 	// Sort sessions by start time, newest first
-	for i := 0; i < len(result)-1; i++ {
-		for j := i + 1; j < len(result); j++ {
-			if result[i].StartTime.Before(result[j].StartTime) {
-				result[i], result[j] = result[j], result[i]
-			}
-		}
-	}
+	sortSessions(result, sessions.SortCreated, true)
 
 	return result
 }
 
+// Query returns the page of sessions selected by q
+func (s *SessionStore) Query(q sessions.Query) sessions.Page {
+	return querySessions(s.GetAll(), q)
+}
+
 // GetRecent returns the n most recent sessions
 func (s *SessionStore) GetRecent(n int) []*sessions.Session {
 	allSessions := s.GetAll()
@@ -137,6 +315,17 @@ func (s *SessionStore) GetRecent(n int) []*sessions.Session {
 	return allSessions[:n]
 }
 
+// ListByTrainee returns the sessions of a trainee, newest first
+func (s *SessionStore) ListByTrainee(traineeID string) []*sessions.Session {
+	result := make([]*sessions.Session, 0)
+	for _, session := range s.GetAll() {
+		if session.TraineeID == traineeID {
+			result = append(result, session)
+		}
+	}
+	return result
+}
+
 // GetByID returns a session by ID
 func (s *SessionStore) GetByID(id string) (*sessions.Session, error) {
 	s.mu.RLock()
@@ -146,94 +335,224 @@ func (s *SessionStore) GetByID(id string) (*sessions.Session, error) {
 	if !ok {
 		return nil, ErrSessionNotFound
 	}
-	return session, nil
+	return session.Clone(), nil
 }
 
-// Update the Create method:
-func (s *SessionStore) Create(scenarioID, avatarID, observerID string) (*sessions.Session, error) {
-	// Generate ID based on timestamp
-	id := "session_" + time.Now().Format("20060102150405")
-
-	// Create the session
-	session := &sessions.Session{
-		ID:         id,
-		ScenarioID: scenarioID,
-		AvatarID:   avatarID,
-		ObserverID: observerID,
-		Status:     sessions.StatusPending,
-		StartTime:  time.Now(),
-		UpdateTime: time.Now(),
+// Create starts a new pending session from the references and configuration filled in on session.
+// Sessions with a ScheduledFor time are created scheduled instead.
+func (s *SessionStore) Create(session sessions.Session) (*sessions.Session, error) {
+	if session.Configuration == nil {
+		return nil, ErrInvalidSession
 	}
 
-	s.mu.Lock()
-	s.sessions[id] = session
-	s.mu.Unlock()
+	now := time.Now()
+	first := createdTransition(&session, now)
+	session.ID = newTimestampID("session_")
+	session.Status = first.To
+	session.StartTime = now
+	session.UpdateTime = now
+	session.Transitions = []sessions.Transition{first}
 
-	// Save to disk synchronously
-	err := s.saveSessions()
-	if err != nil {
-		log.Printf("Error saving sessions: %v", err)
-	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
 
-	return session, nil
+	if err := s.commit(session.ID, session.Clone()); err != nil {
+		return nil, err
+	}
+	return &session, nil
 }
 
-// Update modifies an existing session
-// internal/models/session.go
-// Update the Update method:
+// Transition moves a session to t.To, recording who changed it and why.
+// Changes the state machine does not allow fail with ErrIllegalTransition.
+func (s *SessionStore) Transition(id string, t sessions.Transition) (*sessions.Session, error) {
+	return s.update(id, func(session *sessions.Session) error {
+		return applyTransition(session, t, time.Now())
+	})
+}
 
-func (s *SessionStore) Update(id string, status string) error {
-	s.mu.Lock()
+// Delete removes a session
+func (s *SessionStore) Delete(id string) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
 
-	session, ok := s.sessions[id]
+	s.mu.RLock()
+	_, ok := s.sessions[id]
+	s.mu.RUnlock()
 	if !ok {
 		return ErrSessionNotFound
 	}
+	return s.commit(id, nil)
+}
+
+// SetLegalHold places a session on legal hold, or releases it
+func (s *SessionStore) SetLegalHold(id string, hold bool) error {
+	_, err := s.update(id, func(session *sessions.Session) error {
+		session.LegalHold = hold
+		session.UpdateTime = time.Now()
+		return nil
+	})
+	return err
+}
+
+// SetStation moves a session that has not been sent yet to another station
+func (s *SessionStore) SetStation(id, stationID string) error {
+	_, err := s.update(id, func(session *sessions.Session) error {
+		if !session.IsQueued() {
+			return fmt.Errorf("%w: session %s is already %s", ErrIllegalTransition, id, session.Status)
+		}
+		session.StationID = stationID
+		session.UpdateTime = time.Now()
+		return nil
+	})
+	return err
+}
 
-	// Update status and timestamp
-	session.Status = status
-	session.UpdateTime = time.Now()
+// SetScore records the final score of a session and, when it was scored with a rubric,
+// the score of each success criterion
+func (s *SessionStore) SetScore(id string, score int, breakdown []sessions.CriterionScore) error {
+	_, err := s.update(id, func(session *sessions.Session) error {
+		session.Score = &score
+		session.ScoreBreakdown = breakdown
+		session.UpdateTime = time.Now()
+		return nil
+	})
+	return err
+}
 
-	// If completed, set end time
-	if status == sessions.StatusCompleted {
-		now := time.Now()
-		session.EndTime = &now
+// Anonymize removes the personal data of a session, keeping what is needed for statistics
+func (s *SessionStore) Anonymize(id string) error {
+	_, err := s.update(id, func(session *sessions.Session) error {
+		anonymizeSession(session, time.Now())
+		return nil
+	})
+	return err
+}
+
+// createdTransition is the first transition of every session: it is pending, or
+// scheduled when it was booked for later
+func createdTransition(session *sessions.Session, now time.Time) sessions.Transition {
+	if session.ScheduledFor != nil {
+		return sessions.Transition{
+			To:     sessions.StatusScheduled,
+			Actor:  sessions.ActorTrainer,
+			Reason: "session scheduled for " + session.ScheduledFor.Format("2006-01-02 15:04"),
+			At:     now,
+		}
 	}
+	return sessions.Transition{To: sessions.StatusPending, Actor: sessions.ActorTrainer, Reason: "session created", At: now}
+}
 
-	s.mu.Unlock()
+// applyTransition checks t against the session state machine and applies it at now
+func applyTransition(session *sessions.Session, t sessions.Transition, now time.Time) error {
+	if !sessions.CanTransition(session.Status, t.To) {
+		return fmt.Errorf("%w from %s to %s", ErrIllegalTransition, session.Status, t.To)
+	}
+	t.At = now
+	session.ApplyTransition(t)
+	return nil
+}
 
-	// Save to disk synchronously
-	err := s.saveSessions()
+// anonymizeSession clears the fields of a session that may identify a trainee
+func anonymizeSession(session *sessions.Session, now time.Time) {
+	session.Notes = ""
+	session.TraineeID = ""
+	session.TraineeName = ""
+	session.AnonymizedAt = &now
+}
+
+// assignTrainee checks that the trainee of session can be trained and records their name
+func assignTrainee(traineeStore TraineeRepository, session *sessions.Session) error {
+	trainee, err := traineeStore.GetByID(session.TraineeID)
 	if err != nil {
-		log.Printf("Error saving sessions: %v", err)
+		return err
 	}
-
+	if trainee.Archived {
+		return ErrArchived
+	}
+	session.TraineeName = trainee.Name
 	return nil
 }
 
-// Delete removes a session
-func (s *SessionStore) Delete(id string) error {
-	s.mu.RLock()
-	if _, ok := s.sessions[id]; !ok {
-		return ErrSessionNotFound
+// CreateSession captures the current scenario revision, avatar and observer referenced by
+// session and creates the session with that configuration embedded, for the trainee it names
+func CreateSession(sessionStore SessionRepository, scenarioStore ScenarioRepository, revisionStore ScenarioRevisionRepository, avatarStore AvatarRepository, observerStore ObserverRepository, traineeStore TraineeRepository, session sessions.Session) (*sessions.Session, error) {
+	if err := assignTrainee(traineeStore, &session); err != nil {
+		return nil, err
 	}
-	s.mu.RUnlock()
 
-	delete(s.sessions, id)
+	// Record the exact scenario revision the session is started with
+	revision, err := CurrentScenarioRevision(scenarioStore, revisionStore, session.ScenarioID)
+	if err != nil {
+		return nil, err
+	}
+	avatar, err := avatarStore.GetByID(session.AvatarID)
+	if err != nil {
+		return nil, err
+	}
+	observer, err := observerStore.GetByID(session.ObserverID)
+	if err != nil {
+		return nil, err
+	}
+	scenario, err := scenarioStore.GetByID(session.ScenarioID)
+	if err != nil {
+		return nil, err
+	}
+	if scenario.Archived || avatar.Archived || observer.Archived {
+		return nil, ErrArchived
+	}
 
-	// Save to disk
-	go s.saveSessions()
+	session.ScenarioRevisionID = revision.ID
+	session.Configuration = &sessions.Configuration{
+		CapturedAt: time.Now(),
+		Scenario:   revision.Scenario,
+		Avatar:     avatar,
+		Observer:   observer,
+	}
+	return sessionStore.Create(session)
+}
 
-	return nil
+// RerunSession creates a new session on stationID with the configuration original was
+// started with, for the trainee traineeID. Sessions from before configurations were captured
+// are rerun with the current content they reference.
+func RerunSession(sessionStore SessionRepository, scenarioStore ScenarioRepository, revisionStore ScenarioRevisionRepository, avatarStore AvatarRepository, observerStore ObserverRepository, traineeStore TraineeRepository, original *sessions.Session, stationID, traineeID string) (*sessions.Session, error) {
+	rerun := sessions.Session{
+		ScenarioID: original.ScenarioID,
+		AvatarID:   original.AvatarID,
+		ObserverID: original.ObserverID,
+		StationID:  stationID,
+		TraineeID:  traineeID,
+	}
+	if original.Configuration == nil {
+		return CreateSession(sessionStore, scenarioStore, revisionStore, avatarStore, observerStore, traineeStore, rerun)
+	}
+
+	if err := assignTrainee(traineeStore, &rerun); err != nil {
+		return nil, err
+	}
+	config := *original.Configuration
+	rerun.ScenarioRevisionID = original.ScenarioRevisionID
+	rerun.Configuration = &config
+	return sessionStore.Create(rerun)
 }
 
-// GetSessionDetails retrieves the scenario, avatar, and observer details for a session
-func (s *SessionStore) GetSessionDetails(id string, scenarioStore *ScenarioStore, avatarStore *AvatarStore, observerStore *ObserverStore) (*sessions.SessionDetails, error) {
-	session, err := s.GetByID(id)
+// GetSessionDetails returns a session together with its scenario, avatar and observer.
+// These come from the configuration captured when the session started; sessions created
+// before configurations were captured fall back to the current content.
+func GetSessionDetails(sessionStore SessionRepository, id string, scenarioStore ScenarioRepository, avatarStore AvatarRepository, observerStore ObserverRepository) (*sessions.SessionDetails, error) {
+	session, err := sessionStore.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
 
+	if cfg := session.Configuration; cfg != nil {
+		return &sessions.SessionDetails{
+			Session:  *session,
+			Scenario: cfg.Scenario,
+			Avatar:   cfg.Avatar,
+			Observer: cfg.Observer,
+		}, nil
+	}
+
 	// Get associated entities
 	scenario, err := scenarioStore.GetByID(session.ScenarioID)
 	if err != nil {
@@ -255,34 +574,28 @@ func (s *SessionStore) GetSessionDetails(id string, scenarioStore *ScenarioStore
 		Scenario: scenario,
 		Avatar:   avatar,
 		Observer: observer,
+		Live:     true,
 	}, nil
 }
 
-// CreateURESessionPayload creates the payload to send to Unreal Engine
-func (s *SessionStore) CreateURESessionPayload(id string, scenarioStore *ScenarioStore, avatarStore *AvatarStore, observerStore *ObserverStore) ([]byte, error) {
-	details, err := s.GetSessionDetails(id, scenarioStore, avatarStore, observerStore)
+// CreateURESessionPayload creates the payload to send to Unreal Engine, in the current uepayload.Version
+func CreateURESessionPayload(sessionStore SessionRepository, id string, scenarioStore ScenarioRepository, avatarStore AvatarRepository, observerStore ObserverRepository) ([]byte, error) {
+	details, err := GetSessionDetails(sessionStore, id, scenarioStore, avatarStore, observerStore)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create a payload structure for Unreal Engine
-	type UREPayload struct {
-		SessionID string             `json:"sessionId"`
-		Status    string             `json:"status"`
-		Scenario  scenarios.Scenario `json:"scenario"`
-		Avatar    avatars.Avatar     `json:"avatar"`
-		Observer  observers.Observer `json:"observer"`
-		Timestamp string             `json:"timestamp"`
-	}
+	return json.Marshal(uepayload.New(details, time.Now()))
+}
 
-	payload := UREPayload{
-		SessionID: details.Session.ID,
-		Status:    details.Session.Status,
-		Scenario:  details.Scenario,
-		Avatar:    details.Avatar,
-		Observer:  details.Observer,
-		Timestamp: time.Now().Format(time.RFC3339),
+// CreateUREStartPayload creates the payload that sends a pending session to its station,
+// describing the session as starting, which it is once the payload is on its way
+func CreateUREStartPayload(sessionStore SessionRepository, id string, scenarioStore ScenarioRepository, avatarStore AvatarRepository, observerStore ObserverRepository) ([]byte, error) {
+	details, err := GetSessionDetails(sessionStore, id, scenarioStore, avatarStore, observerStore)
+	if err != nil {
+		return nil, err
 	}
 
-	return json.Marshal(payload)
+	details.Session.Status = sessions.StatusStarting
+	return json.Marshal(uepayload.New(details, time.Now()))
 }
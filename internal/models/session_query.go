@@ -0,0 +1,107 @@
+// internal/models/session_query.go
+package models
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+)
+
+// querySessions returns the page of list selected by q, for stores that keep every
+// session in memory
+func querySessions(list []*sessions.Session, q sessions.Query) sessions.Page {
+	q = q.Normalize()
+
+	matched := make([]*sessions.Session, 0)
+	for _, session := range list {
+		if matchesQuery(session, q) {
+			matched = append(matched, session)
+		}
+	}
+	sortSessions(matched, q.Sort, q.Desc)
+
+	page := sessions.Page{Sessions: []*sessions.Session{}, Total: len(matched), Query: q}
+	if offset := q.Offset(); offset < len(matched) {
+		end := offset + q.PageSize
+		if end > len(matched) {
+			end = len(matched)
+		}
+		page.Sessions = matched[offset:end]
+	}
+	return page
+}
+
+// matchesQuery reports whether session passes every filter of q
+func matchesQuery(session *sessions.Session, q sessions.Query) bool {
+	switch {
+	case q.Status != "" && session.Status != q.Status,
+		q.ScenarioID != "" && session.ScenarioID != q.ScenarioID,
+		q.AvatarID != "" && session.AvatarID != q.AvatarID,
+		q.ObserverID != "" && session.ObserverID != q.ObserverID,
+		q.StationID != "" && session.StationID != q.StationID,
+		q.TraineeID != "" && session.TraineeID != q.TraineeID,
+		!q.From.IsZero() && session.StartTime.Before(q.From),
+		!q.To.IsZero() && !session.StartTime.Before(q.Until()):
+		return false
+	}
+	return true
+}
+
+// sortSessions orders list by column, newest first among equal values
+func sortSessions(list []*sessions.Session, column string, desc bool) {
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if c := compareSessions(a, b, column); c != 0 {
+			if desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		if !a.StartTime.Equal(b.StartTime) {
+			return a.StartTime.After(b.StartTime)
+		}
+		return a.ID > b.ID
+	})
+}
+
+// compareSessions compares two sessions by column, returning -1, 0 or 1.
+// Sessions without a score sort before every score.
+func compareSessions(a, b *sessions.Session, column string) int {
+	switch column {
+	case sessions.SortID:
+		return strings.Compare(a.ID, b.ID)
+	case sessions.SortTrainee:
+		return strings.Compare(strings.ToLower(a.TraineeName), strings.ToLower(b.TraineeName))
+	case sessions.SortScenario:
+		return strings.Compare(strings.ToLower(a.ScenarioName()), strings.ToLower(b.ScenarioName()))
+	case sessions.SortStation:
+		return strings.Compare(a.StationID, b.StationID)
+	case sessions.SortStatus:
+		return strings.Compare(a.Status, b.Status)
+	case sessions.SortDuration:
+		return compareInts(int64(a.GetDuration()), int64(b.GetDuration()))
+	case sessions.SortScore:
+		return compareInts(scoreOrMissing(a), scoreOrMissing(b))
+	default:
+		return compareInts(a.StartTime.UnixNano(), b.StartTime.UnixNano())
+	}
+}
+
+func scoreOrMissing(session *sessions.Session) int64 {
+	if session.Score == nil {
+		return -1
+	}
+	return int64(*session.Score)
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,171 @@
+// internal/models/session_query_test.go
+package models
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/internal/config"
+	"github.com/saladinomario/vr-training-admin/templates/components/avatars"
+	"github.com/saladinomario/vr-training-admin/templates/components/observers"
+	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+	"github.com/saladinomario/vr-training-admin/templates/components/trainees"
+)
+
+// querySnapshot returns sessions with ties in every sort column: equal creation times,
+// scores, trainees, scenarios, stations, statuses and durations
+func querySnapshot() *Snapshot {
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)
+	at := func(d time.Duration) *time.Time {
+		t := base.Add(d)
+		return &t
+	}
+	fireDrill := &sessions.Configuration{Scenario: scenarios.Scenario{ID: "scenario_1", Name: "Fire drill"}}
+	complaint := &sessions.Configuration{Scenario: scenarios.Scenario{ID: "scenario_2", Name: "angry customer"}}
+	day := 24 * time.Hour
+
+	session := func(id, status string, start time.Duration, config *sessions.Configuration, traineeID, traineeName, stationID string, score *int) *sessions.Session {
+		return &sessions.Session{
+			ID:            id,
+			ScenarioID:    config.Scenario.ID,
+			AvatarID:      "avatar_1",
+			ObserverID:    "observer_1",
+			StationID:     stationID,
+			TraineeID:     traineeID,
+			TraineeName:   traineeName,
+			Status:        status,
+			StartTime:     *at(start),
+			UpdateTime:    *at(start + time.Hour),
+			Score:         score,
+			Transitions:   []sessions.Transition{{To: sessions.StatusPending, Actor: sessions.ActorTrainer, At: *at(start)}},
+			Configuration: config,
+		}
+	}
+	// ran sets the active time of a session that ran from its start for minutes
+	ran := func(s *sessions.Session, minutes int, paused time.Duration) *sessions.Session {
+		started := s.StartTime.Add(time.Minute)
+		ended := started.Add(time.Duration(minutes)*time.Minute + paused)
+		s.StartedAt, s.EndTime, s.PausedTotal = &started, &ended, paused
+		return s
+	}
+
+	list := []*sessions.Session{
+		ran(session("session_a", sessions.StatusCompleted, 0, fireDrill, "trainee_1", "Sam", "station_1", intPtr(80)), 30, 0),
+		ran(session("session_b", sessions.StatusCompleted, 0, complaint, "trainee_2", "kim", "station_2", intPtr(80)), 30, 0),
+		session("session_c", sessions.StatusFailed, time.Hour, fireDrill, "", "", "station_1", nil),
+		ran(session("session_d", sessions.StatusCompleted, day, fireDrill, "trainee_2", "kim", "station_2", intPtr(95)), 40, 5*time.Minute),
+		session("session_e", sessions.StatusPending, day+time.Hour, complaint, "trainee_1", "Sam", "", nil),
+		ran(session("session_f", sessions.StatusCompleted, 2*day+17*time.Minute+3*time.Second, fireDrill, "trainee_1", "Sam", "station_1", intPtr(60)), 30, 0),
+		session("session_g", sessions.StatusAborted, 2*day+17*time.Minute+3*time.Second, complaint, "", "Walk-in", "station_1", nil),
+	}
+	paused := session("session_h", sessions.StatusPaused, 3*day, fireDrill, "trainee_2", "kim", "station_2", intPtr(80))
+	paused.StartedAt, paused.PausedAt = at(3*day+time.Minute), at(3*day+11*time.Minute)
+	list = append(list, paused)
+
+	return &Snapshot{
+		Scenarios: []scenarios.Scenario{{ID: "scenario_1", Name: "Fire drill"}, {ID: "scenario_2", Name: "angry customer"}},
+		Avatars:   []avatars.Avatar{{ID: "avatar_1", Name: "Alex"}},
+		Observers: []observers.Observer{{ID: "observer_1", Name: "Coach"}},
+		Trainees: []trainees.Trainee{
+			{ID: "trainee_1", Name: "Sam", EmployeeID: "E1"},
+			{ID: "trainee_2", Name: "Kim", EmployeeID: "E2"},
+		},
+		Sessions:        list,
+		GeneralSettings: DefaultGeneralSettings(),
+		Stations:        DefaultStations(),
+	}
+}
+
+// pageIDs describes a page by its total and the IDs of its sessions, in order
+func pageIDs(page sessions.Page) string {
+	ids := make([]string, len(page.Sessions))
+	for i, session := range page.Sessions {
+		ids[i] = strings.TrimPrefix(session.ID, "session_")
+	}
+	return fmt.Sprintf("%d: %s", page.Total, strings.Join(ids, " "))
+}
+
+func TestSessionQueryBackendsAgree(t *testing.T) {
+	snap := querySnapshot()
+	day := func(offset int) time.Time {
+		return time.Date(2026, 3, 2+offset, 0, 0, 0, 0, time.Local)
+	}
+	sorted := func(column string, desc bool) sessions.Query {
+		q := sessions.DefaultQuery()
+		q.Sort, q.Desc = column, desc
+		return q
+	}
+	filtered := func(change func(q *sessions.Query)) sessions.Query {
+		q := sessions.DefaultQuery()
+		change(&q)
+		return q
+	}
+	paged := func(q sessions.Query, page, size int) sessions.Query {
+		q.Page, q.PageSize = page, size
+		return q
+	}
+
+	// Ties are broken by creation time, newest first, and then by ID
+	tests := []struct {
+		name  string
+		query sessions.Query
+		want  string
+	}{
+		{"newest first", sessions.DefaultQuery(), "8: h g f e d c b a"},
+		{"oldest first", sorted(sessions.SortCreated, false), "8: b a c d e g f h"},
+		{"by ID", sorted(sessions.SortID, false), "8: a b c d e f g h"},
+		{"by score", sorted(sessions.SortScore, true), "8: d h b a f g e c"},
+		{"by score ascending", sorted(sessions.SortScore, false), "8: g e c f h b a d"},
+		{"by trainee", sorted(sessions.SortTrainee, false), "8: c h d b f e a g"},
+		{"by scenario", sorted(sessions.SortScenario, false), "8: g e b h f d c a"},
+		{"by station", sorted(sessions.SortStation, true), "8: h d b g f c a e"},
+		{"by status", sorted(sessions.SortStatus, false), "8: g f d b a c h e"},
+		{"by duration", sorted(sessions.SortDuration, true), "8: d f b a h g e c"},
+		{"by duration ascending", sorted(sessions.SortDuration, false), "8: g e c h f b a d"},
+		{"status", filtered(func(q *sessions.Query) { q.Status = sessions.StatusCompleted }), "4: f d b a"},
+		{"scenario", filtered(func(q *sessions.Query) { q.ScenarioID = "scenario_2" }), "3: g e b"},
+		{"trainee", filtered(func(q *sessions.Query) { q.TraineeID = "trainee_1" }), "3: f e a"},
+		{"trainee names do not match", filtered(func(q *sessions.Query) { q.TraineeID = "Walk-in" }), "0: "},
+		{"station", filtered(func(q *sessions.Query) { q.StationID = "station_1" }), "4: g f c a"},
+		{"one day", filtered(func(q *sessions.Query) { q.From, q.To = day(1), day(1) }), "2: e d"},
+		{"from a day on", filtered(func(q *sessions.Query) { q.From = day(2) }), "3: h g f"},
+		{"until a day", filtered(func(q *sessions.Query) { q.To = day(0) }), "3: c b a"},
+		{"first page", paged(sessions.DefaultQuery(), 1, 3), "8: h g f"},
+		{"middle page", paged(sessions.DefaultQuery(), 2, 3), "8: e d c"},
+		{"last page", paged(sessions.DefaultQuery(), 3, 3), "8: b a"},
+		{"past the last page", paged(sessions.DefaultQuery(), 4, 3), "8: "},
+		{"page through a tie", paged(sorted(sessions.SortScore, true), 3, 1), "8: b"},
+		{"filtered page", paged(filtered(func(q *sessions.Query) {
+			q.Status, q.Sort, q.Desc = sessions.StatusCompleted, sessions.SortScore, false
+		}), 2, 2), "4: a d"},
+	}
+
+	backends := map[string]*Stores{}
+	for _, storage := range []string{config.StorageMemory, config.StorageSQLite} {
+		stores, err := OpenStores(config.Config{DataDir: t.TempDir(), Storage: storage})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer stores.Close()
+		if err := stores.Restore(snap); err != nil {
+			t.Fatalf("restoring into %s: %v", storage, err)
+		}
+		backends[storage] = stores
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pageIDs(querySessions(snap.Sessions, tt.query)); got != tt.want {
+				t.Errorf("querySessions = %s, want %s", got, tt.want)
+			}
+			for storage, stores := range backends {
+				if got := pageIDs(stores.Sessions.Query(tt.query)); got != tt.want {
+					t.Errorf("%s Query = %s, want %s", storage, got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+// internal/models/session_test.go
+package models
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+)
+
+func newTestSession() sessions.Session {
+	return sessions.Session{
+		ScenarioID:    "scenario_1",
+		AvatarID:      "avatar_1",
+		ObserverID:    "observer_1",
+		Configuration: &sessions.Configuration{},
+	}
+}
+
+// breakDataDir replaces the directory holding path with a regular file, so every
+// later write to path fails, even when the tests run as root
+func breakDataDir(t *testing.T, path string) {
+	t.Helper()
+	dir := filepath.Dir(path)
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWriteFileAtomicKeepsBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+
+	if err := writeFileAtomic(path, []byte("first"), 0644); err != nil {
+		t.Fatalf("first write: %v", err)
+	}
+	if _, err := os.Stat(path + ".bak"); !os.IsNotExist(err) {
+		t.Errorf("backup kept before there was a previous version: %v", err)
+	}
+
+	if err := writeFileAtomic(path, []byte("second"), 0644); err != nil {
+		t.Fatalf("second write: %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "second" {
+		t.Errorf("file holds %q, want %q", got, "second")
+	}
+	if got, _ := os.ReadFile(path + ".bak"); string(got) != "first" {
+		t.Errorf("backup holds %q, want %q", got, "first")
+	}
+	if tmp, _ := filepath.Glob(path + ".*.tmp"); len(tmp) != 0 {
+		t.Errorf("temporary files left behind: %v", tmp)
+	}
+}
+
+func TestWriteFileAtomicFailureRemovesTempFile(t *testing.T) {
+	// A directory in the way of the rename makes the write fail after the temp file was written
+	path := filepath.Join(t.TempDir(), "data.json")
+	if err := os.MkdirAll(filepath.Join(path, "child"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFileAtomic(path, []byte("new"), 0644); err == nil {
+		t.Fatal("writing over a non-empty directory succeeded")
+	}
+	if tmp, _ := filepath.Glob(path + ".*.tmp"); len(tmp) != 0 {
+		t.Errorf("temporary files left behind: %v", tmp)
+	}
+}
+
+func TestSessionStoreReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.json")
+	store := NewSessionStore(path)
+
+	created, err := store.Create(newTestSession())
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	reloaded := NewSessionStore(path)
+	got, err := reloaded.GetByID(created.ID)
+	if err != nil {
+		t.Fatalf("session not persisted: %v", err)
+	}
+	if got.Status != sessions.StatusPending {
+		t.Errorf("status = %q, want %q", got.Status, sessions.StatusPending)
+	}
+}
+
+func TestSessionStoreRecoversFromBackup(t *testing.T) {
+	tests := []struct {
+		name   string
+		damage func(path string) error
+	}{
+		{"missing file", func(path string) error { return os.Remove(path) }},
+		{"corrupt file", func(path string) error { return os.WriteFile(path, []byte(`{"version": 1, "items": [{"id": `), 0644) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "sessions.json")
+			store := NewSessionStore(path)
+			first, err := store.Create(newTestSession())
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The second save keeps the first one as the backup
+			if _, err := store.Create(newTestSession()); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := tt.damage(path); err != nil {
+				t.Fatal(err)
+			}
+
+			recovered := NewSessionStore(path)
+			all := recovered.GetAll()
+			if len(all) != 1 || all[0].ID != first.ID {
+				t.Fatalf("recovered %d sessions, want only %s from the backup", len(all), first.ID)
+			}
+			if _, err := decodeSessions(mustReadFile(t, path)); err != nil {
+				t.Errorf("recovered sessions were not rewritten: %v", err)
+			}
+		})
+	}
+}
+
+func TestSessionStoreSalvagesCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.json")
+	store := NewSessionStore(path)
+	for i := 0; i < 3; i++ {
+		if _, err := store.Create(newTestSession()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Cut the file in the middle of the last session and drop the backup
+	data := mustReadFile(t, path)
+	last := bytes.LastIndex(data, []byte(`"id"`))
+	if err := os.WriteFile(path, data[:last+10], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(path + ".bak"); err != nil {
+		t.Fatal(err)
+	}
+
+	recovered := NewSessionStore(path)
+	if got := len(recovered.GetAll()); got != 2 {
+		t.Errorf("salvaged %d sessions, want 2", got)
+	}
+	corrupt, _ := filepath.Glob(path + ".corrupt-*")
+	if len(corrupt) != 1 {
+		t.Errorf("damaged file preserved %d times, want once", len(corrupt))
+	}
+}
+
+func TestSessionStoreCreateFailsWhenSaveFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "sessions.json")
+	store := NewSessionStore(path)
+	breakDataDir(t, path)
+
+	if _, err := store.Create(newTestSession()); err == nil {
+		t.Fatal("Create succeeded although the sessions could not be saved")
+	}
+	if got := len(store.GetAll()); got != 0 {
+		t.Errorf("store holds %d sessions after a failed create, want 0", got)
+	}
+}
+
+func TestSessionStoreTransitionFailsWhenSaveFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "sessions.json")
+	store := NewSessionStore(path)
+	created, err := store.Create(newTestSession())
+	if err != nil {
+		t.Fatal(err)
+	}
+	breakDataDir(t, path)
+
+	_, err = store.Transition(created.ID, sessions.Transition{To: sessions.StatusRunning, Actor: sessions.ActorTrainer})
+	if err == nil {
+		t.Fatal("Transition succeeded although the sessions could not be saved")
+	}
+	got, err := store.GetByID(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != sessions.StatusPending || len(got.Transitions) != 1 || got.StartedAt != nil {
+		t.Errorf("failed transition left status %q with %d transitions", got.Status, len(got.Transitions))
+	}
+}
+
+func TestSessionStoreDeleteFailsWhenSaveFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "sessions.json")
+	store := NewSessionStore(path)
+	created, err := store.Create(newTestSession())
+	if err != nil {
+		t.Fatal(err)
+	}
+	breakDataDir(t, path)
+
+	if err := store.Delete(created.ID); err == nil {
+		t.Fatal("Delete succeeded although the sessions could not be saved")
+	}
+	if _, err := store.GetByID(created.ID); errors.Is(err, ErrSessionNotFound) {
+		t.Error("failed delete removed the session")
+	}
+}
+
+func mustReadFile(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestSessionStoreReturnsCopies(t *testing.T) {
+	store := NewSessionStore(filepath.Join(t.TempDir(), "sessions.json"))
+	created, err := store.Create(newTestSession())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.GetByID(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got.Status = sessions.StatusAborted
+	got.Transitions[0].To = sessions.StatusAborted
+	got.Configuration.Scenario.Name = "changed"
+	store.GetAll()[0].Notes = "changed"
+
+	stored, _ := store.GetByID(created.ID)
+	if stored.Status != sessions.StatusPending || stored.Transitions[0].To != sessions.StatusPending ||
+		stored.Configuration.Scenario.Name == "changed" || stored.Notes != "" {
+		t.Errorf("changing returned sessions changed the store: %+v", stored)
+	}
+
+	// A session returned earlier does not move with later changes
+	if _, err := store.Transition(created.ID, sessions.Transition{To: sessions.StatusStarting, Actor: sessions.ActorSystem}); err != nil {
+		t.Fatal(err)
+	}
+	if created.Status != sessions.StatusPending || len(created.Transitions) != 1 {
+		t.Errorf("a transition changed a session returned before it: %s with %d transitions", created.Status, len(created.Transitions))
+	}
+}
+
+func TestSessionStoreRejectsIllegalTransitions(t *testing.T) {
+	tests := []struct {
+		name string
+		path []string // legal steps taken first
+		to   string
+	}{
+		{"pending to paused", nil, sessions.StatusPaused},
+		{"starting to paused", []string{sessions.StatusStarting}, sessions.StatusPaused},
+		{"running to starting", []string{sessions.StatusRunning}, sessions.StatusStarting},
+		{"paused to paused", []string{sessions.StatusRunning, sessions.StatusPaused}, sessions.StatusPaused},
+		{"completed to running", []string{sessions.StatusRunning, sessions.StatusCompleted}, sessions.StatusRunning},
+		{"failed to completed", []string{sessions.StatusFailed}, sessions.StatusCompleted},
+		{"aborted to pending", []string{sessions.StatusAborted}, sessions.StatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewSessionStore(filepath.Join(t.TempDir(), "sessions.json"))
+			created, err := store.Create(newTestSession())
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, status := range tt.path {
+				if _, err := store.Transition(created.ID, sessions.Transition{To: status, Actor: sessions.ActorSystem}); err != nil {
+					t.Fatalf("legal step to %s: %v", status, err)
+				}
+			}
+			before, _ := store.GetByID(created.ID)
+
+			_, err = store.Transition(created.ID, sessions.Transition{To: tt.to, Actor: sessions.ActorTrainer})
+			if !errors.Is(err, ErrIllegalTransition) {
+				t.Fatalf("Transition to %s = %v, want ErrIllegalTransition", tt.to, err)
+			}
+			after, _ := store.GetByID(created.ID)
+			if after.Status != before.Status || len(after.Transitions) != len(before.Transitions) {
+				t.Errorf("a rejected transition changed the session to %s with %d transitions", after.Status, len(after.Transitions))
+			}
+		})
+	}
+}
+
+func TestSessionStoreConcurrentReadsAndTransitions(t *testing.T) {
+	store := NewSessionStore(filepath.Join(t.TempDir(), "sessions.json"))
+	created, err := store.Create(newTestSession())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Transition(created.ID, sessions.Transition{To: sessions.StatusRunning, Actor: sessions.ActorSystem}); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			to := sessions.StatusPaused
+			if i%2 == 1 {
+				to = sessions.StatusRunning
+			}
+			if _, err := store.Transition(created.ID, sessions.Transition{To: to, Actor: sessions.ActorTrainer}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	// Run with -race: readers must never see a session while it is being changed
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		for _, session := range store.GetAll() {
+			_ = session.GetDuration()
+			_ = len(session.Transitions)
+		}
+		if session, err := store.GetByID(created.ID); err == nil {
+			_ = session.Status
+		}
+	}
+}
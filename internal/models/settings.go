@@ -2,8 +2,9 @@
 package models
 
 import (
-	"encoding/json"
-	"io/ioutil"
+	"errors"
+	"log"
+	"net/url"
 	"os"
 	"sync"
 
@@ -14,6 +15,7 @@ import (
 type SettingsStore struct {
 	llmSettings     settings.LLMSettings
 	generalSettings settings.GeneralSettings
+	stations        []settings.Station
 	filePath        string
 	mu              sync.RWMutex
 }
@@ -21,30 +23,10 @@ type SettingsStore struct {
 // NewSettingsStore creates a new settings store with default values
 func NewSettingsStore(filePath string) *SettingsStore {
 	store := &SettingsStore{
-		llmSettings: settings.LLMSettings{
-			ID:                "default",
-			Provider:          "Google Vertex AI",
-			Model:             "gemini-pro",
-			MaxTokens:         1024,
-			Temperature:       0.7,
-			TopP:              0.95,
-			FrequencyPenalty:  0.0,
-			PresencePenalty:   0.0,
-			ProjectID:         "",
-			Location:          "us-central1",
-			Endpoint:          "",
-			ServiceAccountKey: "",
-		},
-		generalSettings: settings.GeneralSettings{
-			ApplicationName:       "VR Training Admin",
-			LogLevel:              "INFO",
-			MaxConcurrentSessions: 10,
-			SessionTimeout:        60,
-			RecordSessions:        true,
-			StoreSessionData:      true,
-			DataRetentionDays:     90,
-		},
-		filePath: filePath,
+		llmSettings:     DefaultLLMSettings(),
+		generalSettings: DefaultGeneralSettings(),
+		stations:        DefaultStations(),
+		filePath:        filePath,
 	}
 
 	// Load existing settings if file exists
@@ -58,6 +40,54 @@ func NewSettingsStore(filePath string) *SettingsStore {
 	return store
 }
 
+// DefaultLLMSettings returns the LLM settings used before anything is configured
+func DefaultLLMSettings() settings.LLMSettings {
+	return settings.LLMSettings{
+		ID:                "default",
+		Provider:          "Google Vertex AI",
+		Model:             "gemini-pro",
+		MaxTokens:         1024,
+		Temperature:       0.7,
+		TopP:              0.95,
+		FrequencyPenalty:  0.0,
+		PresencePenalty:   0.0,
+		ProjectID:         "",
+		Location:          "us-central1",
+		Endpoint:          "",
+		ServiceAccountKey: "",
+	}
+}
+
+// DefaultGeneralSettings returns the general settings used before anything is configured
+func DefaultGeneralSettings() settings.GeneralSettings {
+	return settings.GeneralSettings{
+		ApplicationName:       "VR Training Admin",
+		LogLevel:              "INFO",
+		MaxConcurrentSessions: 10,
+		OverLimitAction:       settings.OverLimitReject,
+		SessionTimeout:        60,
+		TimeoutAction:         settings.TimeoutFail,
+		RecordSessions:        true,
+		StoreSessionData:      true,
+		DataRetentionDays:     90,
+		RetentionAction:       settings.RetentionDelete,
+		PracticeIntervalDays:  30,
+	}
+}
+
+// DefaultStationEndpoint is the endpoint of the station configured out of the box
+const DefaultStationEndpoint = "http://localhost:8081/api/vr-session"
+
+// DefaultStations returns the VR stations used before any are configured
+func DefaultStations() []settings.Station {
+	return []settings.Station{{
+		ID:       "station_default",
+		Name:     "Station 1",
+		Endpoint: DefaultStationEndpoint,
+		Enabled:  true,
+	}}
+}
+
 // GetLLMSettings returns the current LLM settings
 func (s *SettingsStore) GetLLMSettings() settings.LLMSettings {
 	s.mu.RLock()
@@ -90,15 +120,125 @@ func (s *SettingsStore) UpdateGeneralSettings(newSettings settings.GeneralSettin
 	return s.saveToFile()
 }
 
-// TestConnection simulates testing a connection to the LLM API
-func (s *SettingsStore) TestConnection(prompt string) (bool, string, string) {
-	// In a real implementation, this would make an actual API call
-	// For now, we'll simulate a successful connection
+// GetStations returns the configured VR stations
+func (s *SettingsStore) GetStations() []settings.Station {
 	s.mu.RLock()
-	provider := s.llmSettings.Provider
-	s.mu.RUnlock()
+	defer s.mu.RUnlock()
+
+	result := make([]settings.Station, len(s.stations))
+	copy(result, s.stations)
+	return result
+}
+
+// UpdateStations replaces the configured VR stations
+func (s *SettingsStore) UpdateStations(stations []settings.Station) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stations = stations
+	return s.saveToFile()
+}
+
+// ChangeStations replaces the configured VR stations with what change makes of them,
+// holding the store lock so concurrent changes cannot overwrite each other
+func (s *SettingsStore) ChangeStations(change func(stations []settings.Station) ([]settings.Station, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := make([]settings.Station, len(s.stations))
+	copy(current, s.stations)
+	changed, err := change(current)
+	if err != nil {
+		return err
+	}
+
+	previous := s.stations
+	s.stations = changed
+	if err := s.saveToFile(); err != nil {
+		s.stations = previous
+		return err
+	}
+	return nil
+}
 
-	if provider == "" || prompt == "" {
+// replaceAll swaps every settings section in memory; Stores.Restore writes the file
+func (s *SettingsStore) replaceAll(llmSettings settings.LLMSettings, generalSettings settings.GeneralSettings, stations []settings.Station) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.llmSettings = llmSettings
+	s.generalSettings = generalSettings
+	s.stations = stations
+	return nil
+}
+
+var (
+	ErrStationNotFound = errors.New("station not found")
+	ErrInvalidStation  = errors.New("a station needs a name and an http(s) endpoint URL")
+)
+
+// FindStation returns the station with the given ID
+func FindStation(settingsStore SettingsRepository, id string) (settings.Station, error) {
+	for _, station := range settingsStore.GetStations() {
+		if station.ID == id {
+			return station, nil
+		}
+	}
+	return settings.Station{}, ErrStationNotFound
+}
+
+// SaveStation adds station when its ID is empty and replaces the station with its ID otherwise.
+// With keepToken set, a replaced station keeps its saved auth token.
+func SaveStation(settingsStore SettingsRepository, station settings.Station, keepToken bool) (settings.Station, error) {
+	if station.Name == "" || !validStationEndpoint(station.Endpoint) {
+		return station, ErrInvalidStation
+	}
+
+	if station.ID == "" {
+		station.ID = newTimestampID("station_")
+		err := settingsStore.ChangeStations(func(stations []settings.Station) ([]settings.Station, error) {
+			return append(stations, station), nil
+		})
+		return station, err
+	}
+
+	err := settingsStore.ChangeStations(func(stations []settings.Station) ([]settings.Station, error) {
+		for i := range stations {
+			if stations[i].ID == station.ID {
+				if keepToken {
+					station.AuthToken = stations[i].AuthToken
+				}
+				stations[i] = station
+				return stations, nil
+			}
+		}
+		return nil, ErrStationNotFound
+	})
+	return station, err
+}
+
+// DeleteStation removes a station. Sessions keep referring to it by ID.
+func DeleteStation(settingsStore SettingsRepository, id string) error {
+	return settingsStore.ChangeStations(func(stations []settings.Station) ([]settings.Station, error) {
+		for i := range stations {
+			if stations[i].ID == id {
+				return append(stations[:i], stations[i+1:]...), nil
+			}
+		}
+		return nil, ErrStationNotFound
+	})
+}
+
+func validStationEndpoint(endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// TestLLMConnection simulates testing a connection to the LLM API
+func TestLLMConnection(llmSettings settings.LLMSettings, prompt string) (bool, string, string) {
+	// In a real implementation, this would make an actual API call
+	// For now, we'll simulate a successful connection
+	if llmSettings.Provider == "" || prompt == "" {
 		return false, "Invalid configuration. Please check your settings.", ""
 	}
 
@@ -112,38 +252,35 @@ func (s *SettingsStore) TestConnection(prompt string) (bool, string, string) {
 
 // Combined settings for storage
 type combinedSettings struct {
-	LLM     settings.LLMSettings     `json:"llm"`
-	General settings.GeneralSettings `json:"general"`
+	Version  int                      `json:"version"`
+	LLM      settings.LLMSettings     `json:"llm"`
+	General  settings.GeneralSettings `json:"general"`
+	Stations []settings.Station       `json:"stations"`
 }
 
-// loadFromFile loads settings from the JSON file
+// loadFromFile loads settings from the JSON file, migrating older layouts first
 func (s *SettingsStore) loadFromFile() error {
-	data, err := ioutil.ReadFile(s.filePath)
-	if err != nil {
-		return err
-	}
-
-	var combined combinedSettings
-	if err := json.Unmarshal(data, &combined); err != nil {
+	// General settings added since the file was written keep their defaults
+	combined := combinedSettings{General: DefaultGeneralSettings()}
+	if _, err := readDocument(DocumentSettings, s.filePath, &combined); err != nil {
+		log.Printf("Error loading settings: %v", err)
 		return err
 	}
 
 	s.llmSettings = combined.LLM
 	s.generalSettings = combined.General
+	s.stations = combined.Stations
 	return nil
 }
 
 // saveToFile saves settings to the JSON file
 func (s *SettingsStore) saveToFile() error {
 	combined := combinedSettings{
-		LLM:     s.llmSettings,
-		General: s.generalSettings,
-	}
-
-	data, err := json.MarshalIndent(combined, "", "  ")
-	if err != nil {
-		return err
+		Version:  CurrentSchemaVersion,
+		LLM:      s.llmSettings,
+		General:  s.generalSettings,
+		Stations: s.stations,
 	}
 
-	return ioutil.WriteFile(s.filePath, data, 0644)
+	return writeJSONFile(s.filePath, combined)
 }
@@ -0,0 +1,70 @@
+// internal/models/settings_test.go
+package models
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/settings"
+)
+
+func TestSaveStationKeepsToken(t *testing.T) {
+	store := NewSettingsStore(filepath.Join(t.TempDir(), "settings.json"))
+	station, err := SaveStation(store, settings.Station{Name: "Headset", Endpoint: "http://10.0.0.5/api", AuthToken: "secret"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		token     string
+		keepToken bool
+		want      string
+	}{
+		{"blank keeps the saved token", "", true, "secret"},
+		{"new token replaces it", "rotated", false, "rotated"},
+		{"cleared", "", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edit := station
+			edit.AuthToken = tt.token
+			if _, err := SaveStation(store, edit, tt.keepToken); err != nil {
+				t.Fatal(err)
+			}
+			saved, err := FindStation(store, station.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if saved.AuthToken != tt.want {
+				t.Errorf("token = %q, want %q", saved.AuthToken, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveStationConcurrentAdds(t *testing.T) {
+	store := NewSettingsStore(filepath.Join(t.TempDir(), "settings.json"))
+	before := len(store.GetStations())
+
+	const adds = 20
+	var wg sync.WaitGroup
+	for i := 0; i < adds; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			station := settings.Station{Name: fmt.Sprintf("Headset %d", i), Endpoint: "http://10.0.0.5/api"}
+			if _, err := SaveStation(store, station, false); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got := len(store.GetStations()); got != before+adds {
+		t.Errorf("%d stations after %d concurrent adds, want %d", got, adds, before+adds)
+	}
+}
@@ -0,0 +1,514 @@
+// internal/models/snapshot.go
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/avatars"
+	"github.com/saladinomario/vr-training-admin/templates/components/observers"
+	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+	"github.com/saladinomario/vr-training-admin/templates/components/settings"
+	"github.com/saladinomario/vr-training-admin/templates/components/trainees"
+)
+
+// Snapshot is a complete copy of the admin's data, used for backup and restore
+type Snapshot struct {
+	Scenarios         []scenarios.Scenario
+	ScenarioRevisions []scenarios.Revision
+	Avatars           []avatars.Avatar
+	Observers         []observers.Observer
+	Trainees          []trainees.Trainee
+	Sessions          []*sessions.Session
+	Events            []sessions.Event
+	LLMSettings       settings.LLMSettings
+	GeneralSettings   settings.GeneralSettings
+	Stations          []settings.Station
+
+	// SecretsOmitted is set when the LLM credentials and station auth tokens were left out
+	SecretsOmitted bool
+}
+
+// DocumentKinds lists the documents that make up a snapshot
+func DocumentKinds() []string {
+	return []string{DocumentScenarios, DocumentScenarioRevisions, DocumentAvatars, DocumentObservers, DocumentTrainees, DocumentSessions, DocumentSessionEvents, DocumentSettings}
+}
+
+// DocumentOptional reports whether a snapshot may lack the document of kind.
+// Documents added after the first backup format are optional so older backups stay usable.
+func DocumentOptional(kind string) bool {
+	return kind == DocumentScenarioRevisions || kind == DocumentSessionEvents || kind == DocumentTrainees
+}
+
+// DocumentFileName returns the file name a document kind is stored under
+func DocumentFileName(kind string) string {
+	return documentFiles[kind]
+}
+
+// Replacers are implemented by the in-memory and file backed stores to swap their whole
+// content in memory. Restore writes the files of the file backed stores itself.
+type (
+	scenarioReplacer interface {
+		replaceAll([]scenarios.Scenario) error
+	}
+	revisionReplacer interface {
+		replaceAll([]scenarios.Revision) error
+	}
+	avatarReplacer interface {
+		replaceAll([]avatars.Avatar) error
+	}
+	observerReplacer interface {
+		replaceAll([]observers.Observer) error
+	}
+	traineeReplacer interface {
+		replaceAll([]trainees.Trainee) error
+	}
+	sessionReplacer interface {
+		replaceAll([]*sessions.Session) error
+	}
+	eventReplacer interface {
+		replaceAll([]sessions.Event) error
+	}
+	settingsReplacer interface {
+		replaceAll(settings.LLMSettings, settings.GeneralSettings, []settings.Station) error
+	}
+	outboxReplacer interface {
+		replaceAll([]Delivery) error
+	}
+)
+
+// replacers holds the stores of a non-SQLite backend as replacers
+type replacers struct {
+	scenarios scenarioReplacer
+	revisions revisionReplacer
+	avatars   avatarReplacer
+	observers observerReplacer
+	trainees  traineeReplacer
+	sessions  sessionReplacer
+	events    eventReplacer
+	settings  settingsReplacer
+	outbox    outboxReplacer
+}
+
+// replacers returns the stores of s as replacers, or false when one of them cannot be replaced
+func (s *Stores) replacers() (replacers, bool) {
+	var (
+		r  replacers
+		ok [9]bool
+	)
+	r.scenarios, ok[0] = s.Scenarios.(scenarioReplacer)
+	r.revisions, ok[1] = s.ScenarioRevisions.(revisionReplacer)
+	r.avatars, ok[2] = s.Avatars.(avatarReplacer)
+	r.observers, ok[3] = s.Observers.(observerReplacer)
+	r.trainees, ok[4] = s.Trainees.(traineeReplacer)
+	r.sessions, ok[5] = s.Sessions.(sessionReplacer)
+	r.events, ok[6] = s.Events.(eventReplacer)
+	r.settings, ok[7] = s.Settings.(settingsReplacer)
+	r.outbox, ok[8] = s.Outbox.(outboxReplacer)
+	for _, replaceable := range ok {
+		if !replaceable {
+			return r, false
+		}
+	}
+	return r, true
+}
+
+// replaceAll swaps the in-memory content of every store for snap and outbox
+func (r replacers) replaceAll(snap *Snapshot, outbox []Delivery) {
+	r.scenarios.replaceAll(snap.Scenarios)
+	r.revisions.replaceAll(snap.ScenarioRevisions)
+	r.avatars.replaceAll(snap.Avatars)
+	r.observers.replaceAll(snap.Observers)
+	r.trainees.replaceAll(snap.Trainees)
+	r.sessions.replaceAll(snap.Sessions)
+	r.events.replaceAll(snap.Events)
+	r.settings.replaceAll(snap.LLMSettings, snap.GeneralSettings, snap.Stations)
+	r.outbox.replaceAll(outbox)
+}
+
+// Snapshot copies the current content of every store
+func (s *Stores) Snapshot() *Snapshot {
+	snap := &Snapshot{
+		Scenarios:         s.Scenarios.GetAll(),
+		ScenarioRevisions: s.ScenarioRevisions.GetAll(),
+		Avatars:           s.Avatars.GetAll(),
+		Observers:         s.Observers.GetAll(),
+		Trainees:          s.Trainees.GetAll(),
+		Sessions:          s.Sessions.GetAll(),
+		Events:            s.Events.GetAll(),
+		LLMSettings:       s.Settings.GetLLMSettings(),
+		GeneralSettings:   s.Settings.GetGeneralSettings(),
+		Stations:          s.Settings.GetStations(),
+	}
+
+	sort.Slice(snap.Scenarios, func(i, j int) bool { return snap.Scenarios[i].ID < snap.Scenarios[j].ID })
+	sort.Slice(snap.Avatars, func(i, j int) bool { return snap.Avatars[i].ID < snap.Avatars[j].ID })
+	sort.Slice(snap.Observers, func(i, j int) bool { return snap.Observers[i].ID < snap.Observers[j].ID })
+	sort.Slice(snap.Trainees, func(i, j int) bool { return snap.Trainees[i].ID < snap.Trainees[j].ID })
+	return snap
+}
+
+// WithoutSecrets returns a copy of snap without the LLM credentials and station auth tokens,
+// so that an archive of it can be handed out without granting access to those services
+func (snap *Snapshot) WithoutSecrets() *Snapshot {
+	redacted := *snap
+	redacted.LLMSettings.APIKey = ""
+	redacted.LLMSettings.ServiceAccountKey = ""
+	redacted.Stations = make([]settings.Station, len(snap.Stations))
+	for i, station := range snap.Stations {
+		station.AuthToken = ""
+		redacted.Stations[i] = station
+	}
+	redacted.SecretsOmitted = true
+	return &redacted
+}
+
+// KeepSecrets fills in the secrets an archive without them lacks from current: the LLM
+// credentials and the auth token of every station current has under the same ID
+func (snap *Snapshot) KeepSecrets(current *Snapshot) {
+	if snap.LLMSettings.APIKey == "" {
+		snap.LLMSettings.APIKey = current.LLMSettings.APIKey
+	}
+	if snap.LLMSettings.ServiceAccountKey == "" {
+		snap.LLMSettings.ServiceAccountKey = current.LLMSettings.ServiceAccountKey
+	}
+
+	tokens := make(map[string]string, len(current.Stations))
+	for _, station := range current.Stations {
+		tokens[station.ID] = station.AuthToken
+	}
+	for i := range snap.Stations {
+		if snap.Stations[i].AuthToken == "" {
+			snap.Stations[i].AuthToken = tokens[snap.Stations[i].ID]
+		}
+	}
+}
+
+// Restore replaces the content of every store with snap and drops the pending Unreal Engine
+// deliveries, which belong to the sessions being replaced. The SQLite backend does this in a
+// single transaction. The other backends first write every file next to the one it replaces
+// and only then move them all into place, so a failure leaves the previous data untouched.
+func (s *Stores) Restore(snap *Snapshot) error {
+	if err := snap.Validate(); err != nil {
+		return err
+	}
+
+	if s.db != nil {
+		return restoreSQLite(s.db, snap)
+	}
+
+	stores, ok := s.replacers()
+	if !ok {
+		return fmt.Errorf("storage backend does not support restore")
+	}
+
+	staged, err := s.stageFiles(snap)
+	if err != nil {
+		return err
+	}
+
+	previous, previousOutbox := s.Snapshot(), s.Outbox.GetAll()
+	stores.replaceAll(snap, nil)
+	if err := swapStagedFiles(staged); err != nil {
+		stores.replaceAll(previous, previousOutbox)
+		return err
+	}
+	return nil
+}
+
+// stagedFile is a document written next to the file it replaces
+type stagedFile struct {
+	path   string
+	staged string
+	// existed records whether there was a file to replace when the staged file was moved
+	existed bool
+}
+
+// stageFiles writes the documents of snap, and an empty outbox, next to the files of the
+// stores that persist to one. Nothing is left behind when a write fails.
+func (s *Stores) stageFiles(snap *Snapshot) ([]stagedFile, error) {
+	kinds := make([]string, 0, len(s.files))
+	for kind := range s.files {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	staged := make([]stagedFile, 0, len(kinds))
+	for _, kind := range kinds {
+		var (
+			data []byte
+			err  error
+		)
+		if kind == DocumentOutbox {
+			data, err = json.MarshalIndent(newListDocument([]Delivery{}), "", "  ")
+		} else {
+			data, _, err = snap.EncodeDocument(kind)
+		}
+
+		file := stagedFile{path: s.files[kind], staged: s.files[kind] + ".restore"}
+		if err == nil {
+			os.Remove(file.staged)
+			err = writeFileAtomic(file.staged, data, 0644)
+		}
+		if err != nil {
+			removeStagedFiles(staged)
+			return nil, fmt.Errorf("staging %s: %w", kind, err)
+		}
+		staged = append(staged, file)
+	}
+	return staged, nil
+}
+
+// swapStagedFiles moves every staged file into place, keeping the previous version as
+// the .bak of the normal saves. When a move fails the files already moved are put back.
+func swapStagedFiles(staged []stagedFile) error {
+	for i := range staged {
+		if err := swapStagedFile(&staged[i]); err != nil {
+			for _, done := range staged[:i] {
+				putBack(done)
+			}
+			removeStagedFiles(staged[i:])
+			return fmt.Errorf("replacing %s: %w", staged[i].path, err)
+		}
+	}
+	return nil
+}
+
+// swapStagedFile keeps the current file as its backup and renames the staged file over it
+func swapStagedFile(file *stagedFile) error {
+	backup := file.path + ".bak"
+	os.Remove(backup)
+	err := os.Link(file.path, backup)
+	file.existed = err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Rename(file.staged, file.path); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(file.path))
+}
+
+// putBack undoes swapStagedFile
+func putBack(file stagedFile) {
+	var err error
+	if file.existed {
+		err = os.Rename(file.path+".bak", file.path)
+	} else {
+		err = os.Remove(file.path)
+	}
+	if err != nil {
+		log.Printf("Error putting back %s: %v", file.path, err)
+	}
+}
+
+// removeStagedFiles deletes staged files that were not moved into place
+func removeStagedFiles(staged []stagedFile) {
+	for _, file := range staged {
+		os.Remove(file.staged)
+	}
+}
+
+// Validate checks that every item has a unique, non-empty ID and the data the stores require,
+// and that sessions and events refer to items of the snapshot
+func (snap *Snapshot) Validate() error {
+	if err := checkIDs("scenario", len(snap.Scenarios), func(i int) (string, string) {
+		return snap.Scenarios[i].ID, snap.Scenarios[i].Name
+	}); err != nil {
+		return err
+	}
+	if err := checkIDs("scenario revision", len(snap.ScenarioRevisions), func(i int) (string, string) {
+		return snap.ScenarioRevisions[i].ID, snap.ScenarioRevisions[i].ScenarioID
+	}); err != nil {
+		return err
+	}
+	if err := checkIDs("avatar", len(snap.Avatars), func(i int) (string, string) {
+		return snap.Avatars[i].ID, snap.Avatars[i].Name
+	}); err != nil {
+		return err
+	}
+	if err := checkIDs("observer", len(snap.Observers), func(i int) (string, string) {
+		return snap.Observers[i].ID, snap.Observers[i].Name
+	}); err != nil {
+		return err
+	}
+	if err := checkIDs("trainee", len(snap.Trainees), func(i int) (string, string) {
+		return snap.Trainees[i].ID, snap.Trainees[i].Name
+	}); err != nil {
+		return err
+	}
+	if err := checkIDs("session", len(snap.Sessions), func(i int) (string, string) {
+		return snap.Sessions[i].ID, snap.Sessions[i].Status
+	}); err != nil {
+		return err
+	}
+	if err := checkIDs("session event", len(snap.Events), func(i int) (string, string) {
+		return snap.Events[i].ID, snap.Events[i].SessionID
+	}); err != nil {
+		return err
+	}
+	return snap.checkReferences()
+}
+
+// checkReferences validates that sessions and their events refer to items of the snapshot.
+// Sessions carrying the configuration they were started with do not need the scenario,
+// avatar and observer, which the configuration holds; trainees are needed by all sessions.
+func (snap *Snapshot) checkReferences() error {
+	scenarioIDs := make(map[string]bool, len(snap.Scenarios))
+	for _, scenario := range snap.Scenarios {
+		scenarioIDs[scenario.ID] = true
+	}
+	avatarIDs := make(map[string]bool, len(snap.Avatars))
+	for _, avatar := range snap.Avatars {
+		avatarIDs[avatar.ID] = true
+	}
+	observerIDs := make(map[string]bool, len(snap.Observers))
+	for _, observer := range snap.Observers {
+		observerIDs[observer.ID] = true
+	}
+	traineeIDs := make(map[string]bool, len(snap.Trainees))
+	for _, trainee := range snap.Trainees {
+		traineeIDs[trainee.ID] = true
+	}
+
+	sessionIDs := make(map[string]bool, len(snap.Sessions))
+	for _, session := range snap.Sessions {
+		sessionIDs[session.ID] = true
+		if session.TraineeID != "" && !traineeIDs[session.TraineeID] {
+			return fmt.Errorf("session %q refers to missing trainee %q", session.ID, session.TraineeID)
+		}
+		if session.Configuration != nil {
+			continue
+		}
+		if !scenarioIDs[session.ScenarioID] {
+			return fmt.Errorf("session %q refers to missing scenario %q", session.ID, session.ScenarioID)
+		}
+		if !avatarIDs[session.AvatarID] {
+			return fmt.Errorf("session %q refers to missing avatar %q", session.ID, session.AvatarID)
+		}
+		if !observerIDs[session.ObserverID] {
+			return fmt.Errorf("session %q refers to missing observer %q", session.ID, session.ObserverID)
+		}
+	}
+	for _, event := range snap.Events {
+		if !sessionIDs[event.SessionID] {
+			return fmt.Errorf("session event %q refers to missing session %q", event.ID, event.SessionID)
+		}
+	}
+	return nil
+}
+
+// checkIDs validates n items of kind; get returns the ID and a field that must not be empty
+func checkIDs(kind string, n int, get func(i int) (string, string)) error {
+	seen := make(map[string]bool, n)
+	for i := 0; i < n; i++ {
+		id, required := get(i)
+		if id == "" {
+			return fmt.Errorf("%s #%d has no ID", kind, i+1)
+		}
+		if seen[id] {
+			return fmt.Errorf("duplicate %s ID %q", kind, id)
+		}
+		if required == "" {
+			return fmt.Errorf("%s %q is incomplete", kind, id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// EncodeDocument renders one document of the snapshot in the versioned on-disk format.
+// It also returns the number of items in the document. The settings document holds the
+// secrets of snap, if any; see WithoutSecrets.
+func (snap *Snapshot) EncodeDocument(kind string) ([]byte, int, error) {
+	var (
+		doc   interface{}
+		count int
+	)
+	switch kind {
+	case DocumentScenarios:
+		doc, count = newListDocument(snap.Scenarios), len(snap.Scenarios)
+	case DocumentScenarioRevisions:
+		doc, count = newListDocument(snap.ScenarioRevisions), len(snap.ScenarioRevisions)
+	case DocumentAvatars:
+		doc, count = newListDocument(snap.Avatars), len(snap.Avatars)
+	case DocumentObservers:
+		doc, count = newListDocument(snap.Observers), len(snap.Observers)
+	case DocumentTrainees:
+		doc, count = newListDocument(snap.Trainees), len(snap.Trainees)
+	case DocumentSessions:
+		doc, count = newListDocument(snap.Sessions), len(snap.Sessions)
+	case DocumentSessionEvents:
+		doc, count = newListDocument(snap.Events), len(snap.Events)
+	case DocumentSettings:
+		doc, count = combinedSettings{
+			Version:  CurrentSchemaVersion,
+			LLM:      snap.LLMSettings,
+			General:  snap.GeneralSettings,
+			Stations: snap.Stations,
+		}, 1
+	default:
+		return nil, 0, fmt.Errorf("unknown document kind %q", kind)
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	return data, count, err
+}
+
+// DecodeDocument parses one document into the snapshot, upgrading older versions first
+func (snap *Snapshot) DecodeDocument(kind string, data []byte) error {
+	raw, _, err := upgradeDocument(kind, data)
+	if err != nil {
+		return err
+	}
+	upgraded, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case DocumentScenarios:
+		var doc listDocument[scenarios.Scenario]
+		err = json.Unmarshal(upgraded, &doc)
+		snap.Scenarios = doc.Items
+	case DocumentScenarioRevisions:
+		var doc listDocument[scenarios.Revision]
+		err = json.Unmarshal(upgraded, &doc)
+		snap.ScenarioRevisions = doc.Items
+	case DocumentAvatars:
+		var doc listDocument[avatars.Avatar]
+		err = json.Unmarshal(upgraded, &doc)
+		snap.Avatars = doc.Items
+	case DocumentObservers:
+		var doc listDocument[observers.Observer]
+		err = json.Unmarshal(upgraded, &doc)
+		snap.Observers = doc.Items
+	case DocumentTrainees:
+		var doc listDocument[trainees.Trainee]
+		err = json.Unmarshal(upgraded, &doc)
+		snap.Trainees = doc.Items
+	case DocumentSessions:
+		var doc listDocument[*sessions.Session]
+		err = json.Unmarshal(upgraded, &doc)
+		snap.Sessions = doc.Items
+	case DocumentSessionEvents:
+		var doc listDocument[sessions.Event]
+		err = json.Unmarshal(upgraded, &doc)
+		snap.Events = doc.Items
+	case DocumentSettings:
+		// General settings added since the backup was taken keep their defaults
+		doc := combinedSettings{General: DefaultGeneralSettings()}
+		err = json.Unmarshal(upgraded, &doc)
+		snap.LLMSettings = doc.LLM
+		snap.GeneralSettings = doc.General
+		snap.Stations = doc.Stations
+	default:
+		return fmt.Errorf("unknown document kind %q", kind)
+	}
+	return err
+}
@@ -0,0 +1,216 @@
+// internal/models/snapshot_test.go
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/saladinomario/vr-training-admin/internal/config"
+	"github.com/saladinomario/vr-training-admin/templates/components/avatars"
+	"github.com/saladinomario/vr-training-admin/templates/components/observers"
+	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+	"github.com/saladinomario/vr-training-admin/templates/components/trainees"
+)
+
+// testSnapshot returns a consistent snapshot with one item of every kind
+func testSnapshot() *Snapshot {
+	return &Snapshot{
+		Scenarios: []scenarios.Scenario{{ID: "scenario_1", Name: "Fire drill"}},
+		Avatars:   []avatars.Avatar{{ID: "avatar_1", Name: "Alex"}},
+		Observers: []observers.Observer{{ID: "observer_1", Name: "Coach"}},
+		Trainees:  []trainees.Trainee{{ID: "trainee_1", Name: "Sam"}},
+		Sessions: []*sessions.Session{{
+			ID:         "session_1",
+			ScenarioID: "scenario_1",
+			AvatarID:   "avatar_1",
+			ObserverID: "observer_1",
+			TraineeID:  "trainee_1",
+			Status:     sessions.StatusCompleted,
+		}},
+		Events:          []sessions.Event{{ID: "event_1", SessionID: "session_1", Type: sessions.EventCompleted}},
+		GeneralSettings: DefaultGeneralSettings(),
+		Stations:        DefaultStations(),
+	}
+}
+
+func openFileStores(t *testing.T) (*Stores, string) {
+	t.Helper()
+	cfg := config.Config{DataDir: t.TempDir(), Storage: config.StorageFile}
+	stores, err := OpenStores(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stores, cfg.DataDir
+}
+
+func TestSnapshotValidateReferences(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(snap *Snapshot)
+		wantErr string
+	}{
+		{"consistent", func(snap *Snapshot) {}, ""},
+		{"missing trainee", func(snap *Snapshot) { snap.Trainees = nil }, "missing trainee"},
+		{"missing scenario", func(snap *Snapshot) { snap.Scenarios = nil }, "missing scenario"},
+		{"missing avatar", func(snap *Snapshot) { snap.Avatars = nil }, "missing avatar"},
+		{"missing observer", func(snap *Snapshot) { snap.Observers = nil }, "missing observer"},
+		{"event of missing session", func(snap *Snapshot) { snap.Events[0].SessionID = "session_2" }, "missing session"},
+		{"configuration replaces content", func(snap *Snapshot) {
+			snap.Sessions[0].Configuration = &sessions.Configuration{}
+			snap.Scenarios, snap.Avatars, snap.Observers = nil, nil, nil
+		}, ""},
+		{"anonymized session", func(snap *Snapshot) {
+			snap.Sessions[0].TraineeID = ""
+			snap.Trainees = nil
+		}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snap := testSnapshot()
+			tt.change(snap)
+			err := snap.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want no error", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want an error about %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRestoreReplacesFilesAndClearsOutbox(t *testing.T) {
+	stores, dir := openFileStores(t)
+	if _, err := stores.Outbox.Add(Delivery{SessionID: "session_old", Payload: []byte(`{}`)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := stores.Restore(testSnapshot()); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if got := len(stores.Outbox.GetAll()); got != 0 {
+		t.Errorf("outbox holds %d deliveries after restore, want 0", got)
+	}
+	if _, err := stores.Sessions.GetByID("session_1"); err != nil {
+		t.Errorf("restored session missing: %v", err)
+	}
+	if staged, _ := filepath.Glob(filepath.Join(dir, "*.restore")); len(staged) != 0 {
+		t.Errorf("staged files left behind: %v", staged)
+	}
+
+	// The restored data must be what the next start loads
+	reopened, err := OpenStores(config.Config{DataDir: dir, Storage: config.StorageFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reopened.Snapshot(); len(got.Sessions) != 1 || len(got.Trainees) != 1 || len(got.Events) != 1 {
+		t.Errorf("reloaded %d sessions, %d trainees and %d events, want one of each",
+			len(got.Sessions), len(got.Trainees), len(got.Events))
+	}
+	if got := len(reopened.Outbox.GetAll()); got != 0 {
+		t.Errorf("reloaded outbox holds %d deliveries, want 0", got)
+	}
+}
+
+func TestRestoreFailureKeepsPreviousData(t *testing.T) {
+	stores, dir := openFileStores(t)
+	if err := stores.Restore(testSnapshot()); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.ReadFile(filepath.Join(dir, "scenarios.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A directory where the sessions file is staged makes staging fail after other files were written
+	blocker := filepath.Join(dir, "sessions.json.restore", "child")
+	if err := os.MkdirAll(blocker, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	replacement := testSnapshot()
+	replacement.Scenarios[0].Name = "Evacuation"
+	if err := stores.Restore(replacement); err == nil {
+		t.Fatal("Restore succeeded although a file could not be staged")
+	}
+
+	if after, _ := os.ReadFile(filepath.Join(dir, "scenarios.json")); string(after) != string(before) {
+		t.Error("a failed restore replaced the scenarios file")
+	}
+	if scenario, _ := stores.Scenarios.GetByID("scenario_1"); scenario.Name != "Fire drill" {
+		t.Errorf("a failed restore changed the scenario in memory to %q", scenario.Name)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "scenarios.json.restore")); !os.IsNotExist(err) {
+		t.Errorf("staged scenarios file left behind: %v", err)
+	}
+}
+
+func TestSwapStagedFilesPutsBackOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.json")
+	created := filepath.Join(dir, "created.json")
+	if err := os.WriteFile(existing, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{existing, created} {
+		if err := os.WriteFile(path+".restore", []byte("new"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The last staged file is missing, so its move fails after the others were moved
+	staged := []stagedFile{
+		{path: existing, staged: existing + ".restore"},
+		{path: created, staged: created + ".restore"},
+		{path: filepath.Join(dir, "missing.json"), staged: filepath.Join(dir, "missing.json.restore")},
+	}
+	if err := swapStagedFiles(staged); err == nil {
+		t.Fatal("swapStagedFiles succeeded with a missing staged file")
+	}
+
+	if got, _ := os.ReadFile(existing); string(got) != "old" {
+		t.Errorf("existing file holds %q after the swap was undone, want %q", got, "old")
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("file that did not exist before is still there: %v", err)
+	}
+}
+
+func TestDecodeOldSettingsDocumentKeepsNewDefaults(t *testing.T) {
+	// An unversioned settings file, written before stations and most general settings existed
+	old := `{
+		"llm": {"ID": "default", "Provider": "OpenAI", "APIKey": "sk-test", "Model": "o4"},
+		"general": {"ApplicationName": "Academy", "LogLevel": "INFO", "MaxConcurrentSessions": 3,
+			"SessionTimeout": 45, "RecordSessions": true, "StoreSessionData": true, "DataRetentionDays": 30}
+	}`
+
+	var snap Snapshot
+	if err := snap.DecodeDocument(DocumentSettings, []byte(old)); err != nil {
+		t.Fatal(err)
+	}
+
+	general := snap.GeneralSettings
+	if general.ApplicationName != "Academy" || general.MaxConcurrentSessions != 3 || general.SessionTimeout != 45 || general.DataRetentionDays != 30 {
+		t.Errorf("restored general settings %+v lost the backed up values", general)
+	}
+	defaults := DefaultGeneralSettings()
+	if general.PracticeIntervalDays != defaults.PracticeIntervalDays {
+		t.Errorf("PracticeIntervalDays = %d, want the default %d", general.PracticeIntervalDays, defaults.PracticeIntervalDays)
+	}
+	if general.OverLimitAction != defaults.OverLimitAction || general.TimeoutAction != defaults.TimeoutAction || general.RetentionAction != defaults.RetentionAction {
+		t.Errorf("actions = %q, %q, %q, want the defaults", general.OverLimitAction, general.TimeoutAction, general.RetentionAction)
+	}
+	if snap.LLMSettings.APIKey != "sk-test" {
+		t.Errorf("LLM API key = %q, want the backed up key", snap.LLMSettings.APIKey)
+	}
+	if len(snap.Stations) != 1 || snap.Stations[0].Endpoint != DefaultStationEndpoint {
+		t.Errorf("stations = %+v, want the default station", snap.Stations)
+	}
+}
@@ -0,0 +1,657 @@
+// internal/models/sqlite.go
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/settings"
+)
+
+// sqliteTimeFormat stores timestamps as fixed-width UTC text so that they sort correctly
+const sqliteTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// sqliteSchema creates every table and index used by the SQLite backend
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS scenarios (
+	id               TEXT PRIMARY KEY,
+	name             TEXT NOT NULL,
+	description      TEXT NOT NULL DEFAULT '',
+	category         TEXT NOT NULL DEFAULT '',
+	difficulty       INTEGER NOT NULL DEFAULT 0,
+	duration         INTEGER NOT NULL DEFAULT 0,
+	scene            TEXT NOT NULL DEFAULT '',
+	background_noise INTEGER NOT NULL DEFAULT 0,
+	success_criteria TEXT NOT NULL DEFAULT '',
+	keywords         TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS avatars (
+	id                   TEXT PRIMARY KEY,
+	name                 TEXT NOT NULL,
+	description          TEXT NOT NULL DEFAULT '',
+	personality_type     TEXT NOT NULL DEFAULT '',
+	communication_style  TEXT NOT NULL DEFAULT '',
+	knowledge_level      INTEGER NOT NULL DEFAULT 0,
+	aggressiveness_level INTEGER NOT NULL DEFAULT 0,
+	patience_level       INTEGER NOT NULL DEFAULT 0,
+	emotional_reactivity INTEGER NOT NULL DEFAULT 0,
+	voice_type           TEXT NOT NULL DEFAULT '',
+	speaking_speed       INTEGER NOT NULL DEFAULT 0,
+	image_url            TEXT NOT NULL DEFAULT '',
+	keywords             TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS observers (
+	id                    TEXT PRIMARY KEY,
+	name                  TEXT NOT NULL,
+	description           TEXT NOT NULL DEFAULT '',
+	feedback_style        TEXT NOT NULL DEFAULT '',
+	intervention_level    INTEGER NOT NULL DEFAULT 0,
+	detail_level          INTEGER NOT NULL DEFAULT 0,
+	feedback_tone         TEXT NOT NULL DEFAULT '',
+	success_metrics       TEXT NOT NULL DEFAULT '',
+	intervention_triggers TEXT NOT NULL DEFAULT '[]',
+	active                INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS sessions (
+	id          TEXT PRIMARY KEY,
+	scenario_id TEXT NOT NULL REFERENCES scenarios(id),
+	avatar_id   TEXT NOT NULL REFERENCES avatars(id),
+	observer_id TEXT NOT NULL REFERENCES observers(id),
+	status      TEXT NOT NULL,
+	start_time  TEXT NOT NULL,
+	end_time    TEXT,
+	update_time TEXT NOT NULL,
+	score       INTEGER,
+	notes       TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_status ON sessions(status);
+CREATE INDEX IF NOT EXISTS idx_sessions_start_time ON sessions(start_time);
+CREATE INDEX IF NOT EXISTS idx_sessions_scenario ON sessions(scenario_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_avatar ON sessions(avatar_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_observer ON sessions(observer_id);
+
+CREATE TABLE IF NOT EXISTS settings (
+	section TEXT PRIMARY KEY,
+	value   TEXT NOT NULL
+);
+`
+
+// OpenSQLite opens the database at path with foreign keys enforced and creates the schema
+func OpenSQLite(path string) (*sql.DB, error) {
+	dsn := "file:" + path +
+		"?_pragma=foreign_keys(1)" +
+		"&_pragma=journal_mode(WAL)" +
+		"&_pragma=busy_timeout(5000)"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+
+	if _, err := migrateSQLite(db, false); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// SQLiteMigration upgrades the database schema to Version.
+// The schema version is tracked in PRAGMA user_version.
+type SQLiteMigration struct {
+	Version     int
+	Description string
+	Apply       func(tx *sql.Tx) ([]string, error)
+}
+
+// sqliteMigrations is the registry of database migrations, in order
+var sqliteMigrations = []SQLiteMigration{
+	{
+		Version:     1,
+		Description: "create tables and indexes",
+		Apply: func(tx *sql.Tx) ([]string, error) {
+			_, err := tx.Exec(sqliteSchema)
+			return nil, err
+		},
+	},
+	{
+		Version:     2,
+		Description: "rename settings fields from Go names to camelCase",
+		Apply:       migrateSQLiteSettingsFields,
+	},
+	{
+		Version:     3,
+		Description: "add legal hold and anonymization to sessions",
+		Apply: func(tx *sql.Tx) ([]string, error) {
+			_, err := tx.Exec(`
+ALTER TABLE sessions ADD COLUMN legal_hold INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sessions ADD COLUMN anonymized_at TEXT;`)
+			return nil, err
+		},
+	},
+	{
+		Version:     4,
+		Description: "add scenario revisions and record the revision of each session",
+		Apply: func(tx *sql.Tx) ([]string, error) {
+			_, err := tx.Exec(`
+CREATE TABLE scenario_revisions (
+	id            TEXT PRIMARY KEY,
+	scenario_id   TEXT NOT NULL,
+	number        INTEGER NOT NULL,
+	author        TEXT NOT NULL,
+	created_at    TEXT NOT NULL,
+	note          TEXT NOT NULL DEFAULT '',
+	restored_from TEXT NOT NULL DEFAULT '',
+	content       TEXT NOT NULL,
+	UNIQUE (scenario_id, number)
+);
+ALTER TABLE sessions ADD COLUMN scenario_revision_id TEXT NOT NULL DEFAULT '';`)
+			return nil, err
+		},
+	},
+	{
+		Version:     5,
+		Description: "snapshot the scenario, avatar and observer configuration of each session",
+		Apply: func(tx *sql.Tx) ([]string, error) {
+			_, err := tx.Exec(`ALTER TABLE sessions ADD COLUMN configuration TEXT NOT NULL DEFAULT '';`)
+			return nil, err
+		},
+	},
+	{
+		Version:     6,
+		Description: "allow archiving scenarios, avatars and observers",
+		Apply: func(tx *sql.Tx) ([]string, error) {
+			_, err := tx.Exec(`
+ALTER TABLE scenarios ADD COLUMN archived INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE avatars ADD COLUMN archived INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE observers ADD COLUMN archived INTEGER NOT NULL DEFAULT 0;`)
+			return nil, err
+		},
+	},
+	{
+		Version:     7,
+		Description: "add the outbox of pending Unreal Engine deliveries",
+		Apply: func(tx *sql.Tx) ([]string, error) {
+			_, err := tx.Exec(`
+CREATE TABLE ue_outbox (
+	id           TEXT PRIMARY KEY,
+	session_id   TEXT NOT NULL,
+	payload      TEXT NOT NULL,
+	attempts     INTEGER NOT NULL DEFAULT 0,
+	next_attempt TEXT NOT NULL,
+	last_error   TEXT NOT NULL DEFAULT '',
+	created_at   TEXT NOT NULL
+);`)
+			return nil, err
+		},
+	},
+	{
+		Version:     8,
+		Description: "add VR stations and record the station of each session",
+		Apply: func(tx *sql.Tx) ([]string, error) {
+			if _, err := tx.Exec(`ALTER TABLE sessions ADD COLUMN station_id TEXT NOT NULL DEFAULT '';`); err != nil {
+				return nil, err
+			}
+			return migrateSQLiteStations(tx)
+		},
+	},
+	{
+		Version:     9,
+		Description: "add the event logs stations report for sessions",
+		Apply: func(tx *sql.Tx) ([]string, error) {
+			_, err := tx.Exec(`
+CREATE TABLE session_events (
+	seq         INTEGER PRIMARY KEY AUTOINCREMENT,
+	id          TEXT NOT NULL UNIQUE,
+	session_id  TEXT NOT NULL,
+	client_id   TEXT NOT NULL DEFAULT '',
+	type        TEXT NOT NULL,
+	occurred_at TEXT NOT NULL,
+	received_at TEXT NOT NULL,
+	phase       TEXT NOT NULL DEFAULT '',
+	text        TEXT NOT NULL DEFAULT '',
+	score       INTEGER,
+	reason      TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX idx_session_events_session ON session_events(session_id, seq);`)
+			return nil, err
+		},
+	},
+	{
+		Version:     10,
+		Description: "record session status transitions and paused time",
+		Apply: func(tx *sql.Tx) ([]string, error) {
+			_, err := tx.Exec(`
+ALTER TABLE sessions ADD COLUMN started_at TEXT;
+ALTER TABLE sessions ADD COLUMN paused_at TEXT;
+ALTER TABLE sessions ADD COLUMN paused_total INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sessions ADD COLUMN transitions TEXT NOT NULL DEFAULT '';`)
+			return nil, err
+		},
+	},
+	{
+		Version:     11,
+		Description: "schedule sessions for a trainee ahead of time",
+		Apply: func(tx *sql.Tx) ([]string, error) {
+			_, err := tx.Exec(`
+ALTER TABLE sessions ADD COLUMN trainee_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN scheduled_for TEXT;`)
+			return nil, err
+		},
+	},
+	{
+		Version:     12,
+		Description: "add trainees and record the trainee of each session",
+		Apply: func(tx *sql.Tx) ([]string, error) {
+			_, err := tx.Exec(`
+CREATE TABLE trainees (
+	id                  TEXT PRIMARY KEY,
+	name                TEXT NOT NULL,
+	employee_id         TEXT NOT NULL,
+	department          TEXT NOT NULL DEFAULT '',
+	language            TEXT NOT NULL DEFAULT '',
+	accessibility_needs TEXT NOT NULL DEFAULT '[]',
+	archived            INTEGER NOT NULL DEFAULT 0
+);
+CREATE UNIQUE INDEX idx_trainees_employee_id ON trainees(employee_id COLLATE NOCASE);
+ALTER TABLE sessions ADD COLUMN trainee_id TEXT NOT NULL DEFAULT '';
+CREATE INDEX idx_sessions_trainee ON sessions(trainee_id);`)
+			return nil, err
+		},
+	},
+	{
+		Version:     13,
+		Description: "score sessions with observer rubrics mapped to scenario success criteria",
+		Apply: func(tx *sql.Tx) ([]string, error) {
+			_, err := tx.Exec(`
+ALTER TABLE observers ADD COLUMN rubric TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE scenarios ADD COLUMN rubric_items TEXT NOT NULL DEFAULT '{}';
+ALTER TABLE session_events ADD COLUMN ratings TEXT NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN score_breakdown TEXT NOT NULL DEFAULT '';`)
+			return nil, err
+		},
+	},
+	{
+		Version:     14,
+		Description: "check the trainee of each session with a foreign key",
+		Apply:       migrateSQLiteTraineeReference,
+	},
+}
+
+// migrateSQLite applies every pending migration in a single transaction.
+// With dryRun set the transaction is rolled back and the report describes what would change.
+func migrateSQLite(db *sql.DB, dryRun bool) (MigrationReport, error) {
+	latest := sqliteMigrations[len(sqliteMigrations)-1].Version
+	report := MigrationReport{Target: "sqlite", ToVersion: latest}
+
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&report.FromVersion); err != nil {
+		return report, fmt.Errorf("reading schema version: %w", err)
+	}
+	if report.FromVersion > latest {
+		return report, fmt.Errorf("database schema version %d is newer than supported version %d",
+			report.FromVersion, latest)
+	}
+	if !report.Pending() {
+		return report, nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return report, err
+	}
+	defer tx.Rollback()
+
+	for _, m := range sqliteMigrations {
+		if m.Version <= report.FromVersion {
+			continue
+		}
+		changes, err := m.Apply(tx)
+		if err != nil {
+			return report, fmt.Errorf("database migration to version %d: %w", m.Version, err)
+		}
+		report.Changes = append(report.Changes, fmt.Sprintf("v%d: %s", m.Version, m.Description))
+		for _, change := range changes {
+			report.Changes = append(report.Changes, "  "+change)
+		}
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, latest)); err != nil {
+		return report, err
+	}
+	if err := tx.Commit(); err != nil {
+		return report, err
+	}
+
+	log.Printf("Migrated database from version %d to %d", report.FromVersion, latest)
+	return report, nil
+}
+
+// migrateSQLiteFile reports on, and unless dryRun applies, the migrations of the database at path
+func migrateSQLiteFile(path string, dryRun bool) (MigrationReport, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) && dryRun {
+		return MigrationReport{
+			Target:    path,
+			ToVersion: sqliteMigrations[len(sqliteMigrations)-1].Version,
+			Changes:   []string{"database does not exist yet and will be created"},
+		}, nil
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return MigrationReport{Target: path}, err
+	}
+	defer db.Close()
+
+	report, err := migrateSQLite(db, dryRun)
+	report.Target = path
+	return report, err
+}
+
+// migrateSQLiteTraineeReference replaces the trainee_id column of sessions, added without
+// a foreign key, with a nullable one referencing trainees. Sessions without a trainee, or
+// whose trainee no longer exists, keep NULL.
+func migrateSQLiteTraineeReference(tx *sql.Tx) ([]string, error) {
+	var dangling int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM sessions
+		WHERE trainee_id <> '' AND trainee_id NOT IN (SELECT id FROM trainees)`).Scan(&dangling); err != nil {
+		return nil, err
+	}
+
+	_, err := tx.Exec(`
+DROP INDEX idx_sessions_trainee;
+ALTER TABLE sessions RENAME COLUMN trainee_id TO legacy_trainee_id;
+ALTER TABLE sessions ADD COLUMN trainee_id TEXT REFERENCES trainees(id);
+UPDATE sessions SET trainee_id = (SELECT id FROM trainees WHERE id = legacy_trainee_id);
+ALTER TABLE sessions DROP COLUMN legacy_trainee_id;
+CREATE INDEX idx_sessions_trainee ON sessions(trainee_id);`)
+	if err != nil {
+		return nil, err
+	}
+	if dangling > 0 {
+		return []string{fmt.Sprintf("cleared the trainee of %d sessions whose trainee no longer exists", dangling)}, nil
+	}
+	return nil, nil
+}
+
+// migrateSQLiteStations moves the single Unreal Engine endpoint of the general settings
+// into the station list
+func migrateSQLiteStations(tx *sql.Tx) ([]string, error) {
+	var value string
+	err := tx.QueryRow(`SELECT value FROM settings WHERE section = ?`, settingsSectionGeneral).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var general map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &general); err != nil {
+		return nil, fmt.Errorf("decoding general settings: %w", err)
+	}
+	stations, changes := stationsFromGeneral(general)
+
+	data, err := json.Marshal(general)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`UPDATE settings SET value = ? WHERE section = ?`, string(data), settingsSectionGeneral); err != nil {
+		return nil, err
+	}
+	if err := saveSettingsSection(tx, settingsSectionStations, stations); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// migrateSQLiteSettingsFields rewrites the stored settings documents with camelCase keys
+func migrateSQLiteSettingsFields(tx *sql.Tx) ([]string, error) {
+	renames := map[string]map[string]string{
+		settingsSectionLLM:     llmSettingsRenames,
+		settingsSectionGeneral: generalSettingsRenames,
+	}
+
+	rows, err := tx.Query(`SELECT section, value FROM settings`)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	for rows.Next() {
+		var section, value string
+		if err := rows.Scan(&section, &value); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		values[section] = value
+	}
+	rows.Close()
+
+	changes := make([]string, 0)
+	for section, value := range values {
+		table, ok := renames[section]
+		if !ok {
+			continue
+		}
+		var obj map[string]interface{}
+		if err := json.Unmarshal([]byte(value), &obj); err != nil {
+			return nil, fmt.Errorf("decoding %s settings: %w", section, err)
+		}
+		renamed := renameKeys(obj, table)
+		if len(renamed) == 0 {
+			continue
+		}
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(`UPDATE settings SET value = ? WHERE section = ?`, string(data), section); err != nil {
+			return nil, err
+		}
+		for _, change := range renamed {
+			changes = append(changes, section+"."+change)
+		}
+	}
+	return changes, nil
+}
+
+// openSQLiteStores opens the SQLite database and builds every store on top of it
+func openSQLiteStores(path string) (*Stores, error) {
+	db, err := OpenSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := seedSQLite(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Stores{
+		Scenarios:         NewSQLiteScenarioStore(db),
+		ScenarioRevisions: NewSQLiteScenarioRevisionStore(db),
+		Avatars:           NewSQLiteAvatarStore(db),
+		Observers:         NewSQLiteObserverStore(db),
+		Trainees:          NewSQLiteTraineeStore(db),
+		Sessions:          NewSQLiteSessionStore(db),
+		Settings:          NewSQLiteSettingsStore(db),
+		Outbox:            NewSQLiteOutboxStore(db),
+		Events:            NewSQLiteEventStore(db),
+		db:                db,
+	}, nil
+}
+
+// seedSQLite fills an empty database with the same sample data as the in-memory stores
+func seedSQLite(db *sql.DB) error {
+	var count int
+	if err := db.QueryRow(`SELECT
+		(SELECT COUNT(*) FROM scenarios) +
+		(SELECT COUNT(*) FROM avatars) +
+		(SELECT COUNT(*) FROM observers)`).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, scenario := range NewScenarioStore().GetAll() {
+		if err := insertScenario(tx, scenario); err != nil {
+			return err
+		}
+	}
+	for _, avatar := range NewAvatarStore().GetAll() {
+		if err := insertAvatar(tx, avatar); err != nil {
+			return err
+		}
+	}
+	for _, observer := range NewObserverStore().GetAll() {
+		if err := insertObserver(tx, observer); err != nil {
+			return err
+		}
+	}
+	for _, trainee := range NewTraineeStore().GetAll() {
+		if err := insertTrainee(tx, trainee); err != nil {
+			return err
+		}
+	}
+
+	log.Println("Seeded SQLite database with sample data")
+	return tx.Commit()
+}
+
+// restoreSQLite replaces the whole database content with snap in a single transaction
+func restoreSQLite(db *sql.DB, snap *Snapshot) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// References are checked once everything has been inserted
+	if _, err := tx.Exec(`PRAGMA defer_foreign_keys = ON`); err != nil {
+		return err
+	}
+	// Pending deliveries belong to the sessions being replaced and must not be sent again
+	for _, table := range []string{"ue_outbox", "session_events", "sessions", "scenario_revisions", "scenarios", "avatars", "observers", "trainees", "settings"} {
+		if _, err := tx.Exec(`DELETE FROM ` + table); err != nil {
+			return fmt.Errorf("clearing %s: %w", table, err)
+		}
+	}
+
+	for _, scenario := range snap.Scenarios {
+		if err := insertScenario(tx, scenario); err != nil {
+			return fmt.Errorf("restoring scenario %s: %w", scenario.ID, err)
+		}
+	}
+	for _, rev := range snap.ScenarioRevisions {
+		if err := insertRevision(tx, rev); err != nil {
+			return fmt.Errorf("restoring scenario revision %s: %w", rev.ID, err)
+		}
+	}
+	for _, avatar := range snap.Avatars {
+		if err := insertAvatar(tx, avatar); err != nil {
+			return fmt.Errorf("restoring avatar %s: %w", avatar.ID, err)
+		}
+	}
+	for _, observer := range snap.Observers {
+		if err := insertObserver(tx, observer); err != nil {
+			return fmt.Errorf("restoring observer %s: %w", observer.ID, err)
+		}
+	}
+	for _, trainee := range snap.Trainees {
+		if err := insertTrainee(tx, trainee); err != nil {
+			return fmt.Errorf("restoring trainee %s: %w", trainee.ID, err)
+		}
+	}
+	for _, session := range snap.Sessions {
+		if err := insertSession(tx, session); err != nil {
+			return fmt.Errorf("restoring session %s: %w", session.ID, err)
+		}
+	}
+	for _, event := range snap.Events {
+		if err := insertEvent(tx, event); err != nil {
+			return fmt.Errorf("restoring session event %s: %w", event.ID, err)
+		}
+	}
+
+	if err := saveSettingsSection(tx, settingsSectionLLM, snap.LLMSettings); err != nil {
+		return err
+	}
+	if err := saveSettingsSection(tx, settingsSectionGeneral, snap.GeneralSettings); err != nil {
+		return err
+	}
+	stations := snap.Stations
+	if stations == nil {
+		stations = []settings.Station{}
+	}
+	if err := saveSettingsSection(tx, settingsSectionStations, stations); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing restore: %w", err)
+	}
+	return nil
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// formatSQLiteTime converts t to the stored text representation
+func formatSQLiteTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeFormat)
+}
+
+// parseSQLiteTime converts a stored timestamp back to local time
+func parseSQLiteTime(value string) (time.Time, error) {
+	t, err := time.Parse(sqliteTimeFormat, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.Local(), nil
+}
+
+// boolToInt converts a bool to the integer SQLite stores it as
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// likePattern builds a case-insensitive LIKE pattern for a search query. The query is
+// matched literally, so the pattern must be used with ESCAPE '\'.
+func likePattern(query string) string {
+	return "%" + likeEscaper.Replace(query) + "%"
+}
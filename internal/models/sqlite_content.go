@@ -0,0 +1,505 @@
+// internal/models/sqlite_content.go
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/avatars"
+	"github.com/saladinomario/vr-training-admin/templates/components/observers"
+	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
+)
+
+// SQLiteScenarioStore implements ScenarioRepository on top of SQLite
+type SQLiteScenarioStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteScenarioStore creates a scenario store backed by db
+func NewSQLiteScenarioStore(db *sql.DB) *SQLiteScenarioStore {
+	return &SQLiteScenarioStore{db: db}
+}
+
+const scenarioColumns = `id, name, description, category, difficulty, duration, scene, background_noise, success_criteria, keywords, archived, rubric_items`
+
+func scanScenario(row rowScanner) (scenarios.Scenario, error) {
+	var scenario scenarios.Scenario
+	var rubricItems string
+	err := row.Scan(&scenario.ID, &scenario.Name, &scenario.Description, &scenario.Category,
+		&scenario.Difficulty, &scenario.Duration, &scenario.Scene, &scenario.BackgroundNoise,
+		&scenario.SuccessCriteria, &scenario.Keywords, &scenario.Archived, &rubricItems)
+	if err != nil {
+		return scenario, err
+	}
+	if err := json.Unmarshal([]byte(rubricItems), &scenario.RubricItems); err != nil {
+		return scenario, fmt.Errorf("decoding rubric items: %w", err)
+	}
+	return scenario, nil
+}
+
+// encodeRubricItems returns the JSON stored in the rubric_items column
+func encodeRubricItems(mapping map[string][]string) (string, error) {
+	if mapping == nil {
+		mapping = map[string][]string{}
+	}
+	data, err := json.Marshal(mapping)
+	return string(data), err
+}
+
+func insertScenario(db execer, scenario scenarios.Scenario) error {
+	rubricItems, err := encodeRubricItems(scenario.RubricItems)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO scenarios (`+scenarioColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		scenario.ID, scenario.Name, scenario.Description, scenario.Category, scenario.Difficulty,
+		scenario.Duration, scenario.Scene, boolToInt(scenario.BackgroundNoise), scenario.SuccessCriteria,
+		scenario.Keywords, boolToInt(scenario.Archived), rubricItems)
+	return err
+}
+
+func (s *SQLiteScenarioStore) query(where string, args ...interface{}) []scenarios.Scenario {
+	rows, err := s.db.Query(`SELECT `+scenarioColumns+` FROM scenarios `+where+` ORDER BY name`, args...)
+	if err != nil {
+		log.Printf("Error querying scenarios: %v", err)
+		return []scenarios.Scenario{}
+	}
+	defer rows.Close()
+
+	result := make([]scenarios.Scenario, 0)
+	for rows.Next() {
+		scenario, err := scanScenario(rows)
+		if err != nil {
+			log.Printf("Error scanning scenario: %v", err)
+			continue
+		}
+		result = append(result, scenario)
+	}
+	return result
+}
+
+// GetAll returns all scenarios
+func (s *SQLiteScenarioStore) GetAll() []scenarios.Scenario {
+	return s.query("")
+}
+
+// GetByID returns a scenario by its ID
+func (s *SQLiteScenarioStore) GetByID(id string) (scenarios.Scenario, error) {
+	scenario, err := scanScenario(s.db.QueryRow(`SELECT `+scenarioColumns+` FROM scenarios WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return scenarios.Scenario{}, ErrScenarioNotFound
+	}
+	return scenario, err
+}
+
+// Create adds a new scenario
+func (s *SQLiteScenarioStore) Create(scenario scenarios.Scenario) (scenarios.Scenario, error) {
+	if scenario.Name == "" {
+		return scenarios.Scenario{}, ErrInvalidScenario
+	}
+
+	scenario.ID = generateID()
+	if err := insertScenario(s.db, scenario); err != nil {
+		return scenarios.Scenario{}, fmt.Errorf("inserting scenario: %w", err)
+	}
+	return scenario, nil
+}
+
+// Insert adds a scenario under its own ID, as done when importing content
+func (s *SQLiteScenarioStore) Insert(scenario scenarios.Scenario) error {
+	if scenario.ID == "" || scenario.Name == "" {
+		return ErrInvalidScenario
+	}
+	if _, err := s.GetByID(scenario.ID); err == nil {
+		return ErrScenarioExists
+	}
+	if err := insertScenario(s.db, scenario); err != nil {
+		return fmt.Errorf("inserting scenario: %w", err)
+	}
+	return nil
+}
+
+// Update modifies an existing scenario
+func (s *SQLiteScenarioStore) Update(id string, scenario scenarios.Scenario) error {
+	if scenario.Name == "" {
+		return ErrInvalidScenario
+	}
+
+	rubricItems, err := encodeRubricItems(scenario.RubricItems)
+	if err != nil {
+		return err
+	}
+	res, err := s.db.Exec(`UPDATE scenarios SET name = ?, description = ?, category = ?, difficulty = ?,
+		duration = ?, scene = ?, background_noise = ?, success_criteria = ?, keywords = ?, rubric_items = ? WHERE id = ?`,
+		scenario.Name, scenario.Description, scenario.Category, scenario.Difficulty, scenario.Duration,
+		scenario.Scene, boolToInt(scenario.BackgroundNoise), scenario.SuccessCriteria, scenario.Keywords, rubricItems, id)
+	if err != nil {
+		return fmt.Errorf("updating scenario: %w", err)
+	}
+	return requireAffected(res, ErrScenarioNotFound)
+}
+
+// Delete removes a scenario
+func (s *SQLiteScenarioStore) Delete(id string) error {
+	res, err := s.db.Exec(`DELETE FROM scenarios WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("deleting scenario: %w", err)
+	}
+	return requireAffected(res, ErrScenarioNotFound)
+}
+
+// SetArchived archives a scenario, or brings it back
+func (s *SQLiteScenarioStore) SetArchived(id string, archived bool) error {
+	res, err := s.db.Exec(`UPDATE scenarios SET archived = ? WHERE id = ?`, boolToInt(archived), id)
+	if err != nil {
+		return fmt.Errorf("archiving scenario: %w", err)
+	}
+	return requireAffected(res, ErrScenarioNotFound)
+}
+
+// Search looks for scenarios matching the query
+func (s *SQLiteScenarioStore) Search(query string) []scenarios.Scenario {
+	if query == "" {
+		return s.GetAll()
+	}
+	pattern := likePattern(query)
+	return s.query(`WHERE name LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\' OR category LIKE ? ESCAPE '\'`, pattern, pattern, pattern)
+}
+
+// SQLiteAvatarStore implements AvatarRepository on top of SQLite
+type SQLiteAvatarStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteAvatarStore creates an avatar store backed by db
+func NewSQLiteAvatarStore(db *sql.DB) *SQLiteAvatarStore {
+	return &SQLiteAvatarStore{db: db}
+}
+
+const avatarColumns = `id, name, description, personality_type, communication_style, knowledge_level,
+	aggressiveness_level, patience_level, emotional_reactivity, voice_type, speaking_speed, image_url, keywords, archived`
+
+func scanAvatar(row rowScanner) (avatars.Avatar, error) {
+	var avatar avatars.Avatar
+	err := row.Scan(&avatar.ID, &avatar.Name, &avatar.Description, &avatar.PersonalityType,
+		&avatar.CommunicationStyle, &avatar.KnowledgeLevel, &avatar.AggressivenessLevel,
+		&avatar.PatienceLevel, &avatar.EmotionalReactivity, &avatar.VoiceType, &avatar.SpeakingSpeed,
+		&avatar.ImageURL, &avatar.Keywords, &avatar.Archived)
+	return avatar, err
+}
+
+func insertAvatar(db execer, avatar avatars.Avatar) error {
+	_, err := db.Exec(`INSERT INTO avatars (`+avatarColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		avatar.ID, avatar.Name, avatar.Description, avatar.PersonalityType, avatar.CommunicationStyle,
+		avatar.KnowledgeLevel, avatar.AggressivenessLevel, avatar.PatienceLevel, avatar.EmotionalReactivity,
+		avatar.VoiceType, avatar.SpeakingSpeed, avatar.ImageURL, avatar.Keywords, boolToInt(avatar.Archived))
+	return err
+}
+
+func (s *SQLiteAvatarStore) query(where string, args ...interface{}) []avatars.Avatar {
+	rows, err := s.db.Query(`SELECT `+avatarColumns+` FROM avatars `+where+` ORDER BY name`, args...)
+	if err != nil {
+		log.Printf("Error querying avatars: %v", err)
+		return []avatars.Avatar{}
+	}
+	defer rows.Close()
+
+	result := make([]avatars.Avatar, 0)
+	for rows.Next() {
+		avatar, err := scanAvatar(rows)
+		if err != nil {
+			log.Printf("Error scanning avatar: %v", err)
+			continue
+		}
+		result = append(result, avatar)
+	}
+	return result
+}
+
+// GetAll returns all avatars
+func (s *SQLiteAvatarStore) GetAll() []avatars.Avatar {
+	return s.query("")
+}
+
+// GetByID returns an avatar by its ID
+func (s *SQLiteAvatarStore) GetByID(id string) (avatars.Avatar, error) {
+	avatar, err := scanAvatar(s.db.QueryRow(`SELECT `+avatarColumns+` FROM avatars WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return avatars.Avatar{}, ErrAvatarNotFound
+	}
+	return avatar, err
+}
+
+// Create adds a new avatar
+func (s *SQLiteAvatarStore) Create(avatar avatars.Avatar) (avatars.Avatar, error) {
+	if avatar.Name == "" {
+		return avatars.Avatar{}, ErrInvalidAvatar
+	}
+
+	avatar.ID = generateAvatarID()
+	if err := insertAvatar(s.db, avatar); err != nil {
+		return avatars.Avatar{}, fmt.Errorf("inserting avatar: %w", err)
+	}
+	return avatar, nil
+}
+
+// Insert adds an avatar under its own ID, as done when importing content
+func (s *SQLiteAvatarStore) Insert(avatar avatars.Avatar) error {
+	if avatar.ID == "" || avatar.Name == "" {
+		return ErrInvalidAvatar
+	}
+	if _, err := s.GetByID(avatar.ID); err == nil {
+		return ErrAvatarExists
+	}
+	if err := insertAvatar(s.db, avatar); err != nil {
+		return fmt.Errorf("inserting avatar: %w", err)
+	}
+	return nil
+}
+
+// Update modifies an existing avatar
+func (s *SQLiteAvatarStore) Update(id string, avatar avatars.Avatar) error {
+	if avatar.Name == "" {
+		return ErrInvalidAvatar
+	}
+
+	res, err := s.db.Exec(`UPDATE avatars SET name = ?, description = ?, personality_type = ?,
+		communication_style = ?, knowledge_level = ?, aggressiveness_level = ?, patience_level = ?,
+		emotional_reactivity = ?, voice_type = ?, speaking_speed = ?, image_url = ?, keywords = ? WHERE id = ?`,
+		avatar.Name, avatar.Description, avatar.PersonalityType, avatar.CommunicationStyle,
+		avatar.KnowledgeLevel, avatar.AggressivenessLevel, avatar.PatienceLevel, avatar.EmotionalReactivity,
+		avatar.VoiceType, avatar.SpeakingSpeed, avatar.ImageURL, avatar.Keywords, id)
+	if err != nil {
+		return fmt.Errorf("updating avatar: %w", err)
+	}
+	return requireAffected(res, ErrAvatarNotFound)
+}
+
+// Delete removes an avatar
+func (s *SQLiteAvatarStore) Delete(id string) error {
+	res, err := s.db.Exec(`DELETE FROM avatars WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("deleting avatar: %w", err)
+	}
+	return requireAffected(res, ErrAvatarNotFound)
+}
+
+// SetArchived archives an avatar, or brings it back
+func (s *SQLiteAvatarStore) SetArchived(id string, archived bool) error {
+	res, err := s.db.Exec(`UPDATE avatars SET archived = ? WHERE id = ?`, boolToInt(archived), id)
+	if err != nil {
+		return fmt.Errorf("archiving avatar: %w", err)
+	}
+	return requireAffected(res, ErrAvatarNotFound)
+}
+
+// Search looks for avatars matching the query
+func (s *SQLiteAvatarStore) Search(query string) []avatars.Avatar {
+	if query == "" {
+		return s.GetAll()
+	}
+	pattern := likePattern(query)
+	return s.query(`WHERE name LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\' OR personality_type LIKE ? ESCAPE '\'`, pattern, pattern, pattern)
+}
+
+// SQLiteObserverStore implements ObserverRepository on top of SQLite
+type SQLiteObserverStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteObserverStore creates an observer store backed by db
+func NewSQLiteObserverStore(db *sql.DB) *SQLiteObserverStore {
+	return &SQLiteObserverStore{db: db}
+}
+
+const observerColumns = `id, name, description, feedback_style, intervention_level, detail_level,
+	feedback_tone, success_metrics, intervention_triggers, active, archived, rubric`
+
+func scanObserver(row rowScanner) (observers.Observer, error) {
+	var observer observers.Observer
+	var triggers, rubric string
+	err := row.Scan(&observer.ID, &observer.Name, &observer.Description, &observer.FeedbackStyle,
+		&observer.InterventionLevel, &observer.DetailLevel, &observer.FeedbackTone,
+		&observer.SuccessMetrics, &triggers, &observer.Active, &observer.Archived, &rubric)
+	if err != nil {
+		return observer, err
+	}
+	if err := json.Unmarshal([]byte(triggers), &observer.InterventionTriggers); err != nil {
+		return observer, fmt.Errorf("decoding intervention triggers: %w", err)
+	}
+	if err := json.Unmarshal([]byte(rubric), &observer.Rubric); err != nil {
+		return observer, fmt.Errorf("decoding rubric: %w", err)
+	}
+	return observer, nil
+}
+
+// encodeRubric returns the JSON stored in the rubric column
+func encodeRubric(rubric []observers.RubricItem) (string, error) {
+	if rubric == nil {
+		rubric = []observers.RubricItem{}
+	}
+	data, err := json.Marshal(rubric)
+	return string(data), err
+}
+
+func encodeStringList(list []string) (string, error) {
+	if list == nil {
+		list = []string{}
+	}
+	data, err := json.Marshal(list)
+	return string(data), err
+}
+
+func insertObserver(db execer, observer observers.Observer) error {
+	triggers, err := encodeStringList(observer.InterventionTriggers)
+	if err != nil {
+		return err
+	}
+	rubric, err := encodeRubric(observer.Rubric)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO observers (`+observerColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		observer.ID, observer.Name, observer.Description, observer.FeedbackStyle, observer.InterventionLevel,
+		observer.DetailLevel, observer.FeedbackTone, observer.SuccessMetrics, triggers,
+		boolToInt(observer.Active), boolToInt(observer.Archived), rubric)
+	return err
+}
+
+func (s *SQLiteObserverStore) query(where string, args ...interface{}) []observers.Observer {
+	rows, err := s.db.Query(`SELECT `+observerColumns+` FROM observers `+where+` ORDER BY name`, args...)
+	if err != nil {
+		log.Printf("Error querying observers: %v", err)
+		return []observers.Observer{}
+	}
+	defer rows.Close()
+
+	result := make([]observers.Observer, 0)
+	for rows.Next() {
+		observer, err := scanObserver(rows)
+		if err != nil {
+			log.Printf("Error scanning observer: %v", err)
+			continue
+		}
+		result = append(result, observer)
+	}
+	return result
+}
+
+// GetAll returns all observers
+func (s *SQLiteObserverStore) GetAll() []observers.Observer {
+	return s.query("")
+}
+
+// GetByID returns an observer by its ID
+func (s *SQLiteObserverStore) GetByID(id string) (observers.Observer, error) {
+	observer, err := scanObserver(s.db.QueryRow(`SELECT `+observerColumns+` FROM observers WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return observers.Observer{}, ErrObserverNotFound
+	}
+	return observer, err
+}
+
+// Create adds a new observer
+func (s *SQLiteObserverStore) Create(observer observers.Observer) (observers.Observer, error) {
+	if observer.Name == "" {
+		return observers.Observer{}, ErrInvalidObserver
+	}
+	if err := prepareRubric(&observer); err != nil {
+		return observers.Observer{}, err
+	}
+
+	observer.ID = generateObserverID()
+	if err := insertObserver(s.db, observer); err != nil {
+		return observers.Observer{}, fmt.Errorf("inserting observer: %w", err)
+	}
+	return observer, nil
+}
+
+// Insert adds an observer under its own ID, as done when importing content
+func (s *SQLiteObserverStore) Insert(observer observers.Observer) error {
+	if observer.ID == "" || observer.Name == "" {
+		return ErrInvalidObserver
+	}
+	if err := prepareRubric(&observer); err != nil {
+		return err
+	}
+	if _, err := s.GetByID(observer.ID); err == nil {
+		return ErrObserverExists
+	}
+	if err := insertObserver(s.db, observer); err != nil {
+		return fmt.Errorf("inserting observer: %w", err)
+	}
+	return nil
+}
+
+// Update modifies an existing observer
+func (s *SQLiteObserverStore) Update(id string, observer observers.Observer) error {
+	if observer.Name == "" {
+		return ErrInvalidObserver
+	}
+	if err := prepareRubric(&observer); err != nil {
+		return err
+	}
+
+	triggers, err := encodeStringList(observer.InterventionTriggers)
+	if err != nil {
+		return err
+	}
+	rubric, err := encodeRubric(observer.Rubric)
+	if err != nil {
+		return err
+	}
+	res, err := s.db.Exec(`UPDATE observers SET name = ?, description = ?, feedback_style = ?,
+		intervention_level = ?, detail_level = ?, feedback_tone = ?, success_metrics = ?,
+		intervention_triggers = ?, active = ?, rubric = ? WHERE id = ?`,
+		observer.Name, observer.Description, observer.FeedbackStyle, observer.InterventionLevel,
+		observer.DetailLevel, observer.FeedbackTone, observer.SuccessMetrics, triggers,
+		boolToInt(observer.Active), rubric, id)
+	if err != nil {
+		return fmt.Errorf("updating observer: %w", err)
+	}
+	return requireAffected(res, ErrObserverNotFound)
+}
+
+// Delete removes an observer
+func (s *SQLiteObserverStore) Delete(id string) error {
+	res, err := s.db.Exec(`DELETE FROM observers WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("deleting observer: %w", err)
+	}
+	return requireAffected(res, ErrObserverNotFound)
+}
+
+// SetArchived archives an observer, or brings it back
+func (s *SQLiteObserverStore) SetArchived(id string, archived bool) error {
+	res, err := s.db.Exec(`UPDATE observers SET archived = ? WHERE id = ?`, boolToInt(archived), id)
+	if err != nil {
+		return fmt.Errorf("archiving observer: %w", err)
+	}
+	return requireAffected(res, ErrObserverNotFound)
+}
+
+// Search looks for observers matching the query
+func (s *SQLiteObserverStore) Search(query string) []observers.Observer {
+	if query == "" {
+		return s.GetAll()
+	}
+	pattern := likePattern(query)
+	return s.query(`WHERE name LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\' OR feedback_style LIKE ? ESCAPE '\'`, pattern, pattern, pattern)
+}
+
+// requireAffected returns notFound when a statement did not touch any row
+func requireAffected(res sql.Result, notFound error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return notFound
+	}
+	return nil
+}
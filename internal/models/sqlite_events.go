@@ -0,0 +1,149 @@
+// internal/models/sqlite_events.go
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+)
+
+// SQLiteEventStore implements EventRepository on top of SQLite.
+// Events are read back in insertion order, which is the order they were received.
+type SQLiteEventStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteEventStore creates an event store backed by db
+func NewSQLiteEventStore(db *sql.DB) *SQLiteEventStore {
+	return &SQLiteEventStore{db: db}
+}
+
+const eventColumns = `id, session_id, client_id, type, occurred_at, received_at, phase, text, score, reason, ratings`
+
+func scanEvent(row rowScanner) (sessions.Event, error) {
+	var (
+		event      sessions.Event
+		occurredAt string
+		receivedAt string
+		score      sql.NullInt64
+		ratings    string
+	)
+	err := row.Scan(&event.ID, &event.SessionID, &event.ClientID, &event.Type, &occurredAt, &receivedAt,
+		&event.Phase, &event.Text, &score, &event.Reason, &ratings)
+	if err != nil {
+		return event, err
+	}
+	if score.Valid {
+		value := int(score.Int64)
+		event.Score = &value
+	}
+	if ratings != "" {
+		if err := json.Unmarshal([]byte(ratings), &event.Ratings); err != nil {
+			return event, fmt.Errorf("decoding ratings of event %s: %w", event.ID, err)
+		}
+	}
+	if event.OccurredAt, err = parseSQLiteTime(occurredAt); err != nil {
+		return event, err
+	}
+	event.ReceivedAt, err = parseSQLiteTime(receivedAt)
+	return event, err
+}
+
+func insertEvent(db execer, event sessions.Event) error {
+	var score interface{}
+	if event.Score != nil {
+		score = *event.Score
+	}
+	ratings := ""
+	if len(event.Ratings) > 0 {
+		data, err := json.Marshal(event.Ratings)
+		if err != nil {
+			return err
+		}
+		ratings = string(data)
+	}
+	_, err := db.Exec(`INSERT INTO session_events (`+eventColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		event.ID, event.SessionID, event.ClientID, event.Type, formatSQLiteTime(event.OccurredAt),
+		formatSQLiteTime(event.ReceivedAt), event.Phase, event.Text, score, event.Reason, ratings)
+	return err
+}
+
+func (s *SQLiteEventStore) query(suffix string, args ...interface{}) []sessions.Event {
+	rows, err := s.db.Query(`SELECT `+eventColumns+` FROM session_events `+suffix, args...)
+	if err != nil {
+		log.Printf("Error querying session events: %v", err)
+		return []sessions.Event{}
+	}
+	defer rows.Close()
+
+	result := make([]sessions.Event, 0)
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			log.Printf("Error scanning session event: %v", err)
+			continue
+		}
+		result = append(result, event)
+	}
+	return result
+}
+
+// GetAll returns the events of every session, each log in the order it was received
+func (s *SQLiteEventStore) GetAll() []sessions.Event {
+	return s.query(`ORDER BY session_id, seq`)
+}
+
+// ListBySession returns the event log of a session
+func (s *SQLiteEventStore) ListBySession(sessionID string) []sessions.Event {
+	return s.query(`WHERE session_id = ? ORDER BY seq`, sessionID)
+}
+
+// Append adds events to the logs of their sessions in one transaction and returns the
+// events that were stored. Events whose ClientID is already in the session's log are skipped.
+func (s *SQLiteEventStore) Append(events []sessions.Event) ([]sessions.Event, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	added := make([]sessions.Event, 0, len(events))
+	for _, event := range events {
+		if event.ClientID != "" {
+			var exists int
+			err := tx.QueryRow(`SELECT COUNT(*) FROM session_events WHERE session_id = ? AND client_id = ?`,
+				event.SessionID, event.ClientID).Scan(&exists)
+			if err != nil {
+				return nil, fmt.Errorf("checking session event: %w", err)
+			}
+			if exists > 0 {
+				continue
+			}
+		}
+		stampEvent(&event, now)
+		if err := insertEvent(tx, event); err != nil {
+			return nil, fmt.Errorf("inserting session event: %w", err)
+		}
+		added = append(added, event)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// DeleteBySession removes the event log of a session and returns the number of events removed
+func (s *SQLiteEventStore) DeleteBySession(sessionID string) (int, error) {
+	res, err := s.db.Exec(`DELETE FROM session_events WHERE session_id = ?`, sessionID)
+	if err != nil {
+		return 0, fmt.Errorf("deleting session events: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
@@ -0,0 +1,92 @@
+// internal/models/sqlite_outbox.go
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// SQLiteOutboxStore implements OutboxRepository on top of SQLite
+type SQLiteOutboxStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteOutboxStore creates an outbox backed by db
+func NewSQLiteOutboxStore(db *sql.DB) *SQLiteOutboxStore {
+	return &SQLiteOutboxStore{db: db}
+}
+
+const deliveryColumns = `id, session_id, payload, attempts, next_attempt, last_error, created_at`
+
+func scanDelivery(row rowScanner) (Delivery, error) {
+	var (
+		d           Delivery
+		payload     string
+		nextAttempt string
+		createdAt   string
+	)
+	err := row.Scan(&d.ID, &d.SessionID, &payload, &d.Attempts, &nextAttempt, &d.LastError, &createdAt)
+	if err != nil {
+		return d, err
+	}
+	d.Payload = []byte(payload)
+	if d.NextAttempt, err = parseSQLiteTime(nextAttempt); err != nil {
+		return d, err
+	}
+	d.CreatedAt, err = parseSQLiteTime(createdAt)
+	return d, err
+}
+
+// GetAll returns the pending deliveries, oldest first
+func (s *SQLiteOutboxStore) GetAll() []Delivery {
+	rows, err := s.db.Query(`SELECT ` + deliveryColumns + ` FROM ue_outbox ORDER BY created_at, id`)
+	if err != nil {
+		log.Printf("Error querying outbox: %v", err)
+		return []Delivery{}
+	}
+	defer rows.Close()
+
+	result := make([]Delivery, 0)
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			log.Printf("Error scanning delivery: %v", err)
+			continue
+		}
+		result = append(result, d)
+	}
+	return result
+}
+
+// Add queues a new delivery that is due immediately
+func (s *SQLiteOutboxStore) Add(d Delivery) (Delivery, error) {
+	stampDelivery(&d, time.Now())
+	_, err := s.db.Exec(`INSERT INTO ue_outbox (`+deliveryColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		d.ID, d.SessionID, string(d.Payload), d.Attempts, formatSQLiteTime(d.NextAttempt),
+		d.LastError, formatSQLiteTime(d.CreatedAt))
+	if err != nil {
+		return Delivery{}, fmt.Errorf("inserting delivery: %w", err)
+	}
+	return d, nil
+}
+
+// Update stores the retry state of a pending delivery
+func (s *SQLiteOutboxStore) Update(d Delivery) error {
+	res, err := s.db.Exec(`UPDATE ue_outbox SET attempts = ?, next_attempt = ?, last_error = ? WHERE id = ?`,
+		d.Attempts, formatSQLiteTime(d.NextAttempt), d.LastError, d.ID)
+	if err != nil {
+		return fmt.Errorf("updating delivery: %w", err)
+	}
+	return requireAffected(res, ErrDeliveryNotFound)
+}
+
+// Delete removes a delivery once it was delivered or given up
+func (s *SQLiteOutboxStore) Delete(id string) error {
+	res, err := s.db.Exec(`DELETE FROM ue_outbox WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("deleting delivery: %w", err)
+	}
+	return requireAffected(res, ErrDeliveryNotFound)
+}
@@ -0,0 +1,118 @@
+// internal/models/sqlite_revisions.go
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
+)
+
+// SQLiteScenarioRevisionStore implements ScenarioRevisionRepository on top of SQLite.
+// The scenario content of each revision is stored as a JSON document.
+type SQLiteScenarioRevisionStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteScenarioRevisionStore creates a revision store backed by db
+func NewSQLiteScenarioRevisionStore(db *sql.DB) *SQLiteScenarioRevisionStore {
+	return &SQLiteScenarioRevisionStore{db: db}
+}
+
+const revisionColumns = `id, scenario_id, number, author, created_at, note, restored_from, content`
+
+func scanRevision(row rowScanner) (scenarios.Revision, error) {
+	var (
+		rev       scenarios.Revision
+		createdAt string
+		content   string
+	)
+	err := row.Scan(&rev.ID, &rev.ScenarioID, &rev.Number, &rev.Author, &createdAt,
+		&rev.Note, &rev.RestoredFrom, &content)
+	if err != nil {
+		return rev, err
+	}
+	if rev.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return rev, err
+	}
+	err = json.Unmarshal([]byte(content), &rev.Scenario)
+	return rev, err
+}
+
+func insertRevision(db execer, rev scenarios.Revision) error {
+	content, err := json.Marshal(rev.Scenario)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO scenario_revisions (`+revisionColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		rev.ID, rev.ScenarioID, rev.Number, rev.Author, formatSQLiteTime(rev.CreatedAt),
+		rev.Note, rev.RestoredFrom, string(content))
+	return err
+}
+
+func (s *SQLiteScenarioRevisionStore) query(where string, args ...interface{}) []scenarios.Revision {
+	rows, err := s.db.Query(`SELECT `+revisionColumns+` FROM scenario_revisions `+where, args...)
+	if err != nil {
+		log.Printf("Error querying scenario revisions: %v", err)
+		return []scenarios.Revision{}
+	}
+	defer rows.Close()
+
+	result := make([]scenarios.Revision, 0)
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			log.Printf("Error scanning scenario revision: %v", err)
+			continue
+		}
+		result = append(result, rev)
+	}
+	return result
+}
+
+// GetAll returns every revision of every scenario
+func (s *SQLiteScenarioRevisionStore) GetAll() []scenarios.Revision {
+	return s.query(`ORDER BY id`)
+}
+
+// ListByScenario returns the revisions of a scenario, newest first
+func (s *SQLiteScenarioRevisionStore) ListByScenario(scenarioID string) []scenarios.Revision {
+	return s.query(`WHERE scenario_id = ? ORDER BY number DESC`, scenarioID)
+}
+
+// GetByID returns a revision by ID
+func (s *SQLiteScenarioRevisionStore) GetByID(id string) (scenarios.Revision, error) {
+	rev, err := scanRevision(s.db.QueryRow(`SELECT `+revisionColumns+` FROM scenario_revisions WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return scenarios.Revision{}, ErrRevisionNotFound
+	}
+	return rev, err
+}
+
+// Add stores a new revision, numbering it after the latest revision of its scenario
+func (s *SQLiteScenarioRevisionStore) Add(rev scenarios.Revision) (scenarios.Revision, error) {
+	if rev.ScenarioID == "" {
+		return scenarios.Revision{}, ErrInvalidScenario
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return scenarios.Revision{}, err
+	}
+	defer tx.Rollback()
+
+	var number int
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(number), 0) FROM scenario_revisions WHERE scenario_id = ?`,
+		rev.ScenarioID).Scan(&number); err != nil {
+		return scenarios.Revision{}, err
+	}
+	stampRevision(&rev, number+1)
+
+	if err := insertRevision(tx, rev); err != nil {
+		return scenarios.Revision{}, fmt.Errorf("inserting scenario revision: %w", err)
+	}
+	return rev, tx.Commit()
+}
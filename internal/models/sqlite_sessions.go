@@ -0,0 +1,552 @@
+// internal/models/sqlite_sessions.go
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+	"github.com/saladinomario/vr-training-admin/templates/components/settings"
+)
+
+// SQLiteSessionStore implements SessionRepository on top of SQLite
+type SQLiteSessionStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteSessionStore creates a session store backed by db
+func NewSQLiteSessionStore(db *sql.DB) *SQLiteSessionStore {
+	return &SQLiteSessionStore{db: db}
+}
+
+const sessionColumns = `id, scenario_id, avatar_id, observer_id, status, start_time, end_time, update_time, score, notes,
+	legal_hold, anonymized_at, scenario_revision_id, configuration, station_id,
+	started_at, paused_at, paused_total, transitions, trainee_name, scheduled_for, trainee_id, score_breakdown`
+
+func scanSession(row rowScanner) (*sessions.Session, error) {
+	var (
+		session    sessions.Session
+		startTime  string
+		endTime    sql.NullString
+		updateTime string
+		score      sql.NullInt64
+		anonymized sql.NullString
+		config     string
+		startedAt  sql.NullString
+		pausedAt   sql.NullString
+		history    string
+		scheduled  sql.NullString
+		traineeID  sql.NullString
+		breakdown  string
+	)
+	err := row.Scan(&session.ID, &session.ScenarioID, &session.AvatarID, &session.ObserverID,
+		&session.Status, &startTime, &endTime, &updateTime, &score, &session.Notes,
+		&session.LegalHold, &anonymized, &session.ScenarioRevisionID, &config, &session.StationID,
+		&startedAt, &pausedAt, &session.PausedTotal, &history, &session.TraineeName, &scheduled, &traineeID, &breakdown)
+	if err != nil {
+		return nil, err
+	}
+
+	session.TraineeID = traineeID.String
+	if session.StartTime, err = parseSQLiteTime(startTime); err != nil {
+		return nil, err
+	}
+	if session.UpdateTime, err = parseSQLiteTime(updateTime); err != nil {
+		return nil, err
+	}
+	if endTime.Valid {
+		t, err := parseSQLiteTime(endTime.String)
+		if err != nil {
+			return nil, err
+		}
+		session.EndTime = &t
+	}
+	if score.Valid {
+		value := int(score.Int64)
+		session.Score = &value
+	}
+	if anonymized.Valid {
+		t, err := parseSQLiteTime(anonymized.String)
+		if err != nil {
+			return nil, err
+		}
+		session.AnonymizedAt = &t
+	}
+	if config != "" {
+		session.Configuration = &sessions.Configuration{}
+		if err := json.Unmarshal([]byte(config), session.Configuration); err != nil {
+			return nil, fmt.Errorf("decoding configuration of session %s: %w", session.ID, err)
+		}
+	}
+	if session.StartedAt, err = parseNullSQLiteTime(startedAt); err != nil {
+		return nil, err
+	}
+	if session.PausedAt, err = parseNullSQLiteTime(pausedAt); err != nil {
+		return nil, err
+	}
+	if session.ScheduledFor, err = parseNullSQLiteTime(scheduled); err != nil {
+		return nil, err
+	}
+	if history != "" {
+		if err := json.Unmarshal([]byte(history), &session.Transitions); err != nil {
+			return nil, fmt.Errorf("decoding transitions of session %s: %w", session.ID, err)
+		}
+	}
+	if breakdown != "" {
+		if err := json.Unmarshal([]byte(breakdown), &session.ScoreBreakdown); err != nil {
+			return nil, fmt.Errorf("decoding score breakdown of session %s: %w", session.ID, err)
+		}
+	}
+	return &session, nil
+}
+
+// parseNullSQLiteTime parses an optional time column
+func parseNullSQLiteTime(value sql.NullString) (*time.Time, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+	t, err := parseSQLiteTime(value.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// nullSQLiteTime formats an optional time for a nullable column
+func nullSQLiteTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return formatSQLiteTime(*t)
+}
+
+// nullSQLiteString stores an empty reference as NULL, which its foreign key accepts
+func nullSQLiteString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// encodeTransitions returns the JSON stored in the transitions column
+func encodeTransitions(list []sessions.Transition) (string, error) {
+	if len(list) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// encodeBreakdown returns the JSON stored in the score_breakdown column
+func encodeBreakdown(breakdown []sessions.CriterionScore) (string, error) {
+	if len(breakdown) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(breakdown)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func insertSession(db execer, session *sessions.Session) error {
+	var endTime, score, anonymized interface{}
+	config := ""
+	if session.EndTime != nil {
+		endTime = formatSQLiteTime(*session.EndTime)
+	}
+	if session.Score != nil {
+		score = *session.Score
+	}
+	if session.AnonymizedAt != nil {
+		anonymized = formatSQLiteTime(*session.AnonymizedAt)
+	}
+	if session.Configuration != nil {
+		data, err := json.Marshal(session.Configuration)
+		if err != nil {
+			return err
+		}
+		config = string(data)
+	}
+	history, err := encodeTransitions(session.Transitions)
+	if err != nil {
+		return err
+	}
+	breakdown, err := encodeBreakdown(session.ScoreBreakdown)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO sessions (`+sessionColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		session.ID, session.ScenarioID, session.AvatarID, session.ObserverID, session.Status,
+		formatSQLiteTime(session.StartTime), endTime, formatSQLiteTime(session.UpdateTime), score, session.Notes,
+		boolToInt(session.LegalHold), anonymized, session.ScenarioRevisionID, config, session.StationID,
+		nullSQLiteTime(session.StartedAt), nullSQLiteTime(session.PausedAt), int64(session.PausedTotal), history,
+		session.TraineeName, nullSQLiteTime(session.ScheduledFor), nullSQLiteString(session.TraineeID), breakdown)
+	return err
+}
+
+// query returns the sessions matching where, newest first, with suffix appended after the order
+func (s *SQLiteSessionStore) query(where, suffix string, args ...interface{}) []*sessions.Session {
+	rows, err := s.db.Query(`SELECT `+sessionColumns+` FROM sessions `+where+` ORDER BY start_time DESC `+suffix, args...)
+	if err != nil {
+		log.Printf("Error querying sessions: %v", err)
+		return []*sessions.Session{}
+	}
+	defer rows.Close()
+
+	result := make([]*sessions.Session, 0)
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			log.Printf("Error scanning session: %v", err)
+			continue
+		}
+		result = append(result, session)
+	}
+	return result
+}
+
+// GetAll returns all sessions, newest first
+func (s *SQLiteSessionStore) GetAll() []*sessions.Session {
+	return s.query("", "")
+}
+
+// GetRecent returns the n most recent sessions
+func (s *SQLiteSessionStore) GetRecent(n int) []*sessions.Session {
+	return s.query("", "LIMIT ?", n)
+}
+
+// ListByTrainee returns the sessions of a trainee, newest first
+func (s *SQLiteSessionStore) ListByTrainee(traineeID string) []*sessions.Session {
+	return s.query("WHERE trainee_id = ?", "", traineeID)
+}
+
+// sessionSortExpressions maps the sort columns of sessions.Query to the SQL they order by
+var sessionSortExpressions = map[string]string{
+	sessions.SortCreated:  "start_time",
+	sessions.SortID:       "id",
+	sessions.SortTrainee:  "trainee_name COLLATE NOCASE",
+	sessions.SortScenario: "(CASE WHEN configuration = '' THEN scenario_id ELSE json_extract(configuration, '$.scenario.name') END) COLLATE NOCASE",
+	sessions.SortStation:  "station_id",
+	sessions.SortStatus:   "status",
+	sessions.SortScore:    "score",
+	// The active time of Session.GetDuration, in nanoseconds; the parameter is the current time
+	sessions.SortDuration: `(CASE WHEN started_at IS NULL AND transitions != '' THEN 0
+		ELSE MAX(0, (julianday(COALESCE(end_time, paused_at, ?)) - julianday(COALESCE(started_at, start_time))) * 86400e9 - paused_total) END)`,
+}
+
+// Query returns the page of sessions selected by q. Filtering, sorting and paging
+// all happen in the database.
+func (s *SQLiteSessionStore) Query(q sessions.Query) sessions.Page {
+	q = q.Normalize()
+	page := sessions.Page{Sessions: []*sessions.Session{}, Query: q}
+
+	where := []string{"1 = 1"}
+	args := []interface{}{}
+	filter := func(clause string, value interface{}) {
+		where = append(where, clause)
+		args = append(args, value)
+	}
+	if q.Status != "" {
+		filter("status = ?", q.Status)
+	}
+	if q.ScenarioID != "" {
+		filter("scenario_id = ?", q.ScenarioID)
+	}
+	if q.AvatarID != "" {
+		filter("avatar_id = ?", q.AvatarID)
+	}
+	if q.ObserverID != "" {
+		filter("observer_id = ?", q.ObserverID)
+	}
+	if q.StationID != "" {
+		filter("station_id = ?", q.StationID)
+	}
+	if q.TraineeID != "" {
+		filter("trainee_id = ?", q.TraineeID)
+	}
+	if !q.From.IsZero() {
+		filter("start_time >= ?", formatSQLiteTime(q.From))
+	}
+	if !q.To.IsZero() {
+		filter("start_time < ?", formatSQLiteTime(q.Until()))
+	}
+	conditions := strings.Join(where, " AND ")
+
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM sessions WHERE `+conditions, args...).Scan(&page.Total); err != nil {
+		log.Printf("Error counting sessions: %v", err)
+		return page
+	}
+
+	direction := "ASC"
+	if q.Desc {
+		direction = "DESC"
+	}
+	if q.Sort == sessions.SortDuration {
+		args = append(args, formatSQLiteTime(time.Now()))
+	}
+	args = append(args, q.PageSize, q.Offset())
+
+	rows, err := s.db.Query(`SELECT `+sessionColumns+` FROM sessions WHERE `+conditions+`
+		ORDER BY `+sessionSortExpressions[q.Sort]+` `+direction+`, start_time DESC, id DESC LIMIT ? OFFSET ?`, args...)
+	if err != nil {
+		log.Printf("Error querying sessions: %v", err)
+		return page
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			log.Printf("Error scanning session: %v", err)
+			continue
+		}
+		page.Sessions = append(page.Sessions, session)
+	}
+	return page
+}
+
+// GetByID returns a session by ID
+func (s *SQLiteSessionStore) GetByID(id string) (*sessions.Session, error) {
+	session, err := scanSession(s.db.QueryRow(`SELECT `+sessionColumns+` FROM sessions WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSessionNotFound
+	}
+	return session, err
+}
+
+// Create starts a new pending session from the references and configuration filled in on session.
+// Sessions with a ScheduledFor time are created scheduled instead.
+func (s *SQLiteSessionStore) Create(session sessions.Session) (*sessions.Session, error) {
+	if session.Configuration == nil {
+		return nil, ErrInvalidSession
+	}
+
+	now := time.Now()
+	created := createdTransition(&session, now)
+	session.ID = newTimestampID("session_")
+	session.Status = created.To
+	session.StartTime = now
+	session.UpdateTime = now
+	session.Transitions = []sessions.Transition{created}
+
+	if err := insertSession(s.db, &session); err != nil {
+		return nil, fmt.Errorf("inserting session: %w", err)
+	}
+	return &session, nil
+}
+
+// Transition moves a session to t.To, recording who changed it and why.
+// Changes the state machine does not allow fail with ErrIllegalTransition. The update only
+// applies while the session still has the status it was read with, so a concurrent change
+// is retried against the new status instead of being overwritten.
+func (s *SQLiteSessionStore) Transition(id string, t sessions.Transition) (*sessions.Session, error) {
+	for attempt := 0; attempt < 3; attempt++ {
+		session, err := s.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		from := session.Status
+		if err := applyTransition(session, t, time.Now()); err != nil {
+			return nil, err
+		}
+		history, err := encodeTransitions(session.Transitions)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := s.db.Exec(`UPDATE sessions SET status = ?, update_time = ?, end_time = ?, started_at = ?,
+			paused_at = ?, paused_total = ?, transitions = ? WHERE id = ? AND status = ?`,
+			session.Status, formatSQLiteTime(session.UpdateTime), nullSQLiteTime(session.EndTime),
+			nullSQLiteTime(session.StartedAt), nullSQLiteTime(session.PausedAt), int64(session.PausedTotal),
+			history, id, from)
+		if err != nil {
+			return nil, fmt.Errorf("updating session: %w", err)
+		}
+		if affected, err := res.RowsAffected(); err == nil && affected == 1 {
+			return session, nil
+		}
+	}
+	return nil, fmt.Errorf("updating session %s: status kept changing concurrently", id)
+}
+
+// Delete removes a session
+func (s *SQLiteSessionStore) Delete(id string) error {
+	res, err := s.db.Exec(`DELETE FROM sessions WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("deleting session: %w", err)
+	}
+	return requireAffected(res, ErrSessionNotFound)
+}
+
+// SetLegalHold places a session on legal hold, or releases it
+func (s *SQLiteSessionStore) SetLegalHold(id string, hold bool) error {
+	res, err := s.db.Exec(`UPDATE sessions SET legal_hold = ?, update_time = ? WHERE id = ?`,
+		boolToInt(hold), formatSQLiteTime(time.Now()), id)
+	if err != nil {
+		return fmt.Errorf("updating legal hold: %w", err)
+	}
+	return requireAffected(res, ErrSessionNotFound)
+}
+
+// SetStation moves a session that has not been sent yet to another station
+func (s *SQLiteSessionStore) SetStation(id, stationID string) error {
+	res, err := s.db.Exec(`UPDATE sessions SET station_id = ?, update_time = ? WHERE id = ? AND status IN (?, ?)`,
+		stationID, formatSQLiteTime(time.Now()), id, sessions.StatusScheduled, sessions.StatusPending)
+	if err != nil {
+		return fmt.Errorf("updating station: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 1 {
+		return nil
+	}
+	session, err := s.GetByID(id)
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: session %s is already %s", ErrIllegalTransition, id, session.Status)
+}
+
+// SetScore records the final score of a session and, when it was scored with a rubric,
+// the score of each success criterion
+func (s *SQLiteSessionStore) SetScore(id string, score int, breakdown []sessions.CriterionScore) error {
+	encoded, err := encodeBreakdown(breakdown)
+	if err != nil {
+		return err
+	}
+	res, err := s.db.Exec(`UPDATE sessions SET score = ?, score_breakdown = ?, update_time = ? WHERE id = ?`,
+		score, encoded, formatSQLiteTime(time.Now()), id)
+	if err != nil {
+		return fmt.Errorf("updating score: %w", err)
+	}
+	return requireAffected(res, ErrSessionNotFound)
+}
+
+// Anonymize removes the personal data of a session, keeping what is needed for statistics
+func (s *SQLiteSessionStore) Anonymize(id string) error {
+	res, err := s.db.Exec(`UPDATE sessions SET notes = '', trainee_name = '', trainee_id = NULL, anonymized_at = ? WHERE id = ?`,
+		formatSQLiteTime(time.Now()), id)
+	if err != nil {
+		return fmt.Errorf("anonymizing session: %w", err)
+	}
+	return requireAffected(res, ErrSessionNotFound)
+}
+
+// Settings sections stored in the settings table
+const (
+	settingsSectionLLM      = "llm"
+	settingsSectionGeneral  = "general"
+	settingsSectionStations = "stations"
+)
+
+// SQLiteSettingsStore implements SettingsRepository on top of SQLite.
+// Each settings section is stored as a JSON document keyed by its name.
+type SQLiteSettingsStore struct {
+	db *sql.DB
+
+	// stationsMu makes changing the station list a single step
+	stationsMu sync.Mutex
+}
+
+// NewSQLiteSettingsStore creates a settings store backed by db
+func NewSQLiteSettingsStore(db *sql.DB) *SQLiteSettingsStore {
+	return &SQLiteSettingsStore{db: db}
+}
+
+// load decodes a settings section into v, leaving v untouched when the section is missing
+func (s *SQLiteSettingsStore) load(section string, v interface{}) {
+	var value string
+	err := s.db.QueryRow(`SELECT value FROM settings WHERE section = ?`, section).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return
+	}
+	if err != nil {
+		log.Printf("Error reading %s settings: %v", section, err)
+		return
+	}
+	if err := json.Unmarshal([]byte(value), v); err != nil {
+		log.Printf("Error decoding %s settings: %v", section, err)
+	}
+}
+
+// save stores v as the JSON document for a settings section
+func (s *SQLiteSettingsStore) save(section string, v interface{}) error {
+	return saveSettingsSection(s.db, section, v)
+}
+
+// saveSettingsSection upserts the JSON document for a settings section using db
+func saveSettingsSection(db execer, section string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO settings (section, value) VALUES (?, ?)
+		ON CONFLICT(section) DO UPDATE SET value = excluded.value`, section, string(data))
+	return err
+}
+
+// GetLLMSettings returns the current LLM settings
+func (s *SQLiteSettingsStore) GetLLMSettings() settings.LLMSettings {
+	llmSettings := DefaultLLMSettings()
+	s.load(settingsSectionLLM, &llmSettings)
+	return llmSettings
+}
+
+// GetGeneralSettings returns the current general settings
+func (s *SQLiteSettingsStore) GetGeneralSettings() settings.GeneralSettings {
+	generalSettings := DefaultGeneralSettings()
+	s.load(settingsSectionGeneral, &generalSettings)
+	return generalSettings
+}
+
+// GetStations returns the configured VR stations
+func (s *SQLiteSettingsStore) GetStations() []settings.Station {
+	stations := DefaultStations()
+	s.load(settingsSectionStations, &stations)
+	return stations
+}
+
+// UpdateStations replaces the configured VR stations
+func (s *SQLiteSettingsStore) UpdateStations(stations []settings.Station) error {
+	s.stationsMu.Lock()
+	defer s.stationsMu.Unlock()
+	return s.saveStations(stations)
+}
+
+// ChangeStations replaces the configured VR stations with what change makes of them,
+// holding the store lock so concurrent changes cannot overwrite each other
+func (s *SQLiteSettingsStore) ChangeStations(change func(stations []settings.Station) ([]settings.Station, error)) error {
+	s.stationsMu.Lock()
+	defer s.stationsMu.Unlock()
+
+	changed, err := change(s.GetStations())
+	if err != nil {
+		return err
+	}
+	return s.saveStations(changed)
+}
+
+func (s *SQLiteSettingsStore) saveStations(stations []settings.Station) error {
+	if stations == nil {
+		stations = []settings.Station{}
+	}
+	return s.save(settingsSectionStations, stations)
+}
+
+// UpdateLLMSettings updates the LLM settings
+func (s *SQLiteSettingsStore) UpdateLLMSettings(newSettings settings.LLMSettings) error {
+	return s.save(settingsSectionLLM, newSettings)
+}
+
+// UpdateGeneralSettings updates the general settings
+func (s *SQLiteSettingsStore) UpdateGeneralSettings(newSettings settings.GeneralSettings) error {
+	return s.save(settingsSectionGeneral, newSettings)
+}
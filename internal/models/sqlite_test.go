@@ -0,0 +1,323 @@
+// internal/models/sqlite_test.go
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/saladinomario/vr-training-admin/internal/config"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+)
+
+// openSQLiteAt opens a new database with foreign keys enforced and the migrations up to
+// version applied, as an installation of that version left it
+func openSQLiteAt(t *testing.T, version int) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "admin.db")+"?_pragma=foreign_keys(1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	for _, m := range sqliteMigrations {
+		if m.Version > version {
+			break
+		}
+		if _, err := m.Apply(tx); err != nil {
+			t.Fatalf("migration to version %d: %v", m.Version, err)
+		}
+	}
+	if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, version)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// execAll runs statements, failing the test on the first error
+func execAll(t *testing.T, db *sql.DB, statements ...string) {
+	t.Helper()
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+}
+
+func TestSQLiteMigrationChecksSessionTrainees(t *testing.T) {
+	db := openSQLiteAt(t, 13)
+	execAll(t, db,
+		`INSERT INTO scenarios (id, name) VALUES ('scenario_1', 'Fire drill')`,
+		`INSERT INTO avatars (id, name) VALUES ('avatar_1', 'Alex')`,
+		`INSERT INTO observers (id, name) VALUES ('observer_1', 'Coach')`,
+		`INSERT INTO trainees (id, name, employee_id) VALUES ('trainee_1', 'Sam', 'E1')`,
+	)
+	for id, traineeID := range map[string]string{"session_kept": "trainee_1", "session_legacy": "", "session_dangling": "trainee_gone"} {
+		execAll(t, db, fmt.Sprintf(`INSERT INTO sessions (id, scenario_id, avatar_id, observer_id, status, start_time, update_time, trainee_id)
+			VALUES ('%s', 'scenario_1', 'avatar_1', 'observer_1', 'completed', '2026-01-01T00:00:00.000000000Z', '2026-01-01T00:00:00.000000000Z', '%s')`, id, traineeID))
+	}
+
+	report, err := migrateSQLite(db, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.FromVersion != 13 || len(report.Changes) != 2 {
+		t.Errorf("report = %+v, want the version 14 migration clearing one dangling trainee", report)
+	}
+
+	store := NewSQLiteSessionStore(db)
+	want := map[string]string{"session_kept": "trainee_1", "session_legacy": "", "session_dangling": ""}
+	for id, traineeID := range want {
+		session, err := store.GetByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if session.TraineeID != traineeID {
+			t.Errorf("%s has trainee %q, want %q", id, session.TraineeID, traineeID)
+		}
+	}
+	var nulls int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sessions WHERE trainee_id IS NULL`).Scan(&nulls); err != nil {
+		t.Fatal(err)
+	}
+	if nulls != 2 {
+		t.Errorf("%d sessions have a NULL trainee, want 2", nulls)
+	}
+
+	// The trainee of a session can no longer be deleted, nor can a session name an unknown one
+	if err := NewSQLiteTraineeStore(db).Delete("trainee_1"); err == nil {
+		t.Error("deleted a trainee that a session refers to")
+	}
+	if _, err := db.Exec(`UPDATE sessions SET trainee_id = 'trainee_gone' WHERE id = 'session_legacy'`); err == nil {
+		t.Error("a session was given a trainee that does not exist")
+	}
+	if err := store.Anonymize("session_kept"); err != nil {
+		t.Errorf("anonymizing a session: %v", err)
+	}
+}
+
+func TestSQLiteRestoreKeepsTraineeReferences(t *testing.T) {
+	stores, err := OpenStores(config.Config{DataDir: t.TempDir(), Storage: config.StorageSQLite})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stores.Close()
+
+	snap := testSnapshot()
+	untracked := *snap.Sessions[0]
+	untracked.ID, untracked.TraineeID = "session_2", ""
+	snap.Sessions = append(snap.Sessions, &untracked)
+	if err := stores.Restore(snap); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	for _, want := range snap.Sessions {
+		session, err := stores.Sessions.GetByID(want.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if session.TraineeID != want.TraineeID {
+			t.Errorf("%s has trainee %q, want %q", want.ID, session.TraineeID, want.TraineeID)
+		}
+	}
+	if err := DeleteTrainee(stores.Trainees, stores.Sessions, "trainee_1"); err == nil {
+		t.Error("deleted the trainee of a restored session")
+	}
+}
+
+func TestSQLiteMigrationsFromEveryVersion(t *testing.T) {
+	latest := sqliteMigrations[len(sqliteMigrations)-1].Version
+
+	// fixtures hold data in the layout of the versions from since to until, and check what
+	// the migrations made of it
+	fixtures := []struct {
+		name         string
+		since, until int
+		statements   []string
+		check        func(t *testing.T, db *sql.DB)
+	}{
+		{
+			name: "settings with Go field names", since: 1, until: 1,
+			statements: []string{
+				`INSERT INTO settings (section, value) VALUES ('llm', '{"APIKey": "sk-1", "Model": "gpt-4"}')`,
+				`INSERT INTO settings (section, value) VALUES ('general', '{"ApplicationName": "Academy", "SessionTimeout": 45}')`,
+			},
+			check: func(t *testing.T, db *sql.DB) {
+				store := NewSQLiteSettingsStore(db)
+				if llm := store.GetLLMSettings(); llm.APIKey != "sk-1" || llm.Model != "gpt-4" {
+					t.Errorf("LLM settings = %+v, want the renamed fields", llm)
+				}
+				if general := store.GetGeneralSettings(); general.ApplicationName != "Academy" || general.SessionTimeout != 45 {
+					t.Errorf("general settings = %+v, want the renamed fields", general)
+				}
+			},
+		},
+		{
+			name: "single Unreal Engine endpoint", since: 2, until: 7,
+			statements: []string{
+				`INSERT INTO settings (section, value) VALUES ('general', '{"applicationName": "Academy", "unrealEndpoint": "http://bay1:9000/api"}')`,
+			},
+			check: func(t *testing.T, db *sql.DB) {
+				stations := NewSQLiteSettingsStore(db).GetStations()
+				if len(stations) != 1 || stations[0].Endpoint != "http://bay1:9000/api" {
+					t.Errorf("stations = %+v, want one at the former Unreal Engine endpoint", stations)
+				}
+				var general string
+				if err := db.QueryRow(`SELECT value FROM settings WHERE section = 'general'`).Scan(&general); err != nil {
+					t.Fatal(err)
+				}
+				if strings.Contains(general, "unrealEndpoint") || !strings.Contains(general, "Academy") {
+					t.Errorf("general settings = %s, want the endpoint removed and the rest kept", general)
+				}
+			},
+		},
+		{
+			name: "trainee named on the session", since: 11, until: 11,
+			statements: []string{`UPDATE sessions SET trainee_name = 'Sam' WHERE id = 'session_1'`},
+			check: func(t *testing.T, db *sql.DB) {
+				session, err := NewSQLiteSessionStore(db).GetByID("session_1")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if session.TraineeName != "Sam" || session.TraineeID != "" {
+					t.Errorf("session trainee = %q (%q), want Sam without a record", session.TraineeName, session.TraineeID)
+				}
+			},
+		},
+		{
+			name: "trainee record", since: 12, until: 13,
+			statements: []string{
+				`INSERT INTO trainees (id, name, employee_id) VALUES ('trainee_1', 'Sam', 'E1')`,
+				`UPDATE sessions SET trainee_id = 'trainee_1' WHERE id = 'session_1'`,
+			},
+			check: func(t *testing.T, db *sql.DB) {
+				session, err := NewSQLiteSessionStore(db).GetByID("session_1")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if session.TraineeID != "trainee_1" {
+					t.Errorf("session trainee = %q, want trainee_1", session.TraineeID)
+				}
+			},
+		},
+	}
+
+	for from := 0; from < latest; from++ {
+		t.Run(fmt.Sprintf("from version %d", from), func(t *testing.T) {
+			db := openSQLiteAt(t, from)
+			if from > 0 {
+				execAll(t, db,
+					`INSERT INTO scenarios (id, name) VALUES ('scenario_1', 'Fire drill')`,
+					`INSERT INTO avatars (id, name) VALUES ('avatar_1', 'Alex')`,
+					`INSERT INTO observers (id, name) VALUES ('observer_1', 'Coach')`,
+					`INSERT INTO sessions (id, scenario_id, avatar_id, observer_id, status, start_time, update_time)
+						VALUES ('session_1', 'scenario_1', 'avatar_1', 'observer_1', 'completed', '2026-01-01T00:00:00.000000000Z', '2026-01-01T00:00:00.000000000Z')`,
+				)
+			}
+			for _, fixture := range fixtures {
+				if fixture.since <= from && from <= fixture.until {
+					execAll(t, db, fixture.statements...)
+				}
+			}
+
+			report, err := migrateSQLite(db, false)
+			if err != nil {
+				t.Fatalf("migrateSQLite: %v", err)
+			}
+			if report.FromVersion != from || report.ToVersion != latest {
+				t.Errorf("report goes from version %d to %d, want %d to %d", report.FromVersion, report.ToVersion, from, latest)
+			}
+			if applied := countPrefixed(report.Changes, "v"); applied != latest-from {
+				t.Errorf("report lists %d migrations, want %d", applied, latest-from)
+			}
+			var version int
+			if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+				t.Fatal(err)
+			}
+			if version != latest {
+				t.Errorf("user_version = %d, want %d", version, latest)
+			}
+			if from == 0 {
+				return
+			}
+
+			session, err := NewSQLiteSessionStore(db).GetByID("session_1")
+			if err != nil {
+				t.Fatalf("reading the migrated session: %v", err)
+			}
+			if session.Status != sessions.StatusCompleted || session.ScenarioID != "scenario_1" || session.LegalHold {
+				t.Errorf("migrated session = %+v", session)
+			}
+			for _, fixture := range fixtures {
+				if fixture.since <= from && from <= fixture.until {
+					t.Run(fixture.name, func(t *testing.T) { fixture.check(t, db) })
+				}
+			}
+		})
+	}
+}
+
+func TestSQLiteMigrationDryRunWritesNothing(t *testing.T) {
+	db := openSQLiteAt(t, 1)
+	execAll(t, db, `INSERT INTO settings (section, value) VALUES ('llm', '{"APIKey": "sk-1"}')`)
+
+	report, err := migrateSQLite(db, true)
+	if err != nil {
+		t.Fatalf("migrateSQLite: %v", err)
+	}
+	if !report.Pending() || !containsString(report.Changes, "  llm.APIKey -> apiKey") {
+		t.Errorf("report = %+v, want the pending settings rename", report)
+	}
+
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if version != 1 {
+		t.Errorf("user_version = %d after a dry run, want 1", version)
+	}
+	var llm string
+	if err := db.QueryRow(`SELECT value FROM settings WHERE section = 'llm'`).Scan(&llm); err != nil {
+		t.Fatal(err)
+	}
+	if llm != `{"APIKey": "sk-1"}` {
+		t.Errorf("LLM settings = %s after a dry run, want them unchanged", llm)
+	}
+	var tables int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name IN ('trainees', 'ue_outbox')`).Scan(&tables); err != nil {
+		t.Fatal(err)
+	}
+	if tables != 0 {
+		t.Error("a dry run created tables")
+	}
+
+	path := filepath.Join(t.TempDir(), "admin.db")
+	if _, err := migrateSQLiteFile(path, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("a dry run created the database: %v", err)
+	}
+}
+
+// countPrefixed counts the entries of list starting with prefix
+func countPrefixed(list []string, prefix string) int {
+	n := 0
+	for _, s := range list {
+		if strings.HasPrefix(s, prefix) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,175 @@
+// internal/models/sqlite_trainees.go
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/trainees"
+)
+
+// SQLiteTraineeStore implements TraineeRepository on top of SQLite
+type SQLiteTraineeStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteTraineeStore creates a trainee store backed by db
+func NewSQLiteTraineeStore(db *sql.DB) *SQLiteTraineeStore {
+	return &SQLiteTraineeStore{db: db}
+}
+
+const traineeColumns = `id, name, employee_id, department, language, accessibility_needs, archived`
+
+func scanTrainee(row rowScanner) (trainees.Trainee, error) {
+	var trainee trainees.Trainee
+	var needs string
+	err := row.Scan(&trainee.ID, &trainee.Name, &trainee.EmployeeID, &trainee.Department,
+		&trainee.Language, &needs, &trainee.Archived)
+	if err != nil {
+		return trainee, err
+	}
+	if err := json.Unmarshal([]byte(needs), &trainee.AccessibilityNeeds); err != nil {
+		return trainee, fmt.Errorf("decoding accessibility needs: %w", err)
+	}
+	return trainee, nil
+}
+
+func insertTrainee(db execer, trainee trainees.Trainee) error {
+	needs, err := encodeStringList(trainee.AccessibilityNeeds)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO trainees (`+traineeColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		trainee.ID, trainee.Name, trainee.EmployeeID, trainee.Department, trainee.Language, needs,
+		boolToInt(trainee.Archived))
+	return err
+}
+
+func (s *SQLiteTraineeStore) query(where string, args ...interface{}) []trainees.Trainee {
+	rows, err := s.db.Query(`SELECT `+traineeColumns+` FROM trainees `+where+` ORDER BY name`, args...)
+	if err != nil {
+		log.Printf("Error querying trainees: %v", err)
+		return []trainees.Trainee{}
+	}
+	defer rows.Close()
+
+	result := make([]trainees.Trainee, 0)
+	for rows.Next() {
+		trainee, err := scanTrainee(rows)
+		if err != nil {
+			log.Printf("Error scanning trainee: %v", err)
+			continue
+		}
+		result = append(result, trainee)
+	}
+	return result
+}
+
+// validate checks the required fields and that no other trainee than id has the same employee ID
+func (s *SQLiteTraineeStore) validate(id string, trainee trainees.Trainee) error {
+	if trainee.Name == "" || trainee.EmployeeID == "" {
+		return ErrInvalidTrainee
+	}
+	var count int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM trainees WHERE employee_id = ? COLLATE NOCASE AND id <> ?`,
+		trainee.EmployeeID, id).Scan(&count); err != nil {
+		return fmt.Errorf("checking employee ID: %w", err)
+	}
+	if count > 0 {
+		return ErrDuplicateEmployeeID
+	}
+	return nil
+}
+
+// GetAll returns all trainees
+func (s *SQLiteTraineeStore) GetAll() []trainees.Trainee {
+	return s.query("")
+}
+
+// GetByID returns a trainee by its ID
+func (s *SQLiteTraineeStore) GetByID(id string) (trainees.Trainee, error) {
+	trainee, err := scanTrainee(s.db.QueryRow(`SELECT `+traineeColumns+` FROM trainees WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return trainees.Trainee{}, ErrTraineeNotFound
+	}
+	return trainee, err
+}
+
+// Create adds a new trainee
+func (s *SQLiteTraineeStore) Create(trainee trainees.Trainee) (trainees.Trainee, error) {
+	if err := s.validate("", trainee); err != nil {
+		return trainees.Trainee{}, err
+	}
+
+	trainee.ID = generateTraineeID()
+	if err := insertTrainee(s.db, trainee); err != nil {
+		return trainees.Trainee{}, fmt.Errorf("inserting trainee: %w", err)
+	}
+	return trainee, nil
+}
+
+// Insert adds a trainee under its own ID, as done when importing data
+func (s *SQLiteTraineeStore) Insert(trainee trainees.Trainee) error {
+	if trainee.ID == "" {
+		return ErrInvalidTrainee
+	}
+	if _, err := s.GetByID(trainee.ID); err == nil {
+		return ErrTraineeExists
+	}
+	if err := s.validate(trainee.ID, trainee); err != nil {
+		return err
+	}
+	if err := insertTrainee(s.db, trainee); err != nil {
+		return fmt.Errorf("inserting trainee: %w", err)
+	}
+	return nil
+}
+
+// Update modifies an existing trainee
+func (s *SQLiteTraineeStore) Update(id string, trainee trainees.Trainee) error {
+	if err := s.validate(id, trainee); err != nil {
+		return err
+	}
+
+	needs, err := encodeStringList(trainee.AccessibilityNeeds)
+	if err != nil {
+		return err
+	}
+	res, err := s.db.Exec(`UPDATE trainees SET name = ?, employee_id = ?, department = ?, language = ?,
+		accessibility_needs = ? WHERE id = ?`,
+		trainee.Name, trainee.EmployeeID, trainee.Department, trainee.Language, needs, id)
+	if err != nil {
+		return fmt.Errorf("updating trainee: %w", err)
+	}
+	return requireAffected(res, ErrTraineeNotFound)
+}
+
+// Delete removes a trainee
+func (s *SQLiteTraineeStore) Delete(id string) error {
+	res, err := s.db.Exec(`DELETE FROM trainees WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("deleting trainee: %w", err)
+	}
+	return requireAffected(res, ErrTraineeNotFound)
+}
+
+// SetArchived archives a trainee, or brings it back
+func (s *SQLiteTraineeStore) SetArchived(id string, archived bool) error {
+	res, err := s.db.Exec(`UPDATE trainees SET archived = ? WHERE id = ?`, boolToInt(archived), id)
+	if err != nil {
+		return fmt.Errorf("archiving trainee: %w", err)
+	}
+	return requireAffected(res, ErrTraineeNotFound)
+}
+
+// Search looks for trainees whose name, employee ID or department matches the query
+func (s *SQLiteTraineeStore) Search(query string) []trainees.Trainee {
+	if query == "" {
+		return s.GetAll()
+	}
+	pattern := likePattern(query)
+	return s.query(`WHERE name LIKE ? ESCAPE '\' OR employee_id LIKE ? ESCAPE '\' OR department LIKE ? ESCAPE '\'`, pattern, pattern, pattern)
+}
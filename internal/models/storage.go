@@ -0,0 +1,263 @@
+// internal/models/storage.go
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/saladinomario/vr-training-admin/internal/config"
+	"github.com/saladinomario/vr-training-admin/templates/components/avatars"
+	"github.com/saladinomario/vr-training-admin/templates/components/observers"
+	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+	"github.com/saladinomario/vr-training-admin/templates/components/settings"
+	"github.com/saladinomario/vr-training-admin/templates/components/trainees"
+)
+
+// ScenarioRepository is the storage contract for scenarios
+type ScenarioRepository interface {
+	GetAll() []scenarios.Scenario
+	GetByID(id string) (scenarios.Scenario, error)
+	Create(scenario scenarios.Scenario) (scenarios.Scenario, error)
+	Insert(scenario scenarios.Scenario) error
+	Update(id string, scenario scenarios.Scenario) error
+	Delete(id string) error
+	SetArchived(id string, archived bool) error
+	Search(query string) []scenarios.Scenario
+}
+
+// ScenarioRevisionRepository is the storage contract for the immutable revisions of scenarios
+type ScenarioRevisionRepository interface {
+	GetAll() []scenarios.Revision
+	ListByScenario(scenarioID string) []scenarios.Revision
+	GetByID(id string) (scenarios.Revision, error)
+	Add(rev scenarios.Revision) (scenarios.Revision, error)
+}
+
+// AvatarRepository is the storage contract for avatars
+type AvatarRepository interface {
+	GetAll() []avatars.Avatar
+	GetByID(id string) (avatars.Avatar, error)
+	Create(avatar avatars.Avatar) (avatars.Avatar, error)
+	Insert(avatar avatars.Avatar) error
+	Update(id string, avatar avatars.Avatar) error
+	Delete(id string) error
+	SetArchived(id string, archived bool) error
+	Search(query string) []avatars.Avatar
+}
+
+// ObserverRepository is the storage contract for observers
+type ObserverRepository interface {
+	GetAll() []observers.Observer
+	GetByID(id string) (observers.Observer, error)
+	Create(observer observers.Observer) (observers.Observer, error)
+	Insert(observer observers.Observer) error
+	Update(id string, observer observers.Observer) error
+	Delete(id string) error
+	SetArchived(id string, archived bool) error
+	Search(query string) []observers.Observer
+}
+
+// TraineeRepository is the storage contract for trainees
+type TraineeRepository interface {
+	GetAll() []trainees.Trainee
+	GetByID(id string) (trainees.Trainee, error)
+	Create(trainee trainees.Trainee) (trainees.Trainee, error)
+	Insert(trainee trainees.Trainee) error
+	Update(id string, trainee trainees.Trainee) error
+	Delete(id string) error
+	SetArchived(id string, archived bool) error
+	Search(query string) []trainees.Trainee
+}
+
+// SessionRepository is the storage contract for training sessions
+type SessionRepository interface {
+	GetAll() []*sessions.Session
+	GetRecent(n int) []*sessions.Session
+	ListByTrainee(traineeID string) []*sessions.Session
+	Query(q sessions.Query) sessions.Page
+	GetByID(id string) (*sessions.Session, error)
+	Create(session sessions.Session) (*sessions.Session, error)
+	Transition(id string, t sessions.Transition) (*sessions.Session, error)
+	Delete(id string) error
+	SetLegalHold(id string, hold bool) error
+	SetStation(id, stationID string) error
+	SetScore(id string, score int, breakdown []sessions.CriterionScore) error
+	Anonymize(id string) error
+}
+
+// EventRepository is the storage contract for the event logs stations report for sessions
+type EventRepository interface {
+	GetAll() []sessions.Event
+	ListBySession(sessionID string) []sessions.Event
+	Append(events []sessions.Event) ([]sessions.Event, error)
+	DeleteBySession(sessionID string) (int, error)
+}
+
+// SettingsRepository is the storage contract for application settings
+type SettingsRepository interface {
+	GetLLMSettings() settings.LLMSettings
+	GetGeneralSettings() settings.GeneralSettings
+	UpdateLLMSettings(newSettings settings.LLMSettings) error
+	UpdateGeneralSettings(newSettings settings.GeneralSettings) error
+	GetStations() []settings.Station
+	UpdateStations(stations []settings.Station) error
+	ChangeStations(change func(stations []settings.Station) ([]settings.Station, error)) error
+}
+
+// OutboxRepository is the storage contract for pending Unreal Engine deliveries
+type OutboxRepository interface {
+	GetAll() []Delivery
+	Add(d Delivery) (Delivery, error)
+	Update(d Delivery) error
+	Delete(id string) error
+}
+
+// Stores groups every store used by the application
+type Stores struct {
+	Scenarios         ScenarioRepository
+	ScenarioRevisions ScenarioRevisionRepository
+	Avatars           AvatarRepository
+	Observers         ObserverRepository
+	Trainees          TraineeRepository
+	Sessions          SessionRepository
+	Settings          SettingsRepository
+	Outbox            OutboxRepository
+	Events            EventRepository
+
+	db *sql.DB
+	// files maps document kinds to the files of the stores that persist to one
+	files map[string]string
+}
+
+// Close releases the resources held by the backend
+func (s *Stores) Close() error {
+	if s.db != nil {
+		return s.db.Close()
+	}
+	return nil
+}
+
+// OpenStores creates the stores for the configured backend
+func OpenStores(cfg config.Config) (*Stores, error) {
+	if err := os.MkdirAll(cfg.DataDir, 0755); err != nil {
+		return nil, fmt.Errorf("creating data directory: %w", err)
+	}
+	log.Printf("Using %s storage in %s", cfg.Storage, cfg.DataDir)
+
+	if cfg.Storage == config.StorageSQLite {
+		return openSQLiteStores(filepath.Join(cfg.DataDir, "admin.db"))
+	}
+
+	path := func(kind string) string {
+		return filepath.Join(cfg.DataDir, documentFiles[kind])
+	}
+	stores := &Stores{
+		Sessions: NewSessionStore(path(DocumentSessions)),
+		Settings: NewSettingsStore(path(DocumentSettings)),
+		files: map[string]string{
+			DocumentSessions: path(DocumentSessions),
+			DocumentSettings: path(DocumentSettings),
+		},
+	}
+
+	switch cfg.Storage {
+	case config.StorageMemory:
+		stores.Scenarios = NewScenarioStore()
+		stores.ScenarioRevisions = NewScenarioRevisionStore()
+		stores.Avatars = NewAvatarStore()
+		stores.Observers = NewObserverStore()
+		stores.Trainees = NewTraineeStore()
+		stores.Outbox = NewOutboxStore()
+		stores.Events = NewEventStore()
+	case config.StorageFile:
+		scenarioStore, err := NewFileScenarioStore(path(DocumentScenarios))
+		if err != nil {
+			return nil, err
+		}
+		revisionStore, err := NewFileScenarioRevisionStore(path(DocumentScenarioRevisions))
+		if err != nil {
+			return nil, err
+		}
+		avatarStore, err := NewFileAvatarStore(path(DocumentAvatars))
+		if err != nil {
+			return nil, err
+		}
+		observerStore, err := NewFileObserverStore(path(DocumentObservers))
+		if err != nil {
+			return nil, err
+		}
+		traineeStore, err := NewFileTraineeStore(path(DocumentTrainees))
+		if err != nil {
+			return nil, err
+		}
+		outboxStore, err := NewFileOutboxStore(path(DocumentOutbox))
+		if err != nil {
+			return nil, err
+		}
+		eventStore, err := NewFileEventStore(path(DocumentSessionEvents))
+		if err != nil {
+			return nil, err
+		}
+		for _, kind := range []string{DocumentScenarios, DocumentScenarioRevisions, DocumentAvatars, DocumentObservers, DocumentTrainees, DocumentOutbox, DocumentSessionEvents} {
+			stores.files[kind] = path(kind)
+		}
+		stores.Scenarios = scenarioStore
+		stores.ScenarioRevisions = revisionStore
+		stores.Avatars = avatarStore
+		stores.Observers = observerStore
+		stores.Trainees = traineeStore
+		stores.Outbox = outboxStore
+		stores.Events = eventStore
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Storage)
+	}
+
+	return stores, nil
+}
+
+// readJSONFile decodes the JSON file at path into v.
+// It reports whether the file existed.
+func readJSONFile(path string, v interface{}) (bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return true, fmt.Errorf("decoding %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// changeListFile applies a change to an in-memory store and writes the list it then holds
+// to path. When the write fails the store gets the list it held before back, so it never
+// keeps a change the file does not have. The caller serializes changes to the store.
+func changeListFile[T any](path string, getAll func() []T, replaceAll func([]T) error, apply func() error) error {
+	previous := getAll()
+	if err := apply(); err != nil {
+		return err
+	}
+	if err := writeJSONFile(path, newListDocument(getAll())); err != nil {
+		if restoreErr := replaceAll(previous); restoreErr != nil {
+			log.Printf("Error restoring %s after a failed write: %v", path, restoreErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// writeJSONFile encodes v as indented JSON and atomically writes it to path
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
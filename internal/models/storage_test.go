@@ -0,0 +1,166 @@
+// internal/models/storage_test.go
+package models
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/avatars"
+	"github.com/saladinomario/vr-training-admin/templates/components/observers"
+	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
+	"github.com/saladinomario/vr-training-admin/templates/components/trainees"
+)
+
+// contentOf describes the content of a store independently of the order GetAll returns
+func contentOf[T any](t *testing.T, list []T, id func(T) string) string {
+	t.Helper()
+	byID := make(map[string]T, len(list))
+	for _, item := range list {
+		byID[id(item)] = item
+	}
+	data, err := json.Marshal(byID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestFileStoresKeepMemoryWhenSaveFails(t *testing.T) {
+	tests := []struct {
+		name string
+		// open creates a store at path holding one item and returns its content and the
+		// changes to attempt once the file can no longer be written
+		open func(t *testing.T, path string) (content func() string, changes map[string]func() error)
+	}{
+		{"avatars", func(t *testing.T, path string) (func() string, map[string]func() error) {
+			store, err := NewFileAvatarStore(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			avatar, err := store.Create(avatars.Avatar{Name: "Alex"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			renamed := avatar
+			renamed.Name = "Sam"
+			return func() string { return contentOf(t, store.GetAll(), func(a avatars.Avatar) string { return a.ID }) },
+				map[string]func() error{
+					"create":  func() error { _, err := store.Create(avatars.Avatar{Name: "Kim"}); return err },
+					"update":  func() error { return store.Update(avatar.ID, renamed) },
+					"archive": func() error { return store.SetArchived(avatar.ID, true) },
+					"delete":  func() error { return store.Delete(avatar.ID) },
+				}
+		}},
+		{"scenarios", func(t *testing.T, path string) (func() string, map[string]func() error) {
+			store, err := NewFileScenarioStore(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			scenario, err := store.Create(scenarios.Scenario{Name: "Fire drill"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			renamed := scenario
+			renamed.Name = "Flood drill"
+			return func() string { return contentOf(t, store.GetAll(), func(s scenarios.Scenario) string { return s.ID }) },
+				map[string]func() error{
+					"create":  func() error { _, err := store.Create(scenarios.Scenario{Name: "Queue"}); return err },
+					"update":  func() error { return store.Update(scenario.ID, renamed) },
+					"archive": func() error { return store.SetArchived(scenario.ID, true) },
+					"delete":  func() error { return store.Delete(scenario.ID) },
+				}
+		}},
+		{"observers", func(t *testing.T, path string) (func() string, map[string]func() error) {
+			store, err := NewFileObserverStore(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			observer, err := store.Create(observers.Observer{Name: "Coach"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			renamed := observer
+			renamed.Name = "Mentor"
+			return func() string { return contentOf(t, store.GetAll(), func(o observers.Observer) string { return o.ID }) },
+				map[string]func() error{
+					"create":  func() error { _, err := store.Create(observers.Observer{Name: "Judge"}); return err },
+					"update":  func() error { return store.Update(observer.ID, renamed) },
+					"archive": func() error { return store.SetArchived(observer.ID, true) },
+					"delete":  func() error { return store.Delete(observer.ID) },
+				}
+		}},
+		{"trainees", func(t *testing.T, path string) (func() string, map[string]func() error) {
+			store, err := NewFileTraineeStore(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			trainee, err := store.Create(trainees.Trainee{Name: "Sam", EmployeeID: "T-1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			renamed := trainee
+			renamed.Name = "Sam Lee"
+			return func() string { return contentOf(t, store.GetAll(), func(tr trainees.Trainee) string { return tr.ID }) },
+				map[string]func() error{
+					"create":  func() error { _, err := store.Create(trainees.Trainee{Name: "Kim", EmployeeID: "T-2"}); return err },
+					"update":  func() error { return store.Update(trainee.ID, renamed) },
+					"archive": func() error { return store.SetArchived(trainee.ID, true) },
+					"delete":  func() error { return store.Delete(trainee.ID) },
+				}
+		}},
+		{"outbox", func(t *testing.T, path string) (func() string, map[string]func() error) {
+			store, err := NewFileOutboxStore(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			delivery, err := store.Add(Delivery{SessionID: "session_1", Payload: json.RawMessage(`{}`)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			retried := delivery
+			retried.Attempts = 1
+			return func() string { return contentOf(t, store.GetAll(), func(d Delivery) string { return d.ID }) },
+				map[string]func() error{
+					"add":    func() error { _, err := store.Add(Delivery{SessionID: "session_2"}); return err },
+					"update": func() error { return store.Update(retried) },
+					"delete": func() error { return store.Delete(delivery.ID) },
+				}
+		}},
+		{"scenario revisions", func(t *testing.T, path string) (func() string, map[string]func() error) {
+			store, err := NewFileScenarioRevisionStore(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := store.Add(scenarios.Revision{ScenarioID: "scenario_1"}); err != nil {
+				t.Fatal(err)
+			}
+			return func() string { return contentOf(t, store.GetAll(), func(r scenarios.Revision) string { return r.ID }) },
+				map[string]func() error{
+					"add": func() error { _, err := store.Add(scenarios.Revision{ScenarioID: "scenario_1"}); return err },
+				}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "data", "store.json")
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			content, changes := tt.open(t, path)
+			before := content()
+
+			breakDataDir(t, path)
+			for name, change := range changes {
+				if err := change(); err == nil {
+					t.Errorf("%s succeeded although the file could not be written", name)
+				}
+				if content() != before {
+					t.Errorf("a failed %s changed the store", name)
+				}
+			}
+		})
+	}
+}
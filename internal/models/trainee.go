@@ -0,0 +1,299 @@
+// internal/models/trainee.go
+package models
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/trainees"
+)
+
+var (
+	ErrTraineeNotFound     = errors.New("trainee not found")
+	ErrInvalidTrainee      = errors.New("invalid trainee data: name and employee ID are required")
+	ErrTraineeExists       = errors.New("trainee already exists")
+	ErrDuplicateEmployeeID = errors.New("employee ID is already used by another trainee")
+)
+
+// TraineeStore implements an in-memory storage for trainees
+type TraineeStore struct {
+	trainees map[string]trainees.Trainee
+	mu       sync.RWMutex
+}
+
+// NewTraineeStore creates a new trainee store with some sample data
+func NewTraineeStore() *TraineeStore {
+	store := &TraineeStore{
+		trainees: make(map[string]trainees.Trainee),
+	}
+
+	// Add some sample trainees
+	sampleTrainees := []trainees.Trainee{
+		{
+			ID:                 "1",
+			Name:               "Anna Becker",
+			EmployeeID:         "E-1001",
+			Department:         "Citizen Services",
+			Language:           "German",
+			AccessibilityNeeds: []string{},
+		},
+		{
+			ID:                 "2",
+			Name:               "Mehmet Yilmaz",
+			EmployeeID:         "E-1002",
+			Department:         "Registration Office",
+			Language:           "English",
+			AccessibilityNeeds: []string{"Seated play", "Subtitles"},
+		},
+	}
+
+	for _, trainee := range sampleTrainees {
+		store.trainees[trainee.ID] = trainee
+	}
+
+	return store
+}
+
+// GetAll returns all trainees
+func (s *TraineeStore) GetAll() []trainees.Trainee {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]trainees.Trainee, 0, len(s.trainees))
+	for _, trainee := range s.trainees {
+		result = append(result, trainee)
+	}
+	return result
+}
+
+// GetByID returns a trainee by its ID
+func (s *TraineeStore) GetByID(id string) (trainees.Trainee, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	trainee, ok := s.trainees[id]
+	if !ok {
+		return trainees.Trainee{}, ErrTraineeNotFound
+	}
+	return trainee, nil
+}
+
+// Create adds a new trainee
+func (s *TraineeStore) Create(trainee trainees.Trainee) (trainees.Trainee, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.validate("", trainee); err != nil {
+		return trainees.Trainee{}, err
+	}
+
+	trainee.ID = generateTraineeID()
+	s.trainees[trainee.ID] = trainee
+	return trainee, nil
+}
+
+// Insert adds a trainee under its own ID, as done when importing data
+func (s *TraineeStore) Insert(trainee trainees.Trainee) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if trainee.ID == "" {
+		return ErrInvalidTrainee
+	}
+	if _, ok := s.trainees[trainee.ID]; ok {
+		return ErrTraineeExists
+	}
+	if err := s.validate(trainee.ID, trainee); err != nil {
+		return err
+	}
+	s.trainees[trainee.ID] = trainee
+	return nil
+}
+
+// Update modifies an existing trainee
+func (s *TraineeStore) Update(id string, trainee trainees.Trainee) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.trainees[id]
+	if !ok {
+		return ErrTraineeNotFound
+	}
+	if err := s.validate(id, trainee); err != nil {
+		return err
+	}
+
+	// Preserve the ID and archive state
+	trainee.ID = id
+	trainee.Archived = existing.Archived
+	s.trainees[id] = trainee
+	return nil
+}
+
+// validate checks the required fields and that no other trainee than id has the same employee ID.
+// The caller must hold the lock.
+func (s *TraineeStore) validate(id string, trainee trainees.Trainee) error {
+	if trainee.Name == "" || trainee.EmployeeID == "" {
+		return ErrInvalidTrainee
+	}
+	for _, other := range s.trainees {
+		if other.ID != id && strings.EqualFold(other.EmployeeID, trainee.EmployeeID) {
+			return ErrDuplicateEmployeeID
+		}
+	}
+	return nil
+}
+
+// SetArchived archives a trainee, or brings it back
+func (s *TraineeStore) SetArchived(id string, archived bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	trainee, ok := s.trainees[id]
+	if !ok {
+		return ErrTraineeNotFound
+	}
+	trainee.Archived = archived
+	s.trainees[id] = trainee
+	return nil
+}
+
+// Delete removes a trainee
+func (s *TraineeStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.trainees[id]; !ok {
+		return ErrTraineeNotFound
+	}
+
+	delete(s.trainees, id)
+	return nil
+}
+
+// Search looks for trainees whose name, employee ID or department matches the query
+func (s *TraineeStore) Search(query string) []trainees.Trainee {
+	if query == "" {
+		return s.GetAll()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	query = strings.ToLower(query)
+	result := make([]trainees.Trainee, 0)
+
+	for _, trainee := range s.trainees {
+		if strings.Contains(strings.ToLower(trainee.Name), query) ||
+			strings.Contains(strings.ToLower(trainee.EmployeeID), query) ||
+			strings.Contains(strings.ToLower(trainee.Department), query) {
+			result = append(result, trainee)
+		}
+	}
+
+	return result
+}
+
+// replaceAll swaps the whole content of the store
+func (s *TraineeStore) replaceAll(list []trainees.Trainee) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.trainees = make(map[string]trainees.Trainee, len(list))
+	for _, trainee := range list {
+		s.trainees[trainee.ID] = trainee
+	}
+	return nil
+}
+
+// Helper to generate a simple ID
+func generateTraineeID() string {
+	return newTimestampID("trainee_")
+}
+
+// FileTraineeStore persists trainees to a JSON file on top of the in-memory store
+type FileTraineeStore struct {
+	*TraineeStore
+	filePath string
+	writeMu  sync.Mutex
+}
+
+// NewFileTraineeStore loads trainees from filePath, seeding it with the sample data when the file does not exist yet
+func NewFileTraineeStore(filePath string) (*FileTraineeStore, error) {
+	store := &FileTraineeStore{
+		TraineeStore: NewTraineeStore(),
+		filePath:     filePath,
+	}
+
+	var doc listDocument[trainees.Trainee]
+	found, err := readDocument(DocumentTrainees, filePath, &doc)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return store, store.save()
+	}
+	list := doc.Items
+
+	store.TraineeStore.replaceAll(list)
+	log.Printf("Loaded %d trainees from %s", len(list), filePath)
+
+	return store, nil
+}
+
+// Create adds a new trainee and persists the store
+func (s *FileTraineeStore) Create(trainee trainees.Trainee) (trainees.Trainee, error) {
+	var created trainees.Trainee
+	err := s.change(func() (err error) {
+		created, err = s.TraineeStore.Create(trainee)
+		return err
+	})
+	return created, err
+}
+
+// Insert adds a trainee under its own ID and persists the store
+func (s *FileTraineeStore) Insert(trainee trainees.Trainee) error {
+	return s.change(func() error {
+		return s.TraineeStore.Insert(trainee)
+	})
+}
+
+// Update modifies an existing trainee and persists the store
+func (s *FileTraineeStore) Update(id string, trainee trainees.Trainee) error {
+	return s.change(func() error {
+		return s.TraineeStore.Update(id, trainee)
+	})
+}
+
+// Delete removes a trainee and persists the store
+func (s *FileTraineeStore) Delete(id string) error {
+	return s.change(func() error {
+		return s.TraineeStore.Delete(id)
+	})
+}
+
+// SetArchived archives a trainee, or brings it back, and persists the store
+func (s *FileTraineeStore) SetArchived(id string, archived bool) error {
+	return s.change(func() error {
+		return s.TraineeStore.SetArchived(id, archived)
+	})
+}
+
+// change applies a change to the store and persists it, restoring the previous content
+// when the file cannot be written
+func (s *FileTraineeStore) change(apply func() error) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	return changeListFile(s.filePath, s.TraineeStore.GetAll, s.TraineeStore.replaceAll, apply)
+}
+
+// save writes a snapshot of the store to disk
+func (s *FileTraineeStore) save() error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	return writeJSONFile(s.filePath, newListDocument(s.GetAll()))
+}
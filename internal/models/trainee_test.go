@@ -0,0 +1,155 @@
+// internal/models/trainee_test.go
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/saladinomario/vr-training-admin/internal/config"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+	"github.com/saladinomario/vr-training-admin/templates/components/trainees"
+)
+
+// forEachBackend runs test against fresh stores of every storage backend
+func forEachBackend(t *testing.T, test func(t *testing.T, stores *Stores)) {
+	for _, storage := range []string{config.StorageMemory, config.StorageFile, config.StorageSQLite} {
+		t.Run(storage, func(t *testing.T) {
+			stores, err := OpenStores(config.Config{DataDir: t.TempDir(), Storage: storage})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer stores.Close()
+			test(t, stores)
+		})
+	}
+}
+
+func TestTraineeStore(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, stores *Stores) {
+		store := stores.Trainees
+		sam, err := store.Create(trainees.Trainee{Name: "Sam Lee", EmployeeID: "T-100", Department: "Front Desk"})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if sam.ID == "" {
+			t.Fatal("created trainee has no ID")
+		}
+		if got, err := store.GetByID(sam.ID); err != nil || got.Name != "Sam Lee" {
+			t.Errorf("GetByID = %+v, %v, want Sam Lee", got, err)
+		}
+
+		tests := []struct {
+			name    string
+			change  func() error
+			wantErr error
+		}{
+			{"create without a name", func() error {
+				_, err := store.Create(trainees.Trainee{EmployeeID: "T-200"})
+				return err
+			}, ErrInvalidTrainee},
+			{"create without an employee ID", func() error {
+				_, err := store.Create(trainees.Trainee{Name: "Kim"})
+				return err
+			}, ErrInvalidTrainee},
+			{"create with a taken employee ID", func() error {
+				_, err := store.Create(trainees.Trainee{Name: "Kim", EmployeeID: "t-100"})
+				return err
+			}, ErrDuplicateEmployeeID},
+			{"insert under a taken ID", func() error {
+				return store.Insert(trainees.Trainee{ID: sam.ID, Name: "Kim", EmployeeID: "T-300"})
+			}, ErrTraineeExists},
+			{"update an unknown trainee", func() error {
+				return store.Update("trainee_missing", trainees.Trainee{Name: "Kim", EmployeeID: "T-400"})
+			}, ErrTraineeNotFound},
+			{"update keeping the employee ID", func() error {
+				return store.Update(sam.ID, trainees.Trainee{Name: "Sam Lee", EmployeeID: "T-100", Department: "Archive"})
+			}, nil},
+			{"archive an unknown trainee", func() error {
+				return store.SetArchived("trainee_missing", true)
+			}, ErrTraineeNotFound},
+			{"delete an unknown trainee", func() error {
+				return store.Delete("trainee_missing")
+			}, ErrTraineeNotFound},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if err := tt.change(); !errors.Is(err, tt.wantErr) {
+					t.Errorf("got %v, want %v", err, tt.wantErr)
+				}
+			})
+		}
+
+		kim, err := store.Create(trainees.Trainee{Name: "Kim Park", EmployeeID: "T-500"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Update(kim.ID, trainees.Trainee{Name: "Kim Park", EmployeeID: "T-100"}); !errors.Is(err, ErrDuplicateEmployeeID) {
+			t.Errorf("taking the employee ID of another trainee: %v, want ErrDuplicateEmployeeID", err)
+		}
+
+		// Archiving survives an update, which cannot change it
+		if err := store.SetArchived(sam.ID, true); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Update(sam.ID, trainees.Trainee{Name: "Sam Lee", EmployeeID: "T-100", Department: "Archive"}); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := store.GetByID(sam.ID); !got.Archived || got.Department != "Archive" {
+			t.Errorf("updated trainee = %+v, want it archived in Archive", got)
+		}
+
+		if found := store.Search("archive"); len(found) != 1 || found[0].ID != sam.ID {
+			t.Errorf("Search(archive) = %+v, want Sam Lee", found)
+		}
+
+		if err := store.Delete(kim.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := store.GetByID(kim.ID); !errors.Is(err, ErrTraineeNotFound) {
+			t.Errorf("GetByID of a deleted trainee: %v, want ErrTraineeNotFound", err)
+		}
+	})
+}
+
+func TestDeleteTrainee(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, stores *Stores) {
+		busy, err := stores.Trainees.Create(trainees.Trainee{Name: "Sam Lee", EmployeeID: "T-100"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		idle, err := stores.Trainees.Create(trainees.Trainee{Name: "Kim Park", EmployeeID: "T-200"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		scenario, avatar, observer := stores.Scenarios.GetAll()[0], stores.Avatars.GetAll()[0], stores.Observers.GetAll()[0]
+		session, err := stores.Sessions.Create(sessions.Session{
+			ScenarioID:    scenario.ID,
+			AvatarID:      avatar.ID,
+			ObserverID:    observer.ID,
+			TraineeID:     busy.ID,
+			Configuration: &sessions.Configuration{Scenario: scenario, Avatar: avatar, Observer: observer},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = DeleteTrainee(stores.Trainees, stores.Sessions, busy.ID)
+		var inUse *InUseError
+		if !errors.As(err, &inUse) || len(inUse.Sessions) != 1 || inUse.Sessions[0] != session.ID {
+			t.Errorf("deleting a trainee with a session: %v, want it in use by %s", err, session.ID)
+		}
+		if _, err := stores.Trainees.GetByID(busy.ID); err != nil {
+			t.Errorf("the trainee in use was deleted: %v", err)
+		}
+
+		if err := DeleteTrainee(stores.Trainees, stores.Sessions, idle.ID); err != nil {
+			t.Errorf("deleting a trainee without sessions: %v", err)
+		}
+		if _, err := stores.Trainees.GetByID(idle.ID); !errors.Is(err, ErrTraineeNotFound) {
+			t.Errorf("the trainee without sessions is still there: %v", err)
+		}
+		if err := DeleteTrainee(stores.Trainees, stores.Sessions, "trainee_missing"); !errors.Is(err, ErrTraineeNotFound) {
+			t.Errorf("deleting an unknown trainee: %v, want ErrTraineeNotFound", err)
+		}
+	})
+}
@@ -0,0 +1,237 @@
+// internal/retention/retention.go
+package retention
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/internal/models"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+	"github.com/saladinomario/vr-training-admin/templates/components/settings"
+)
+
+// Kinds of data attached to a session
+const (
+	DataRecordings  = "recordings"
+	DataTranscripts = "transcripts"
+)
+
+// DefaultInterval is how often the worker applies the retention policy
+const DefaultInterval = time.Hour
+
+// SessionDataStore holds data attached to sessions, such as recordings or transcripts.
+// Stores register with the worker so their data is purged together with the session.
+type SessionDataStore interface {
+	// Kind returns one of DataRecordings or DataTranscripts
+	Kind() string
+	// PurgeSession removes all data of the session and returns the number of items removed
+	PurgeSession(sessionID string) (int, error)
+}
+
+// EventLog applies the policy to the session event logs, which hold the transcripts
+// of what the avatar, the trainee and the observer said during a session
+type EventLog struct {
+	events models.EventRepository
+}
+
+// NewEventLog wraps the event store so it can be registered with a worker
+func NewEventLog(events models.EventRepository) *EventLog {
+	return &EventLog{events: events}
+}
+
+// Kind returns DataTranscripts
+func (l *EventLog) Kind() string {
+	return DataTranscripts
+}
+
+// PurgeSession removes the event log of the session
+func (l *EventLog) PurgeSession(sessionID string) (int, error) {
+	return l.events.DeleteBySession(sessionID)
+}
+
+// Action describes what the worker did, or would do, to one session
+type Action struct {
+	SessionID string
+	Action    string // "delete", "anonymize" or "purge <kind>"
+	Reason    string
+	Items     int
+}
+
+// Report summarizes one run of the retention policy
+type Report struct {
+	RanAt   time.Time
+	Actions []Action
+	Held    int
+	Errors  []string
+}
+
+// Worker enforces GeneralSettings.DataRetentionDays, RecordSessions and StoreSessionData
+type Worker struct {
+	sessions models.SessionRepository
+	settings models.SettingsRepository
+
+	mu         sync.Mutex
+	dataStores []SessionDataStore
+	last       *Report
+
+	// now is replaceable so the policy can be evaluated at a fixed time
+	now func() time.Time
+}
+
+// NewWorker creates a retention worker for the given stores
+func NewWorker(sessionStore models.SessionRepository, settingsStore models.SettingsRepository) *Worker {
+	return &Worker{
+		sessions: sessionStore,
+		settings: settingsStore,
+		now:      time.Now,
+	}
+}
+
+// Register adds a store whose session data is purged by the retention policy
+func (w *Worker) Register(store SessionDataStore) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.dataStores = append(w.dataStores, store)
+}
+
+// LastReport returns the report of the most recent run, or nil before the first run
+func (w *Worker) LastReport() *Report {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.last
+}
+
+// Start runs the policy immediately and then every interval until ctx is cancelled
+func (w *Worker) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		w.RunOnce()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.RunOnce()
+			}
+		}
+	}()
+}
+
+// RunOnce applies the retention policy to every finished session:
+//   - sessions on legal hold are never touched
+//   - sessions that finished more than DataRetentionDays ago are deleted or anonymized
+//     according to RetentionAction, together with their recordings and transcripts
+//   - when RecordSessions is off, recordings of finished sessions are purged
+//   - when StoreSessionData is off, finished sessions are anonymized and their transcripts purged
+//
+// Active sessions are left alone. A DataRetentionDays of zero keeps sessions indefinitely.
+func (w *Worker) RunOnce() *Report {
+	generalSettings := w.settings.GetGeneralSettings()
+	now := w.now()
+	report := &Report{RanAt: now}
+
+	var cutoff time.Time
+	if generalSettings.DataRetentionDays > 0 {
+		cutoff = now.AddDate(0, 0, -generalSettings.DataRetentionDays)
+	}
+
+	for _, session := range w.sessions.GetAll() {
+		if session.IsActive() {
+			continue
+		}
+		if session.LegalHold {
+			report.Held++
+			continue
+		}
+
+		if !cutoff.IsZero() && session.FinishedAt().Before(cutoff) {
+			reason := fmt.Sprintf("older than %d days", generalSettings.DataRetentionDays)
+			w.expire(report, session, generalSettings.RetentionAction, reason)
+			continue
+		}
+
+		if !generalSettings.RecordSessions {
+			w.purgeData(report, session.ID, DataRecordings, "session recording is disabled")
+		}
+		if !generalSettings.StoreSessionData {
+			w.purgeData(report, session.ID, DataTranscripts, "storing session data is disabled")
+			if session.AnonymizedAt == nil {
+				w.anonymize(report, session.ID, "storing session data is disabled")
+			}
+		}
+	}
+
+	w.mu.Lock()
+	w.last = report
+	w.mu.Unlock()
+
+	w.logReport(report)
+	return report
+}
+
+// expire removes a session past the retention window, or anonymizes it
+func (w *Worker) expire(report *Report, session *sessions.Session, action, reason string) {
+	w.purgeData(report, session.ID, DataRecordings, reason)
+	w.purgeData(report, session.ID, DataTranscripts, reason)
+
+	if action == settings.RetentionAnonymize {
+		if session.AnonymizedAt == nil {
+			w.anonymize(report, session.ID, reason)
+		}
+		return
+	}
+
+	if err := w.sessions.Delete(session.ID); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("deleting session %s: %v", session.ID, err))
+		return
+	}
+	report.Actions = append(report.Actions, Action{SessionID: session.ID, Action: settings.RetentionDelete, Reason: reason, Items: 1})
+}
+
+func (w *Worker) anonymize(report *Report, sessionID, reason string) {
+	if err := w.sessions.Anonymize(sessionID); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("anonymizing session %s: %v", sessionID, err))
+		return
+	}
+	report.Actions = append(report.Actions, Action{SessionID: sessionID, Action: settings.RetentionAnonymize, Reason: reason, Items: 1})
+}
+
+// purgeData removes the data of kind attached to a session from every registered store
+func (w *Worker) purgeData(report *Report, sessionID, kind, reason string) {
+	w.mu.Lock()
+	stores := append([]SessionDataStore(nil), w.dataStores...)
+	w.mu.Unlock()
+
+	for _, store := range stores {
+		if store.Kind() != kind {
+			continue
+		}
+		n, err := store.PurgeSession(sessionID)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("purging %s of session %s: %v", kind, sessionID, err))
+			continue
+		}
+		if n > 0 {
+			report.Actions = append(report.Actions, Action{SessionID: sessionID, Action: "purge " + kind, Reason: reason, Items: n})
+		}
+	}
+}
+
+// logReport writes every purge to the log so there is an audit trail of what was removed
+func (w *Worker) logReport(report *Report) {
+	for _, action := range report.Actions {
+		log.Printf("Retention: %s session %s (%s, %d items)", action.Action, action.SessionID, action.Reason, action.Items)
+	}
+	for _, err := range report.Errors {
+		log.Printf("Retention error: %s", err)
+	}
+	if len(report.Actions) > 0 || len(report.Errors) > 0 {
+		log.Printf("Retention run finished: %d actions, %d sessions on legal hold, %d errors",
+			len(report.Actions), report.Held, len(report.Errors))
+	}
+}
@@ -0,0 +1,230 @@
+// internal/retention/retention_test.go
+package retention
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/internal/models"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+	"github.com/saladinomario/vr-training-admin/templates/components/settings"
+)
+
+// recordings is a SessionDataStore holding a number of recordings per session
+type recordings map[string]int
+
+func (r recordings) Kind() string {
+	return DataRecordings
+}
+
+func (r recordings) PurgeSession(sessionID string) (int, error) {
+	n := r[sessionID]
+	delete(r, sessionID)
+	return n, nil
+}
+
+// testWorker runs against real stores, with one recording and one event logged for every
+// session the test adds
+type testWorker struct {
+	*Worker
+	sessions   *models.SessionStore
+	events     *models.EventStore
+	recordings recordings
+}
+
+func newTestWorker(t *testing.T, change func(g *settings.GeneralSettings)) *testWorker {
+	t.Helper()
+	dir := t.TempDir()
+	tw := &testWorker{
+		sessions:   models.NewSessionStore(filepath.Join(dir, "sessions.json")),
+		events:     models.NewEventStore(),
+		recordings: recordings{},
+	}
+	settingsStore := models.NewSettingsStore(filepath.Join(dir, "settings.json"))
+	general := models.DefaultGeneralSettings()
+	general.DataRetentionDays = 30
+	general.RecordSessions = true
+	general.StoreSessionData = true
+	change(&general)
+	if err := settingsStore.UpdateGeneralSettings(general); err != nil {
+		t.Fatal(err)
+	}
+
+	tw.Worker = NewWorker(tw.sessions, settingsStore)
+	tw.Register(tw.recordings)
+	tw.Register(NewEventLog(tw.events))
+	return tw
+}
+
+// add creates a session, moves it through statuses and attaches its data
+func (tw *testWorker) add(t *testing.T, statuses ...string) *sessions.Session {
+	t.Helper()
+	session, err := tw.sessions.Create(sessions.Session{Configuration: &sessions.Configuration{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range statuses {
+		if session, err = tw.sessions.Transition(session.ID, sessions.Transition{To: status, Actor: sessions.ActorSystem}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.recordings[session.ID] = 1
+	if _, err := tw.events.Append([]sessions.Event{{SessionID: session.ID, ClientID: "c1", Type: sessions.EventStarted}}); err != nil {
+		t.Fatal(err)
+	}
+	return session
+}
+
+// at evaluates the policy at t
+func (tw *testWorker) at(t time.Time) {
+	tw.now = func() time.Time { return t }
+}
+
+var finished = []string{sessions.StatusStarting, sessions.StatusRunning, sessions.StatusCompleted}
+
+func TestRunOnceRetentionCutoff(t *testing.T) {
+	tests := []struct {
+		name       string
+		days       int
+		ageDays    int           // days since the session finished
+		extra      time.Duration // added to the age
+		action     string
+		kept       bool
+		anonymized bool
+	}{
+		{"within the window", 30, 29, 0, settings.RetentionDelete, true, false},
+		{"exactly at the cutoff", 30, 30, 0, settings.RetentionDelete, true, false},
+		{"past the window is deleted", 30, 30, time.Second, settings.RetentionDelete, false, false},
+		{"past the window is anonymized", 30, 30, time.Second, settings.RetentionAnonymize, true, true},
+		{"no retention period keeps sessions", 0, 3650, 0, settings.RetentionDelete, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := newTestWorker(t, func(g *settings.GeneralSettings) {
+				g.DataRetentionDays = tt.days
+				g.RetentionAction = tt.action
+			})
+			session := tw.add(t, finished...)
+			tw.at(session.FinishedAt().AddDate(0, 0, tt.ageDays).Add(tt.extra))
+
+			report := tw.RunOnce()
+
+			if len(report.Errors) > 0 {
+				t.Fatalf("errors: %v", report.Errors)
+			}
+			stored, err := tw.sessions.GetByID(session.ID)
+			if kept := err == nil; kept != tt.kept {
+				t.Fatalf("session kept: %t, want %t", kept, tt.kept)
+			}
+			if tt.kept && (stored.AnonymizedAt != nil) != tt.anonymized {
+				t.Errorf("session anonymized: %t, want %t", stored.AnonymizedAt != nil, tt.anonymized)
+			}
+
+			expired := !tt.kept || tt.anonymized
+			if purged := tw.recordings[session.ID] == 0; purged != expired {
+				t.Errorf("recording purged: %t, want %t", purged, expired)
+			}
+			if purged := len(tw.events.ListBySession(session.ID)) == 0; purged != expired {
+				t.Errorf("transcript purged: %t, want %t", purged, expired)
+			}
+			if expired && len(report.Actions) != 3 {
+				t.Errorf("actions = %+v, want the two purges and the %s", report.Actions, tt.action)
+			}
+			if !expired && len(report.Actions) != 0 {
+				t.Errorf("actions = %+v, want none", report.Actions)
+			}
+		})
+	}
+}
+
+func TestRunOnceLeavesHeldAndActiveSessionsAlone(t *testing.T) {
+	tw := newTestWorker(t, func(g *settings.GeneralSettings) {
+		g.RetentionAction = settings.RetentionDelete
+		g.RecordSessions = false
+		g.StoreSessionData = false
+	})
+	held := tw.add(t, finished...)
+	if err := tw.sessions.SetLegalHold(held.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	active := tw.add(t, sessions.StatusStarting, sessions.StatusRunning)
+	tw.at(time.Now().AddDate(1, 0, 0))
+
+	report := tw.RunOnce()
+
+	if report.Held != 1 {
+		t.Errorf("%d sessions reported on legal hold, want 1", report.Held)
+	}
+	if len(report.Actions) != 0 || len(report.Errors) != 0 {
+		t.Errorf("report = %+v, want nothing done", report)
+	}
+	for _, id := range []string{held.ID, active.ID} {
+		session, err := tw.sessions.GetByID(id)
+		if err != nil {
+			t.Fatalf("session %s was deleted: %v", id, err)
+		}
+		if session.AnonymizedAt != nil {
+			t.Errorf("session %s was anonymized", id)
+		}
+		if tw.recordings[id] != 1 || len(tw.events.ListBySession(id)) != 1 {
+			t.Errorf("the data of session %s was purged", id)
+		}
+	}
+}
+
+func TestRunOncePurgesDisabledData(t *testing.T) {
+	tests := []struct {
+		name             string
+		record, store    bool
+		recordingPurged  bool
+		transcriptPurged bool
+	}{
+		{"recording disabled", false, true, true, false},
+		{"storing session data disabled", true, false, false, true},
+		{"both enabled", true, true, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := newTestWorker(t, func(g *settings.GeneralSettings) {
+				g.RecordSessions = tt.record
+				g.StoreSessionData = tt.store
+			})
+			session := tw.add(t, finished...)
+
+			first := tw.RunOnce()
+
+			if purged := tw.recordings[session.ID] == 0; purged != tt.recordingPurged {
+				t.Errorf("recording purged: %t, want %t", purged, tt.recordingPurged)
+			}
+			if purged := len(tw.events.ListBySession(session.ID)) == 0; purged != tt.transcriptPurged {
+				t.Errorf("transcript purged: %t, want %t", purged, tt.transcriptPurged)
+			}
+			stored, err := tw.sessions.GetByID(session.ID)
+			if err != nil {
+				t.Fatalf("session was deleted: %v", err)
+			}
+			if anonymized := stored.AnonymizedAt != nil; anonymized != !tt.store {
+				t.Errorf("session anonymized: %t, want %t", anonymized, !tt.store)
+			}
+			// Without stored session data the session is anonymized as well as its transcript purged
+			if want := btoi(tt.recordingPurged) + 2*btoi(tt.transcriptPurged); len(first.Actions) != want {
+				t.Errorf("first run took %d actions, want %d", len(first.Actions), want)
+			}
+
+			// Nothing is left to do for the session on the next run
+			if second := tw.RunOnce(); len(second.Actions) != 0 {
+				t.Errorf("second run took actions %+v", second.Actions)
+			}
+		})
+	}
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,238 @@
+// internal/supervisor/supervisor.go
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/internal/models"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+	"github.com/saladinomario/vr-training-admin/templates/components/settings"
+)
+
+// DefaultInterval is how often the supervisor checks the sessions
+const DefaultInterval = 15 * time.Second
+
+// Notifier tells the station of a session that the supervisor finished it
+type Notifier func(session *sessions.Session)
+
+// Starter sends a queued session to its station, or to another free station when its
+// own is busy. It returns ErrNoFreeStation when no station can take the session yet.
+type Starter func(sessionID string) error
+
+// ErrNoFreeStation is returned by a Starter when the session has to stay queued
+var ErrNoFreeStation = errors.New("no free station")
+
+// Supervisor enforces GeneralSettings.SessionTimeout and MaxConcurrentSessions and
+// dispatches scheduled sessions. Sessions running longer than the timeout are failed or
+// completed according to TimeoutAction. Scheduled sessions join the queue once their
+// planned start time is reached. Pending sessions form the queue of sessions waiting for
+// a free slot or station; they are started in order whenever fewer than
+// MaxConcurrentSessions are running.
+type Supervisor struct {
+	sessions models.SessionRepository
+	settings models.SettingsRepository
+	notify   Notifier
+	start    Starter
+
+	wake chan struct{}
+
+	// admitMu makes checking for a free slot and taking it one step, both for new sessions
+	// and for queued sessions the supervisor starts
+	admitMu sync.Mutex
+
+	// now is replaceable so the policy can be evaluated at a fixed time
+	now func() time.Time
+}
+
+// New creates a supervisor for the given stores
+func New(sessionStore models.SessionRepository, settingsStore models.SettingsRepository, notify Notifier, start Starter) *Supervisor {
+	return &Supervisor{
+		sessions: sessionStore,
+		settings: settingsStore,
+		notify:   notify,
+		start:    start,
+		wake:     make(chan struct{}, 1),
+		now:      time.Now,
+	}
+}
+
+// Start checks the sessions immediately and then every interval, or sooner when woken,
+// until ctx is cancelled
+func (s *Supervisor) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		s.RunOnce()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-s.wake:
+			}
+			s.RunOnce()
+		}
+	}()
+}
+
+// Wake asks for a check as soon as possible, for example after a session finished
+// and freed a slot for the queue
+func (s *Supervisor) Wake() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Capacity returns how many sessions are running and the configured limit.
+// ok reports whether another session may start now.
+func (s *Supervisor) Capacity() (running, limit int, ok bool) {
+	limit = s.settings.GetGeneralSettings().MaxConcurrentSessions
+	running = CountRunning(s.sessions.GetAll())
+	return running, limit, limit <= 0 || running < limit
+}
+
+// Admit calls admit with the current capacity while no other session is admitted or
+// started from the queue. admit checks the slot and station it needs and takes them, by
+// creating a session that occupies them, before the next admission sees the capacity.
+func (s *Supervisor) Admit(admit func(running, limit int, ok bool) error) error {
+	s.admitMu.Lock()
+	defer s.admitMu.Unlock()
+	return admit(s.Capacity())
+}
+
+// CountRunning returns how many sessions occupy a slot: every active session
+// except the queued ones, which are still waiting to be sent to their station
+func CountRunning(list []*sessions.Session) int {
+	running := 0
+	for _, session := range list {
+		if session.IsActive() && !session.IsQueued() {
+			running++
+		}
+	}
+	return running
+}
+
+// RunOnce times out overdue sessions, queues the scheduled sessions that are due and
+// then starts queued sessions while slots are free
+func (s *Supervisor) RunOnce() {
+	generalSettings := s.settings.GetGeneralSettings()
+	now := s.now()
+	all := s.sessions.GetAll()
+
+	if generalSettings.SessionTimeout > 0 {
+		timeout := time.Duration(generalSettings.SessionTimeout) * time.Minute
+		for _, session := range all {
+			if session.IsActive() && !session.IsQueued() && now.Sub(began(session)) > timeout {
+				s.timeOut(session, generalSettings)
+			}
+		}
+		all = s.sessions.GetAll()
+	}
+
+	s.queueScheduled(all, now)
+
+	// The queue is read under the admission lock, so new sessions cannot take the free
+	// slots between counting and starting
+	s.admitMu.Lock()
+	defer s.admitMu.Unlock()
+	s.startQueued(s.sessions.GetAll(), generalSettings.MaxConcurrentSessions)
+}
+
+// queueScheduled moves the scheduled sessions whose planned start time is reached into the queue
+func (s *Supervisor) queueScheduled(all []*sessions.Session, now time.Time) {
+	for _, session := range all {
+		if session.Status != sessions.StatusScheduled || session.ScheduledFor == nil || session.ScheduledFor.After(now) {
+			continue
+		}
+		_, err := s.sessions.Transition(session.ID, sessions.Transition{
+			To:     sessions.StatusPending,
+			Actor:  sessions.ActorSystem,
+			Reason: "scheduled start time reached",
+		})
+		if err != nil {
+			// The session may have been cancelled since it was read
+			if !errors.Is(err, models.ErrIllegalTransition) {
+				log.Printf("Supervisor: error queueing scheduled session %s: %v", session.ID, err)
+			}
+			continue
+		}
+		log.Printf("Supervisor: scheduled session %s is due and queued", session.ID)
+	}
+}
+
+// timeOut finishes a session that ran past the timeout and tells its station.
+// Sessions that never started running cannot be completed and are failed instead.
+func (s *Supervisor) timeOut(session *sessions.Session, generalSettings settings.GeneralSettings) {
+	status := sessions.StatusFailed
+	if generalSettings.TimeoutAction == settings.TimeoutComplete && sessions.CanTransition(session.Status, sessions.StatusCompleted) {
+		status = sessions.StatusCompleted
+	}
+
+	updated, err := s.sessions.Transition(session.ID, sessions.Transition{
+		To:     status,
+		Actor:  sessions.ActorSystem,
+		Reason: fmt.Sprintf("exceeded the session timeout of %d minutes", generalSettings.SessionTimeout),
+	})
+	if err != nil {
+		// The session may have finished since it was read
+		if !errors.Is(err, models.ErrIllegalTransition) {
+			log.Printf("Supervisor: error timing out session %s: %v", session.ID, err)
+		}
+		return
+	}
+	log.Printf("Supervisor: session %s %s after exceeding the timeout of %d minutes", session.ID, status, generalSettings.SessionTimeout)
+	s.notify(updated)
+}
+
+// startQueued starts pending sessions in queue order while fewer than limit sessions run.
+// A limit of zero or less starts every pending session. Sessions no station can take
+// yet stay queued.
+func (s *Supervisor) startQueued(all []*sessions.Session, limit int) {
+	queued := make([]*sessions.Session, 0)
+	for _, session := range all {
+		if session.Status == sessions.StatusPending {
+			queued = append(queued, session)
+		}
+	}
+	sort.Slice(queued, func(i, j int) bool {
+		return queued[i].QueuedSince().Before(queued[j].QueuedSince())
+	})
+
+	running := CountRunning(all)
+	for _, session := range queued {
+		if limit > 0 && running >= limit {
+			return
+		}
+		if err := s.start(session.ID); err != nil {
+			// Another start may have picked it up already
+			if !errors.Is(err, models.ErrIllegalTransition) && !errors.Is(err, ErrNoFreeStation) {
+				log.Printf("Supervisor: error starting queued session %s: %v", session.ID, err)
+			}
+			continue
+		}
+		log.Printf("Supervisor: started queued session %s", session.ID)
+		running++
+	}
+}
+
+// began returns when a session started to occupy its station: when it first ran, or
+// when it was sent to the station if it never ran
+func began(session *sessions.Session) time.Time {
+	if session.StartedAt != nil {
+		return *session.StartedAt
+	}
+	for i := len(session.Transitions) - 1; i >= 0; i-- {
+		if session.Transitions[i].To == sessions.StatusStarting {
+			return session.Transitions[i].At
+		}
+	}
+	return session.StartTime
+}
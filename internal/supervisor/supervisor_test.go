@@ -0,0 +1,212 @@
+// internal/supervisor/supervisor_test.go
+package supervisor
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/internal/models"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+	"github.com/saladinomario/vr-training-admin/templates/components/settings"
+)
+
+// testSupervisor runs against real stores with a clock fixed an hour ahead, so sessions
+// created by the test have been running for an hour. Starting a session moves it to
+// starting, as sending it to its station does; notified collects finished sessions.
+type testSupervisor struct {
+	*Supervisor
+	sessions *models.SessionStore
+	settings *models.SettingsStore
+
+	mu       sync.Mutex
+	notified []string
+}
+
+func newTestSupervisor(t *testing.T, change func(g *settings.GeneralSettings)) *testSupervisor {
+	t.Helper()
+	dir := t.TempDir()
+	ts := &testSupervisor{
+		sessions: models.NewSessionStore(filepath.Join(dir, "sessions.json")),
+		settings: models.NewSettingsStore(filepath.Join(dir, "settings.json")),
+	}
+	general := models.DefaultGeneralSettings()
+	change(&general)
+	if err := ts.settings.UpdateGeneralSettings(general); err != nil {
+		t.Fatal(err)
+	}
+
+	notify := func(session *sessions.Session) {
+		ts.mu.Lock()
+		defer ts.mu.Unlock()
+		ts.notified = append(ts.notified, session.ID)
+	}
+	start := func(sessionID string) error {
+		_, err := ts.sessions.Transition(sessionID, sessions.Transition{To: sessions.StatusStarting, Actor: sessions.ActorSystem})
+		return err
+	}
+	ts.Supervisor = New(ts.sessions, ts.settings, notify, start)
+	now := time.Now().Add(time.Hour)
+	ts.Supervisor.now = func() time.Time { return now }
+	return ts
+}
+
+// create adds a session and moves it through statuses
+func (ts *testSupervisor) create(t *testing.T, statuses ...string) string {
+	t.Helper()
+	session, err := ts.sessions.Create(sessions.Session{Configuration: &sessions.Configuration{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range statuses {
+		if _, err := ts.sessions.Transition(session.ID, sessions.Transition{To: status, Actor: sessions.ActorSystem}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return session.ID
+}
+
+func (ts *testSupervisor) status(t *testing.T, id string) string {
+	t.Helper()
+	session, err := ts.sessions.GetByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return session.Status
+}
+
+func TestRunOnceTimesOutSessions(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeout  int
+		action   string
+		statuses []string
+		want     string
+	}{
+		{"running past the timeout fails", 30, settings.TimeoutFail, []string{sessions.StatusRunning}, sessions.StatusFailed},
+		{"running past the timeout completes", 30, settings.TimeoutComplete, []string{sessions.StatusRunning}, sessions.StatusCompleted},
+		{"paused past the timeout completes", 30, settings.TimeoutComplete, []string{sessions.StatusRunning, sessions.StatusPaused}, sessions.StatusCompleted},
+		{"never ran fails even when completing", 30, settings.TimeoutComplete, []string{sessions.StatusStarting}, sessions.StatusFailed},
+		{"within the timeout", 90, settings.TimeoutFail, []string{sessions.StatusRunning}, sessions.StatusRunning},
+		{"no timeout", 0, settings.TimeoutFail, []string{sessions.StatusRunning}, sessions.StatusRunning},
+		{"queued sessions do not time out", 30, settings.TimeoutFail, nil, sessions.StatusStarting},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestSupervisor(t, func(g *settings.GeneralSettings) {
+				g.SessionTimeout = tt.timeout
+				g.TimeoutAction = tt.action
+			})
+			id := ts.create(t, tt.statuses...)
+
+			ts.RunOnce()
+
+			if got := ts.status(t, id); got != tt.want {
+				t.Errorf("session is %s, want %s", got, tt.want)
+			}
+			timedOut := tt.want == sessions.StatusFailed || tt.want == sessions.StatusCompleted
+			if got := len(ts.notified); timedOut && got != 1 || !timedOut && got != 0 {
+				t.Errorf("station notified %d times", got)
+			}
+		})
+	}
+}
+
+func TestRunOnceStartsQueuedSessionsInOrder(t *testing.T) {
+	ts := newTestSupervisor(t, func(g *settings.GeneralSettings) {
+		g.MaxConcurrentSessions = 2
+		g.SessionTimeout = 0
+	})
+	running := ts.create(t, sessions.StatusRunning)
+	first := ts.create(t)
+	second := ts.create(t)
+
+	ts.RunOnce()
+	if got := ts.status(t, first); got != sessions.StatusStarting {
+		t.Errorf("oldest queued session is %s, want %s", got, sessions.StatusStarting)
+	}
+	if got := ts.status(t, second); got != sessions.StatusPending {
+		t.Errorf("queued session beyond the limit is %s, want %s", got, sessions.StatusPending)
+	}
+
+	if _, err := ts.sessions.Transition(running, sessions.Transition{To: sessions.StatusCompleted, Actor: sessions.ActorTrainer}); err != nil {
+		t.Fatal(err)
+	}
+	ts.RunOnce()
+	if got := ts.status(t, second); got != sessions.StatusStarting {
+		t.Errorf("queued session is %s after a slot freed up, want %s", got, sessions.StatusStarting)
+	}
+}
+
+func TestRunOnceQueuesDueScheduledSessions(t *testing.T) {
+	ts := newTestSupervisor(t, func(g *settings.GeneralSettings) {
+		g.MaxConcurrentSessions = 1
+		g.SessionTimeout = 0
+	})
+	now := ts.now()
+	due := now.Add(-time.Minute)
+	later := now.Add(time.Minute)
+
+	dueSession, err := ts.sessions.Create(sessions.Session{Configuration: &sessions.Configuration{}, ScheduledFor: &due})
+	if err != nil {
+		t.Fatal(err)
+	}
+	laterSession, err := ts.sessions.Create(sessions.Session{Configuration: &sessions.Configuration{}, ScheduledFor: &later})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts.RunOnce()
+	if got := ts.status(t, dueSession.ID); got != sessions.StatusStarting {
+		t.Errorf("due scheduled session is %s, want %s", got, sessions.StatusStarting)
+	}
+	if got := ts.status(t, laterSession.ID); got != sessions.StatusScheduled {
+		t.Errorf("later scheduled session is %s, want %s", got, sessions.StatusScheduled)
+	}
+}
+
+func TestAdmitTakesSlotsOneAtATime(t *testing.T) {
+	ts := newTestSupervisor(t, func(g *settings.GeneralSettings) {
+		g.MaxConcurrentSessions = 1
+	})
+
+	const requests = 10
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	admitted := 0
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := ts.Admit(func(running, limit int, ok bool) error {
+				if !ok {
+					return nil
+				}
+				session, err := ts.sessions.Create(sessions.Session{Configuration: &sessions.Configuration{}})
+				if err != nil {
+					return err
+				}
+				_, err = ts.sessions.Transition(session.ID, sessions.Transition{To: sessions.StatusStarting, Actor: sessions.ActorSystem})
+				if err == nil {
+					mu.Lock()
+					admitted++
+					mu.Unlock()
+				}
+				return err
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if admitted != 1 {
+		t.Errorf("%d sessions admitted with a limit of 1", admitted)
+	}
+	if running := CountRunning(ts.sessions.GetAll()); running != 1 {
+		t.Errorf("%d sessions running with a limit of 1", running)
+	}
+}
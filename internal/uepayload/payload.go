@@ -0,0 +1,218 @@
+// internal/uepayload/payload.go
+
+// Package uepayload defines the session payload the admin sends to Unreal Engine stations.
+// The JSON field names are part of the contract with the stations: within a version
+// fields may be added but never renamed, retyped or removed. Such changes need a new
+// Version and a new schema under schema/.
+package uepayload
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+)
+
+// Version is the payload version the admin sends
+const Version = 1
+
+// ErrIncompatible is returned for a station that does not support Version
+var ErrIncompatible = errors.New("station does not support the payload version")
+
+// SessionPayload describes a session to the station that runs it
+type SessionPayload struct {
+	Version   int       `json:"version"`
+	SessionID string    `json:"sessionId"`
+	StationID string    `json:"stationId,omitempty"`
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+	Scenario  Scenario  `json:"scenario"`
+	Avatar    Avatar    `json:"avatar"`
+	Observer  Observer  `json:"observer"`
+}
+
+// Scenario is the training scenario of the session
+type Scenario struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	Category        string `json:"category"`
+	Difficulty      int    `json:"difficulty"`
+	Duration        int    `json:"duration"` // minutes
+	Scene           string `json:"scene"`
+	BackgroundNoise bool   `json:"backgroundNoise"`
+	SuccessCriteria string `json:"successCriteria"`
+	Keywords        string `json:"keywords"`
+	// RubricItems lists the observer rubric items that score each success criterion
+	RubricItems map[string][]string `json:"rubricItems"`
+}
+
+// Avatar is the virtual character the trainee talks to
+type Avatar struct {
+	ID                  string `json:"id"`
+	Name                string `json:"name"`
+	Description         string `json:"description"`
+	PersonalityType     string `json:"personalityType"`
+	CommunicationStyle  string `json:"communicationStyle"`
+	KnowledgeLevel      int    `json:"knowledgeLevel"`
+	AggressivenessLevel int    `json:"aggressivenessLevel"`
+	PatienceLevel       int    `json:"patienceLevel"`
+	EmotionalReactivity int    `json:"emotionalReactivity"`
+	VoiceType           string `json:"voiceType"`
+	SpeakingSpeed       int    `json:"speakingSpeed"`
+	ImageURL            string `json:"imageUrl"`
+	Keywords            string `json:"keywords"`
+}
+
+// Observer is the virtual observer that gives feedback during the session
+type Observer struct {
+	ID                   string       `json:"id"`
+	Name                 string       `json:"name"`
+	Description          string       `json:"description"`
+	FeedbackStyle        string       `json:"feedbackStyle"`
+	InterventionLevel    int          `json:"interventionLevel"`
+	DetailLevel          int          `json:"detailLevel"`
+	FeedbackTone         string       `json:"feedbackTone"`
+	SuccessMetrics       string       `json:"successMetrics"`
+	InterventionTriggers []string     `json:"interventionTriggers"`
+	Active               bool         `json:"active"`
+	Rubric               []RubricItem `json:"rubric"`
+}
+
+// RubricItem is one item the observer rates the trainee on. The station reports the
+// ratings, from Min to Max, in the ratings of the completed event.
+type RubricItem struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Weight      int    `json:"weight"`
+	Min         int    `json:"min"`
+	Max         int    `json:"max"`
+}
+
+// New builds the payload of a session at time now
+func New(details *sessions.SessionDetails, now time.Time) SessionPayload {
+	scenario, avatar, observer := details.Scenario, details.Avatar, details.Observer
+
+	triggers := observer.InterventionTriggers
+	if triggers == nil {
+		triggers = []string{}
+	}
+	rubric := make([]RubricItem, len(observer.Rubric))
+	for i, item := range observer.Rubric {
+		rubric[i] = RubricItem{
+			ID:          item.ID,
+			Name:        item.Name,
+			Description: item.Description,
+			Weight:      item.Weight,
+			Min:         item.Min,
+			Max:         item.Max,
+		}
+	}
+	rubricItems := scenario.RubricItems
+	if rubricItems == nil {
+		rubricItems = map[string][]string{}
+	}
+
+	return SessionPayload{
+		Version:   Version,
+		SessionID: details.Session.ID,
+		StationID: details.Session.StationID,
+		Status:    details.Session.Status,
+		Timestamp: now.UTC().Truncate(time.Second),
+		Scenario: Scenario{
+			ID:              scenario.ID,
+			Name:            scenario.Name,
+			Description:     scenario.Description,
+			Category:        scenario.Category,
+			Difficulty:      scenario.Difficulty,
+			Duration:        scenario.Duration,
+			Scene:           scenario.Scene,
+			BackgroundNoise: scenario.BackgroundNoise,
+			SuccessCriteria: scenario.SuccessCriteria,
+			Keywords:        scenario.Keywords,
+			RubricItems:     rubricItems,
+		},
+		Avatar: Avatar{
+			ID:                  avatar.ID,
+			Name:                avatar.Name,
+			Description:         avatar.Description,
+			PersonalityType:     avatar.PersonalityType,
+			CommunicationStyle:  avatar.CommunicationStyle,
+			KnowledgeLevel:      avatar.KnowledgeLevel,
+			AggressivenessLevel: avatar.AggressivenessLevel,
+			PatienceLevel:       avatar.PatienceLevel,
+			EmotionalReactivity: avatar.EmotionalReactivity,
+			VoiceType:           avatar.VoiceType,
+			SpeakingSpeed:       avatar.SpeakingSpeed,
+			ImageURL:            avatar.ImageURL,
+			Keywords:            avatar.Keywords,
+		},
+		Observer: Observer{
+			ID:                   observer.ID,
+			Name:                 observer.Name,
+			Description:          observer.Description,
+			FeedbackStyle:        observer.FeedbackStyle,
+			InterventionLevel:    observer.InterventionLevel,
+			DetailLevel:          observer.DetailLevel,
+			FeedbackTone:         observer.FeedbackTone,
+			SuccessMetrics:       observer.SuccessMetrics,
+			InterventionTriggers: triggers,
+			Active:               observer.Active,
+			Rubric:               rubric,
+		},
+	}
+}
+
+// Compatible reports whether a station supporting versions can receive the payloads the admin sends
+func Compatible(versions []int) bool {
+	for _, v := range versions {
+		if v == Version {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckCompatible returns an ErrIncompatible error naming the versions a station supports
+func CheckCompatible(versions []int) error {
+	if Compatible(versions) {
+		return nil
+	}
+	return fmt.Errorf("%w: it supports %s, the admin sends version %d", ErrIncompatible, FormatVersions(versions), Version)
+}
+
+// ParseVersions parses a comma separated list of versions such as "1,2"
+func ParseVersions(s string) ([]int, error) {
+	versions := make([]int, 0)
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		v, err := strconv.Atoi(field)
+		if err != nil || v < 1 {
+			return nil, fmt.Errorf("invalid payload version %q", field)
+		}
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+// FormatVersions renders versions for messages, such as "versions 1, 2"
+func FormatVersions(versions []int) string {
+	if len(versions) == 0 {
+		return "no versions"
+	}
+	parts := make([]string, len(versions))
+	for i, v := range versions {
+		parts[i] = strconv.Itoa(v)
+	}
+	if len(parts) == 1 {
+		return "version " + parts[0]
+	}
+	return "versions " + strings.Join(parts, ", ")
+}
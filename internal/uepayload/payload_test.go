@@ -0,0 +1,295 @@
+// internal/uepayload/payload_test.go
+package uepayload
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/avatars"
+	"github.com/saladinomario/vr-training-admin/templates/components/observers"
+	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+)
+
+// schemaValidator checks JSON values against the subset of JSON Schema the payload
+// schemas use. Keywords outside that subset are reported rather than ignored, so a
+// schema change the validator does not understand fails the tests. Unlike JSON Schema,
+// properties the schema does not describe are errors too: every field the admin sends
+// must be documented for the stations.
+type schemaValidator struct {
+	root map[string]interface{}
+}
+
+// annotations are the keywords that do not constrain values
+var annotations = map[string]bool{"$schema": true, "$id": true, "$defs": true, "title": true, "description": true}
+
+func newSchemaValidator(t *testing.T, version int) schemaValidator {
+	t.Helper()
+	data, err := Schema(version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		t.Fatalf("schema v%d: %v", version, err)
+	}
+	return schemaValidator{root: root}
+}
+
+// validate returns the errors of value against schema, prefixed with the path of the
+// value in the document
+func (v schemaValidator) validate(schema map[string]interface{}, value interface{}, path string) []string {
+	var errs []string
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		def, ok := v.root["$defs"].(map[string]interface{})[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{})
+		if !ok {
+			fail("unresolved $ref %s", ref)
+			return errs
+		}
+		errs = append(errs, v.validate(def, value, path)...)
+	}
+
+	if typ, ok := schema["type"].(string); ok && !hasType(value, typ) {
+		fail("%v is not of type %s", value, typ)
+		return errs
+	}
+
+	for keyword, arg := range schema {
+		switch keyword {
+		case "$ref", "type", "properties", "additionalProperties":
+			// handled around the switch
+		case "const":
+			if !reflect.DeepEqual(value, arg) {
+				fail("%v is not %v", value, arg)
+			}
+		case "required":
+			object, _ := value.(map[string]interface{})
+			for _, name := range arg.([]interface{}) {
+				if _, ok := object[name.(string)]; !ok {
+					fail("missing required property %s", name)
+				}
+			}
+		case "minLength":
+			if s, ok := value.(string); ok && float64(len([]rune(s))) < arg.(float64) {
+				fail("%q is shorter than %v", s, arg)
+			}
+		case "minimum":
+			if n, ok := value.(float64); ok && n < arg.(float64) {
+				fail("%v is below the minimum %v", n, arg)
+			}
+		case "maximum":
+			if n, ok := value.(float64); ok && n > arg.(float64) {
+				fail("%v is above the maximum %v", n, arg)
+			}
+		case "format":
+			if s, ok := value.(string); ok && arg == "date-time" {
+				if _, err := time.Parse(time.RFC3339, s); err != nil {
+					fail("%q is not a date-time", s)
+				}
+			}
+		case "items":
+			items, _ := value.([]interface{})
+			for i, item := range items {
+				errs = append(errs, v.validate(arg.(map[string]interface{}), item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		default:
+			if !annotations[keyword] {
+				fail("unsupported schema keyword %s", keyword)
+			}
+		}
+	}
+
+	if object, ok := value.(map[string]interface{}); ok {
+		properties, _ := schema["properties"].(map[string]interface{})
+		additional, _ := schema["additionalProperties"].(map[string]interface{})
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := properties[name].(map[string]interface{}); ok {
+				errs = append(errs, v.validate(property, object[name], path+"."+name)...)
+			} else if additional != nil {
+				errs = append(errs, v.validate(additional, object[name], path+"."+name)...)
+			} else if schema["$ref"] == nil {
+				fail("property %s is not in the schema", name)
+			}
+		}
+	}
+	return errs
+}
+
+// hasType reports whether a decoded JSON value is of the JSON Schema type typ
+func hasType(value interface{}, typ string) bool {
+	switch typ {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	}
+	return false
+}
+
+// payloadDetails returns the details of a running session with every part of the
+// payload filled in
+func payloadDetails() *sessions.SessionDetails {
+	return &sessions.SessionDetails{
+		Session: sessions.Session{ID: "session_1", StationID: "station_default", Status: sessions.StatusStarting},
+		Scenario: scenarios.Scenario{
+			ID:              "scenario_1",
+			Name:            "Angry customer",
+			Description:     "A guest complains about a double charge",
+			Category:        "Complaint Handling",
+			Difficulty:      4,
+			Duration:        15,
+			Scene:           "HotelLobby",
+			BackgroundNoise: true,
+			SuccessCriteria: "Clear Communication,Effective Conflict Resolution",
+			Keywords:        "refund,apology",
+			RubricItems:     map[string][]string{"Clear Communication": {"rubric_tone", "rubric_clarity"}},
+		},
+		Avatar: avatars.Avatar{
+			ID:                  "avatar_1",
+			Name:                "Alex",
+			Description:         "Frequent traveller",
+			PersonalityType:     "Demanding",
+			CommunicationStyle:  "Direct",
+			KnowledgeLevel:      3,
+			AggressivenessLevel: 5,
+			PatienceLevel:       1,
+			EmotionalReactivity: 4,
+			VoiceType:           "Male, middle-aged",
+			SpeakingSpeed:       3,
+			ImageURL:            "/static/avatars/alex.png",
+			Keywords:            "business,loyalty",
+		},
+		Observer: observers.Observer{
+			ID:                   "observer_1",
+			Name:                 "Coach",
+			Description:          "Supportive trainer",
+			FeedbackStyle:        "Constructive",
+			InterventionLevel:    2,
+			DetailLevel:          4,
+			FeedbackTone:         "Encouraging",
+			SuccessMetrics:       "Resolution,Empathy",
+			InterventionTriggers: []string{"Trainee is silent"},
+			Active:               true,
+			Rubric: []observers.RubricItem{
+				{ID: "rubric_tone", Name: "Tone", Description: "Stays calm", Weight: 2, Min: 1, Max: 5},
+				{ID: "rubric_clarity", Name: "Clarity", Weight: 1, Min: 0, Max: 10},
+			},
+		},
+	}
+}
+
+// encodePayload returns the payload as stations decode it
+func encodePayload(t *testing.T, payload SessionPayload) map[string]interface{} {
+	t.Helper()
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestNewMatchesSchema(t *testing.T) {
+	validator := newSchemaValidator(t, Version)
+	now := time.Date(2026, 3, 2, 9, 30, 15, 500, time.FixedZone("CET", 3600))
+
+	tests := []struct {
+		name    string
+		details *sessions.SessionDetails
+	}{
+		{"every field set", payloadDetails()},
+		{"empty content and no station", &sessions.SessionDetails{
+			Session: sessions.Session{ID: "session_2", Status: sessions.StatusPending},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := encodePayload(t, New(tt.details, now))
+			if errs := validator.validate(validator.root, doc, "payload"); len(errs) > 0 {
+				t.Errorf("payload does not match schema v%d:\n%s", Version, strings.Join(errs, "\n"))
+			}
+		})
+	}
+}
+
+func TestSchemaRejectsInvalidPayloads(t *testing.T) {
+	validator := newSchemaValidator(t, Version)
+
+	tests := []struct {
+		name   string
+		change func(doc map[string]interface{})
+		want   string
+	}{
+		{"missing session ID", func(doc map[string]interface{}) { delete(doc, "sessionId") }, "missing required property sessionId"},
+		{"empty session ID", func(doc map[string]interface{}) { doc["sessionId"] = "" }, "payload.sessionId"},
+		{"other version", func(doc map[string]interface{}) { doc["version"] = 2.0 }, "payload.version"},
+		{"malformed timestamp", func(doc map[string]interface{}) { doc["timestamp"] = "yesterday" }, "payload.timestamp"},
+		{"level out of range", func(doc map[string]interface{}) {
+			doc["avatar"].(map[string]interface{})["patienceLevel"] = 6.0
+		}, "payload.avatar.patienceLevel"},
+		{"fractional level", func(doc map[string]interface{}) {
+			doc["scenario"].(map[string]interface{})["difficulty"] = 2.5
+		}, "payload.scenario.difficulty"},
+		{"rubric item without a weight", func(doc map[string]interface{}) {
+			rubric := doc["observer"].(map[string]interface{})["rubric"].([]interface{})
+			rubric[0].(map[string]interface{})["weight"] = 0.0
+		}, "payload.observer.rubric[0].weight"},
+		{"rubric mapping of the wrong type", func(doc map[string]interface{}) {
+			doc["scenario"].(map[string]interface{})["rubricItems"] = map[string]interface{}{"Clear Communication": "rubric_tone"}
+		}, "payload.scenario.rubricItems.Clear Communication"},
+		{"undocumented field", func(doc map[string]interface{}) { doc["traineeId"] = "trainee_1" }, "property traineeId is not in the schema"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := encodePayload(t, New(payloadDetails(), time.Now()))
+			tt.change(doc)
+			errs := validator.validate(validator.root, doc, "payload")
+			if !strings.Contains(strings.Join(errs, "\n"), tt.want) {
+				t.Errorf("errors %q do not mention %q", errs, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchema(t *testing.T) {
+	for version := 1; version <= Version; version++ {
+		if _, err := Schema(version); err != nil {
+			t.Errorf("Schema(%d): %v", version, err)
+		}
+	}
+	if _, err := Schema(Version + 1); err == nil {
+		t.Errorf("Schema(%d) found a schema for an unreleased version", Version+1)
+	}
+}
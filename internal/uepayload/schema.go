@@ -0,0 +1,19 @@
+// internal/uepayload/schema.go
+package uepayload
+
+import (
+	"embed"
+	"fmt"
+)
+
+//go:embed schema/*.json
+var schemas embed.FS
+
+// Schema returns the JSON Schema of a payload version
+func Schema(version int) ([]byte, error) {
+	data, err := schemas.ReadFile(fmt.Sprintf("schema/v%d.json", version))
+	if err != nil {
+		return nil, fmt.Errorf("no schema for payload version %d", version)
+	}
+	return data, nil
+}
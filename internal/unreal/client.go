@@ -0,0 +1,125 @@
+// internal/unreal/client.go
+package unreal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultTimeout bounds a single request to the Unreal Engine
+const DefaultTimeout = 10 * time.Second
+
+// ErrNoEndpoint is returned when no Unreal Engine endpoint is configured
+var ErrNoEndpoint = errors.New("no Unreal Engine endpoint configured")
+
+// StatusError is returned when the Unreal Engine answers with a non-2xx status
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("unreal engine responded with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("unreal engine responded with status %d: %s", e.StatusCode, e.Body)
+}
+
+// Permanent reports whether retrying the same request cannot succeed.
+// Client errors are permanent, except for timeouts and rate limiting.
+func (e *StatusError) Permanent() bool {
+	switch e.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
+	}
+	return e.StatusCode >= 400 && e.StatusCode < 500
+}
+
+// Client sends session payloads to an Unreal Engine endpoint over HTTP
+type Client struct {
+	httpClient *http.Client
+}
+
+// NewClient creates a client using httpClient, or a client with DefaultTimeout when nil
+func NewClient(httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	return &Client{httpClient: httpClient}
+}
+
+// Target is where a payload is sent: the endpoint of a station and its auth token
+type Target struct {
+	Endpoint  string
+	AuthToken string
+}
+
+// Send POSTs payload as JSON to the target and succeeds on any 2xx response
+func (c *Client) Send(ctx context.Context, target Target, payload []byte) error {
+	if target.Endpoint == "" {
+		return ErrNoEndpoint
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.Endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	authorize(req, target.AuthToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Keep a short excerpt of the body for the logs
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(body))}
+	}
+	return nil
+}
+
+// Check tells whether a station answers at its endpoint and accepts its token.
+// It sends a GET request; any answer other than a server error or an authorization
+// failure counts as reachable, since stations only need to handle POST and may
+// answer GET with 404, 405 or 501.
+func (c *Client) Check(ctx context.Context, target Target) (time.Duration, error) {
+	if target.Endpoint == "" {
+		return 0, ErrNoEndpoint
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.Endpoint, nil)
+	if err != nil {
+		return 0, fmt.Errorf("creating request: %w", err)
+	}
+	authorize(req, target.AuthToken)
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	elapsed := time.Since(start)
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden ||
+		(resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented) {
+		return elapsed, &StatusError{StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(body))}
+	}
+	return elapsed, nil
+}
+
+// authorize adds token to req as a bearer token
+func authorize(req *http.Request, token string) {
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
@@ -0,0 +1,362 @@
+// internal/unreal/control.go
+package unreal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Control channel timing
+const (
+	// HeartbeatInterval is how often the admin pings each connected station
+	HeartbeatInterval = 15 * time.Second
+	// HeartbeatTimeout is how long a station may stay silent before it is disconnected
+	HeartbeatTimeout = 3 * HeartbeatInterval
+	// AckTimeout bounds the wait for a station to acknowledge a command
+	AckTimeout = 10 * time.Second
+
+	writeTimeout = 5 * time.Second
+)
+
+// Commands the admin sends to a station
+const (
+	CommandPause    = "pause"
+	CommandResume   = "resume"
+	CommandComplete = "complete"
+	CommandAbort    = "abort"
+)
+
+// Types of the messages exchanged over the control channel
+const (
+	MessageHello     = "hello"
+	MessageCommand   = "command"
+	MessageAck       = "ack"
+	MessageHeartbeat = "heartbeat"
+)
+
+var (
+	// ErrStationOffline is returned when a command is sent to a station that is not connected
+	ErrStationOffline = errors.New("station is not connected")
+	// ErrAckTimeout is returned when a station does not acknowledge a command within AckTimeout
+	ErrAckTimeout = errors.New("station did not acknowledge the command in time")
+)
+
+// Message is the envelope of everything sent over the control channel, in both directions.
+// Stations open with a hello listing the payload versions they support. Commands carry
+// ID, SessionID and Command; acks carry the ID of the command, OK and Error. Hellos and
+// heartbeats report the battery, app version and the SessionID the station is running.
+type Message struct {
+	Type            string `json:"type"`
+	ID              string `json:"id,omitempty"`
+	SessionID       string `json:"sessionId,omitempty"`
+	Command         string `json:"command,omitempty"`
+	OK              bool   `json:"ok,omitempty"`
+	Error           string `json:"error,omitempty"`
+	PayloadVersions []int  `json:"payloadVersions,omitempty"`
+	Battery         *int   `json:"battery,omitempty"`
+	AppVersion      string `json:"appVersion,omitempty"`
+}
+
+// report returns what a hello or heartbeat tells about the station
+func (m Message) report() Report {
+	return Report{
+		Battery:         m.Battery,
+		AppVersion:      m.AppVersion,
+		SessionID:       m.SessionID,
+		PayloadVersions: m.PayloadVersions,
+	}
+}
+
+// RejectedError is returned when a station answers a command with a negative ack
+type RejectedError struct {
+	Command string
+	Reason  string
+}
+
+func (e *RejectedError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("station rejected %s", e.Command)
+	}
+	return fmt.Sprintf("station rejected %s: %s", e.Command, e.Reason)
+}
+
+// Authenticator checks the token a station presents when it connects
+type Authenticator func(stationID, token string) error
+
+// Hub holds the control channel of every connected station. Stations connect over
+// WebSocket and keep the connection open; the hub pings them and drops those that stop
+// answering. A station that reconnects replaces its previous connection, and commands
+// still waiting for an ack are sent again on the new one, so stations must treat
+// command IDs as idempotent.
+type Hub struct {
+	authenticate Authenticator
+	registry     *Registry
+	upgrader     websocket.Upgrader
+
+	mu      sync.Mutex
+	conns   map[string]*stationConn
+	pending map[string]*pendingCommand
+
+	nextID atomic.Uint64
+}
+
+// stationConn is one open control connection
+type stationConn struct {
+	stationID string
+	ws        *websocket.Conn
+	send      chan Message
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// pendingCommand is a command waiting for its ack
+type pendingCommand struct {
+	stationID string
+	message   Message
+	result    chan Message
+}
+
+// NewHub creates a hub that admits stations accepted by authenticate and records
+// their connections, hellos and heartbeats in registry
+func NewHub(authenticate Authenticator, registry *Registry) *Hub {
+	return &Hub{
+		authenticate: authenticate,
+		registry:     registry,
+		conns:        make(map[string]*stationConn),
+		pending:      make(map[string]*pendingCommand),
+	}
+}
+
+// Connected reports whether a station currently holds an open control connection
+func (h *Hub) Connected(stationID string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, ok := h.conns[stationID]
+	return ok
+}
+
+// ServeStation upgrades the request to the control connection of stationID and serves it
+// until the station disconnects. The station authenticates with its auth token as a bearer token.
+func (h *Hub) ServeStation(w http.ResponseWriter, r *http.Request, stationID string) {
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if err := h.authenticate(stationID, token); err != nil {
+		log.Printf("Refused control connection from station %s: %v", stationID, err)
+		w.Header().Set("WWW-Authenticate", `Bearer realm="vr-training-admin"`)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	ws, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already answered the request
+		log.Printf("Error upgrading control connection of station %s: %v", stationID, err)
+		return
+	}
+
+	conn := &stationConn{
+		stationID: stationID,
+		ws:        ws,
+		send:      make(chan Message, 16),
+		done:      make(chan struct{}),
+	}
+	h.register(conn)
+	go conn.writeLoop()
+	h.readLoop(conn)
+}
+
+// register makes conn the control connection of its station and resends unacknowledged commands
+func (h *Hub) register(conn *stationConn) {
+	h.mu.Lock()
+	previous := h.conns[conn.stationID]
+	h.conns[conn.stationID] = conn
+	resend := make([]Message, 0)
+	for _, cmd := range h.pending {
+		if cmd.stationID == conn.stationID {
+			resend = append(resend, cmd.message)
+		}
+	}
+	h.mu.Unlock()
+	h.registry.SetConnected(conn.stationID, true)
+
+	if previous != nil {
+		log.Printf("Station %s reconnected; closing its previous control connection", conn.stationID)
+		previous.close()
+	} else {
+		log.Printf("Station %s connected to the control channel", conn.stationID)
+	}
+	for _, msg := range resend {
+		log.Printf("Resending command %s (%s) to station %s", msg.ID, msg.Command, conn.stationID)
+		conn.enqueue(msg)
+	}
+}
+
+// unregister forgets conn unless the station has already replaced it
+func (h *Hub) unregister(conn *stationConn) {
+	h.mu.Lock()
+	current := h.conns[conn.stationID] == conn
+	if current {
+		delete(h.conns, conn.stationID)
+		log.Printf("Station %s disconnected from the control channel", conn.stationID)
+	}
+	h.mu.Unlock()
+	if current {
+		h.registry.SetConnected(conn.stationID, false)
+	}
+	conn.close()
+}
+
+// readLoop handles the messages of a station until the connection fails or goes silent
+func (h *Hub) readLoop(conn *stationConn) {
+	defer h.unregister(conn)
+
+	alive := func() {
+		conn.ws.SetReadDeadline(time.Now().Add(HeartbeatTimeout))
+	}
+	alive()
+	conn.ws.SetPongHandler(func(string) error {
+		alive()
+		h.registry.Seen(conn.stationID)
+		return nil
+	})
+
+	for {
+		var msg Message
+		if err := conn.ws.ReadJSON(&msg); err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Printf("Control connection of station %s closed: %v", conn.stationID, err)
+			}
+			return
+		}
+		alive()
+
+		switch msg.Type {
+		case MessageHello:
+			h.registry.Report(conn.stationID, msg.report())
+			log.Printf("Station %s (app %q) supports payload versions %v", conn.stationID, msg.AppVersion, msg.PayloadVersions)
+		case MessageAck:
+			h.acknowledge(conn.stationID, msg)
+		case MessageHeartbeat:
+			// The read deadline has been extended above
+			h.registry.Report(conn.stationID, msg.report())
+		default:
+			log.Printf("Ignoring %q message from station %s", msg.Type, conn.stationID)
+		}
+	}
+}
+
+// acknowledge hands an ack to the command waiting for it
+func (h *Hub) acknowledge(stationID string, msg Message) {
+	h.mu.Lock()
+	cmd, ok := h.pending[msg.ID]
+	if ok && cmd.stationID == stationID {
+		delete(h.pending, msg.ID)
+	}
+	h.mu.Unlock()
+
+	if !ok || cmd.stationID != stationID {
+		log.Printf("Station %s acknowledged unknown command %s", stationID, msg.ID)
+		return
+	}
+	cmd.result <- msg
+}
+
+// Send pushes a command for a session to a station and waits until the station
+// acknowledges it, the ack times out or ctx is cancelled
+func (h *Hub) Send(ctx context.Context, stationID, sessionID, command string) error {
+	cmd := &pendingCommand{
+		stationID: stationID,
+		message: Message{
+			Type:      MessageCommand,
+			ID:        fmt.Sprintf("cmd_%d_%d", time.Now().Unix(), h.nextID.Add(1)),
+			SessionID: sessionID,
+			Command:   command,
+		},
+		result: make(chan Message, 1),
+	}
+
+	h.mu.Lock()
+	conn, ok := h.conns[stationID]
+	if !ok {
+		h.mu.Unlock()
+		return ErrStationOffline
+	}
+	h.pending[cmd.message.ID] = cmd
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		delete(h.pending, cmd.message.ID)
+		h.mu.Unlock()
+	}()
+
+	log.Printf("Sending command %s (%s) for session %s to station %s", cmd.message.ID, command, sessionID, stationID)
+	conn.enqueue(cmd.message)
+
+	timer := time.NewTimer(AckTimeout)
+	defer timer.Stop()
+
+	select {
+	case ack := <-cmd.result:
+		if !ack.OK {
+			return &RejectedError{Command: command, Reason: ack.Error}
+		}
+		log.Printf("Station %s acknowledged command %s", stationID, cmd.message.ID)
+		return nil
+	case <-timer.C:
+		return ErrAckTimeout
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// enqueue queues msg for the write loop. Messages for a closed connection are dropped;
+// pending commands are resent when the station reconnects.
+func (c *stationConn) enqueue(msg Message) {
+	select {
+	case c.send <- msg:
+	case <-c.done:
+	}
+}
+
+// writeLoop writes queued messages and heartbeat pings until the connection is closed
+func (c *stationConn) writeLoop() {
+	ticker := time.NewTicker(HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case msg := <-c.send:
+			c.ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := c.ws.WriteJSON(msg); err != nil {
+				log.Printf("Error writing to station %s: %v", c.stationID, err)
+				c.close()
+				return
+			}
+		case <-ticker.C:
+			if err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				log.Printf("Heartbeat to station %s failed: %v", c.stationID, err)
+				c.close()
+				return
+			}
+		}
+	}
+}
+
+// close shuts the connection down; it is safe to call more than once
+func (c *stationConn) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.ws.Close()
+	})
+}
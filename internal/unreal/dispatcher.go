@@ -0,0 +1,286 @@
+// internal/unreal/dispatcher.go
+package unreal
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/internal/models"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+)
+
+// PollInterval is how often the dispatcher looks for deliveries that are due for a retry
+const PollInterval = time.Second
+
+// RetryPolicy controls how often and how quickly a failed delivery is retried
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryPolicy retries for roughly ten minutes before giving up
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 8,
+	BaseDelay:   2 * time.Second,
+	MaxDelay:    5 * time.Minute,
+}
+
+// Backoff returns the delay before the next attempt after the given number of failed attempts
+func (p RetryPolicy) Backoff(attempts int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	return delay
+}
+
+// Resolver returns the target for the payloads of a session
+type Resolver func(sessionID string) (Target, error)
+
+// Dispatcher delivers session payloads to the Unreal Engine through a durable outbox.
+// Payloads are written to the outbox before any attempt, so a restart resumes pending
+// deliveries. Payloads of one session are delivered in order. A delivery that fails
+// permanently, exhausts its attempts or has no target is dropped and its session marked failed.
+type Dispatcher struct {
+	client   *Client
+	outbox   models.OutboxRepository
+	sessions models.SessionRepository
+	resolve  Resolver
+	policy   RetryPolicy
+
+	// mu guards inFlight, the sessions a goroutine is currently delivering for, so each
+	// payload is sent by one goroutine only. It is never held while sending.
+	mu       sync.Mutex
+	inFlight map[string]bool
+	wake     chan struct{}
+
+	// now is replaceable so retries can be scheduled at a fixed time
+	now func() time.Time
+}
+
+// NewDispatcher creates a dispatcher that looks up the target of each delivery through resolve
+func NewDispatcher(client *Client, outbox models.OutboxRepository, sessionStore models.SessionRepository, resolve Resolver, policy RetryPolicy) *Dispatcher {
+	return &Dispatcher{
+		client:   client,
+		outbox:   outbox,
+		sessions: sessionStore,
+		resolve:  resolve,
+		policy:   policy,
+		inFlight: make(map[string]bool),
+		wake:     make(chan struct{}, 1),
+		now:      time.Now,
+	}
+}
+
+// Enqueue stores a payload for sessionID in the outbox and triggers a delivery run
+func (d *Dispatcher) Enqueue(sessionID string, payload []byte) error {
+	delivery, err := d.outbox.Add(models.Delivery{SessionID: sessionID, Payload: payload})
+	if err != nil {
+		return err
+	}
+	log.Printf("Queued Unreal Engine delivery %s for session %s", delivery.ID, sessionID)
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Pending returns the deliveries still waiting in the outbox
+func (d *Dispatcher) Pending() []models.Delivery {
+	return d.outbox.GetAll()
+}
+
+// Start delivers queued payloads in the background until ctx is cancelled
+func (d *Dispatcher) Start(ctx context.Context) {
+	if pending := d.outbox.GetAll(); len(pending) > 0 {
+		log.Printf("Resuming %d pending Unreal Engine deliveries", len(pending))
+	}
+
+	go func() {
+		ticker := time.NewTicker(PollInterval)
+		defer ticker.Stop()
+		for {
+			// Runs overlap when a station is slow; sessions still being delivered are skipped
+			go d.DeliverDue(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-d.wake:
+			}
+		}
+	}()
+}
+
+// DeliverDue attempts every delivery whose retry time has come and returns once they
+// were attempted. Sessions are delivered concurrently, so a slow station holds up only its
+// own sessions. A session's later payloads wait until its earlier ones are delivered or given up.
+func (d *Dispatcher) DeliverDue(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, sessionID := range d.claimDue() {
+		wg.Add(1)
+		go func(sessionID string) {
+			defer wg.Done()
+			defer d.release(sessionID)
+			d.deliverSession(ctx, sessionID)
+		}(sessionID)
+	}
+	wg.Wait()
+}
+
+// claimDue marks the sessions whose oldest delivery is due as in flight and returns them.
+// Sessions another run is delivering for are left to that run.
+func (d *Dispatcher) claimDue() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	seen := make(map[string]bool)
+	var due []string
+	for _, delivery := range d.outbox.GetAll() {
+		if seen[delivery.SessionID] {
+			continue
+		}
+		seen[delivery.SessionID] = true
+		if d.inFlight[delivery.SessionID] || delivery.NextAttempt.After(now) {
+			continue
+		}
+		d.inFlight[delivery.SessionID] = true
+		due = append(due, delivery.SessionID)
+	}
+	return due
+}
+
+// release ends the claim on a session taken by claimDue
+func (d *Dispatcher) release(sessionID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.inFlight, sessionID)
+}
+
+// deliverSession sends the deliveries of a claimed session in order until one stays in
+// the outbox or none is left
+func (d *Dispatcher) deliverSession(ctx context.Context, sessionID string) {
+	attempted := make(map[string]bool)
+	for ctx.Err() == nil {
+		delivery, ok := d.oldest(sessionID)
+		// A delivery seen twice could not be removed from the outbox; leave it to the next run
+		if !ok || attempted[delivery.ID] || delivery.NextAttempt.After(d.now()) {
+			return
+		}
+		attempted[delivery.ID] = true
+		if !d.attempt(ctx, delivery) {
+			return
+		}
+	}
+}
+
+// oldest returns the first delivery of a session still in the outbox
+func (d *Dispatcher) oldest(sessionID string) (models.Delivery, bool) {
+	for _, delivery := range d.outbox.GetAll() {
+		if delivery.SessionID == sessionID {
+			return delivery, true
+		}
+	}
+	return models.Delivery{}, false
+}
+
+// attempt sends one delivery and records the outcome. It reports whether the delivery
+// left the outbox, either because it was accepted or because it was given up.
+func (d *Dispatcher) attempt(ctx context.Context, delivery models.Delivery) bool {
+	target, err := d.resolve(delivery.SessionID)
+	if err != nil {
+		delivery.Attempts++
+		delivery.LastError = err.Error()
+		d.giveUp(delivery)
+		return true
+	}
+
+	err = d.client.Send(ctx, target, delivery.Payload)
+	if err == nil {
+		log.Printf("Delivered %s for session %s to %s", delivery.ID, delivery.SessionID, target.Endpoint)
+		if err := d.outbox.Delete(delivery.ID); err != nil {
+			log.Printf("Error removing delivered %s from the outbox: %v", delivery.ID, err)
+		}
+		d.accepted(delivery)
+		return true
+	}
+	if ctx.Err() != nil {
+		// Shutting down; the delivery stays due and is resumed on the next start
+		return false
+	}
+
+	delivery.Attempts++
+	delivery.LastError = err.Error()
+
+	var statusErr *StatusError
+	permanent := errors.As(err, &statusErr) && statusErr.Permanent()
+	if permanent || delivery.Attempts >= d.policy.MaxAttempts {
+		d.giveUp(delivery)
+		return true
+	}
+
+	delivery.NextAttempt = d.now().Add(d.policy.Backoff(delivery.Attempts))
+	log.Printf("Delivery %s for session %s failed (attempt %d of %d), retrying at %s: %v",
+		delivery.ID, delivery.SessionID, delivery.Attempts, d.policy.MaxAttempts,
+		delivery.NextAttempt.Format(time.RFC3339), err)
+	if err := d.outbox.Update(delivery); err != nil {
+		log.Printf("Error updating delivery %s: %v", delivery.ID, err)
+	}
+	return false
+}
+
+// giveUp drops a delivery and marks its session failed
+func (d *Dispatcher) giveUp(delivery models.Delivery) {
+	log.Printf("Giving up delivery %s for session %s after %d attempts: %s",
+		delivery.ID, delivery.SessionID, delivery.Attempts, delivery.LastError)
+
+	if err := d.outbox.Delete(delivery.ID); err != nil {
+		log.Printf("Error removing %s from the outbox: %v", delivery.ID, err)
+	}
+
+	session, err := d.sessions.GetByID(delivery.SessionID)
+	if err != nil {
+		log.Printf("Cannot mark session %s failed: %v", delivery.SessionID, err)
+		return
+	}
+	if !session.IsActive() {
+		return
+	}
+	_, err = d.sessions.Transition(delivery.SessionID, sessions.Transition{
+		To:     sessions.StatusFailed,
+		Actor:  sessions.ActorSystem,
+		Reason: "the Unreal Engine could not be reached: " + delivery.LastError,
+	})
+	if err != nil {
+		log.Printf("Error marking session %s failed: %v", delivery.SessionID, err)
+		return
+	}
+	log.Printf("Session %s marked failed: the Unreal Engine could not be reached", delivery.SessionID)
+}
+
+// accepted moves a starting session to running once its station has accepted the payload.
+// Stations that report a started event may have done so already.
+func (d *Dispatcher) accepted(delivery models.Delivery) {
+	session, err := d.sessions.GetByID(delivery.SessionID)
+	if err != nil || session.Status != sessions.StatusStarting {
+		return
+	}
+	_, err = d.sessions.Transition(delivery.SessionID, sessions.Transition{
+		To:     sessions.StatusRunning,
+		Actor:  sessions.ActorStation,
+		Reason: "station accepted the session",
+	})
+	if err != nil && !errors.Is(err, models.ErrIllegalTransition) {
+		log.Printf("Error marking session %s running: %v", delivery.SessionID, err)
+	}
+}
@@ -0,0 +1,248 @@
+// internal/unreal/dispatcher_test.go
+package unreal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/internal/models"
+	"github.com/saladinomario/vr-training-admin/templates/components/sessions"
+)
+
+var testPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: time.Minute}
+
+// newTestDispatcher returns a dispatcher sending every session to endpoint, with a clock
+// that only moves when the test advances it. The clock starts a little ahead, so
+// deliveries queued by the test are due right away.
+func newTestDispatcher(t *testing.T, endpoint string) (*Dispatcher, *models.SessionStore, *time.Time) {
+	t.Helper()
+	sessionStore := models.NewSessionStore(filepath.Join(t.TempDir(), "sessions.json"))
+	resolve := func(string) (Target, error) { return Target{Endpoint: endpoint}, nil }
+	d := NewDispatcher(NewClient(nil), models.NewOutboxStore(), sessionStore, resolve, testPolicy)
+	now := time.Now().Add(time.Minute)
+	d.now = func() time.Time { return now }
+	return d, sessionStore, &now
+}
+
+// startingSession creates a session that is waiting for its station to accept it
+func startingSession(t *testing.T, store *models.SessionStore) string {
+	t.Helper()
+	session, err := store.Create(sessions.Session{Configuration: &sessions.Configuration{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Transition(session.ID, sessions.Transition{To: sessions.StatusStarting, Actor: sessions.ActorSystem}); err != nil {
+		t.Fatal(err)
+	}
+	return session.ID
+}
+
+func sessionStatus(t *testing.T, store *models.SessionStore, id string) string {
+	t.Helper()
+	session, err := store.GetByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return session.Status
+}
+
+func TestDispatcherRetriesUntilAccepted(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	d, store, now := newTestDispatcher(t, server.URL)
+	id := startingSession(t, store)
+	if err := d.Enqueue(id, []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	d.DeliverDue(context.Background())
+	pending := d.Pending()
+	if len(pending) != 1 || pending[0].Attempts != 1 {
+		t.Fatalf("after a failed attempt the outbox holds %+v, want one delivery with one attempt", pending)
+	}
+	if want := now.Add(testPolicy.BaseDelay); !pending[0].NextAttempt.Equal(want) {
+		t.Errorf("retry scheduled at %s, want %s", pending[0].NextAttempt, want)
+	}
+
+	// Not due yet
+	d.DeliverDue(context.Background())
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("sent %d times before the retry was due, want 1", got)
+	}
+
+	*now = now.Add(testPolicy.BaseDelay)
+	d.DeliverDue(context.Background())
+	if len(d.Pending()) != 0 {
+		t.Errorf("outbox holds %d deliveries after the retry was accepted, want 0", len(d.Pending()))
+	}
+	if got := sessionStatus(t, store, id); got != sessions.StatusRunning {
+		t.Errorf("session is %s after the station accepted it, want %s", got, sessions.StatusRunning)
+	}
+}
+
+func TestDispatcherGivesUp(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		runs   int
+	}{
+		{"permanent failure", http.StatusBadRequest, 1},
+		{"attempts exhausted", http.StatusInternalServerError, testPolicy.MaxAttempts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				http.Error(w, "no", tt.status)
+			}))
+			defer server.Close()
+
+			d, store, now := newTestDispatcher(t, server.URL)
+			id := startingSession(t, store)
+			if err := d.Enqueue(id, []byte(`{}`)); err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < tt.runs; i++ {
+				d.DeliverDue(context.Background())
+				*now = now.Add(testPolicy.MaxDelay)
+			}
+
+			if got := int(calls.Load()); got != tt.runs {
+				t.Errorf("sent %d times, want %d", got, tt.runs)
+			}
+			if len(d.Pending()) != 0 {
+				t.Errorf("outbox holds %d deliveries after giving up, want 0", len(d.Pending()))
+			}
+			if got := sessionStatus(t, store, id); got != sessions.StatusFailed {
+				t.Errorf("session is %s after giving up, want %s", got, sessions.StatusFailed)
+			}
+		})
+	}
+}
+
+func TestDispatcherGivesUpWithoutTarget(t *testing.T) {
+	d, store, _ := newTestDispatcher(t, "")
+	d.resolve = func(string) (Target, error) { return Target{}, models.ErrSessionNotFound }
+	id := startingSession(t, store)
+	if err := d.Enqueue(id, []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	d.DeliverDue(context.Background())
+	if len(d.Pending()) != 0 {
+		t.Errorf("outbox holds %d deliveries, want 0", len(d.Pending()))
+	}
+	if got := sessionStatus(t, store, id); got != sessions.StatusFailed {
+		t.Errorf("session is %s, want %s", got, sessions.StatusFailed)
+	}
+}
+
+func TestDispatcherKeepsSessionOrder(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var body [8]byte
+		n, _ := r.Body.Read(body[:])
+		received = append(received, string(body[:n]))
+	}))
+	defer server.Close()
+
+	d, store, _ := newTestDispatcher(t, server.URL)
+	id := startingSession(t, store)
+	for _, payload := range []string{`1`, `2`, `3`} {
+		if err := d.Enqueue(id, []byte(payload)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d.DeliverDue(context.Background())
+	if len(received) != 3 || received[0] != "1" || received[1] != "2" || received[2] != "3" {
+		t.Errorf("received %v, want [1 2 3]", received)
+	}
+}
+
+func TestDispatcherSlowStationDoesNotBlockOthers(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
+	var fastCalls atomic.Int32
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fastCalls.Add(1)
+	}))
+	defer fast.Close()
+
+	d, store, _ := newTestDispatcher(t, "")
+	slowID := startingSession(t, store)
+	fastID := startingSession(t, store)
+	d.resolve = func(sessionID string) (Target, error) {
+		if sessionID == slowID {
+			return Target{Endpoint: slow.URL}, nil
+		}
+		return Target{Endpoint: fast.URL}, nil
+	}
+	if err := d.Enqueue(slowID, []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first run stays stuck on the slow station
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		d.DeliverDue(ctx)
+		close(done)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for !d.claimed(slowID) {
+		if time.Now().After(deadline) {
+			t.Fatal("the slow session was never claimed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := d.Enqueue(fastID, []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	d.DeliverDue(context.Background())
+	if got := fastCalls.Load(); got != 1 {
+		t.Errorf("fast station received %d payloads while another was slow, want 1", got)
+	}
+	if got := sessionStatus(t, store, fastID); got != sessions.StatusRunning {
+		t.Errorf("fast session is %s, want %s", got, sessions.StatusRunning)
+	}
+
+	cancel()
+	<-done
+	if len(d.Pending()) != 1 {
+		t.Errorf("outbox holds %d deliveries after shutdown, want the slow one", len(d.Pending()))
+	}
+}
+
+// claimed reports whether a run is delivering for sessionID
+func (d *Dispatcher) claimed(sessionID string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.inFlight[sessionID]
+}
@@ -0,0 +1,116 @@
+// internal/unreal/registry.go
+package unreal
+
+import (
+	"sync"
+	"time"
+)
+
+// Station health thresholds
+const (
+	// StaleAfter is how long a station may stay silent before its state is considered stale
+	StaleAfter = HeartbeatTimeout
+	// OfflineAfter is how long a station may stay silent before it is considered offline
+	OfflineAfter = 5 * time.Minute
+)
+
+// Report is what a station tells the admin about itself in a hello or heartbeat
+type Report struct {
+	// Battery is the charge of the headset in percent; nil for stations without a battery
+	Battery    *int
+	AppVersion string
+	// SessionID is the session the station is running; empty when it is idle
+	SessionID       string
+	PayloadVersions []int
+}
+
+// StationState is the last known state of a station
+type StationState struct {
+	StationID string
+	// Connected is set while the station holds an open control connection
+	Connected bool
+	// LastSeen is when the station was last heard from; zero if never since the admin started
+	LastSeen        time.Time
+	Battery         *int
+	AppVersion      string
+	SessionID       string
+	PayloadVersions []int
+}
+
+// Registry keeps the last known state of every station that reported to the admin.
+// It lives in memory: after a restart stations are unknown until they report again.
+type Registry struct {
+	mu     sync.Mutex
+	states map[string]StationState
+	now    func() time.Time
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		states: make(map[string]StationState),
+		now:    time.Now,
+	}
+}
+
+// Report records a hello or heartbeat of a station. Payload versions are kept
+// from the previous report when the new one does not list any.
+func (r *Registry) Report(stationID string, report Report) StationState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state := r.states[stationID]
+	state.StationID = stationID
+	state.LastSeen = r.now()
+	state.Battery = report.Battery
+	state.AppVersion = report.AppVersion
+	state.SessionID = report.SessionID
+	if len(report.PayloadVersions) > 0 {
+		state.PayloadVersions = append([]int{}, report.PayloadVersions...)
+	}
+	r.states[stationID] = state
+	return state
+}
+
+// SetConnected records that a station opened or closed its control connection.
+// Connecting counts as being seen; disconnecting does not.
+func (r *Registry) SetConnected(stationID string, connected bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state := r.states[stationID]
+	state.StationID = stationID
+	state.Connected = connected
+	if connected {
+		state.LastSeen = r.now()
+	}
+	r.states[stationID] = state
+}
+
+// Seen records that a station answered, without changing what it reported
+func (r *Registry) Seen(stationID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state := r.states[stationID]
+	state.StationID = stationID
+	state.LastSeen = r.now()
+	r.states[stationID] = state
+}
+
+// Get returns the state of a station; false if it never reported since the admin started
+func (r *Registry) Get(stationID string) (StationState, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.states[stationID]
+	state.PayloadVersions = append([]int(nil), state.PayloadVersions...)
+	return state, ok
+}
+
+// Forget drops a station, for example after it was removed from the settings
+func (r *Registry) Forget(stationID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.states, stationID)
+}
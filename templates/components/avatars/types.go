@@ -2,19 +2,22 @@
 package avatars
 
 type Avatar struct {
-	ID                  string
-	Name                string
-	Description         string
-	PersonalityType     string
-	CommunicationStyle  string
-	KnowledgeLevel      int
-	AggressivenessLevel int
-	PatienceLevel       int
-	EmotionalReactivity int
-	VoiceType           string
-	SpeakingSpeed       int // 1-5 scale
-	ImageURL            string
-	Keywords            string
+	ID                  string `json:"id"`
+	Name                string `json:"name"`
+	Description         string `json:"description"`
+	PersonalityType     string `json:"personalityType"`
+	CommunicationStyle  string `json:"communicationStyle"`
+	KnowledgeLevel      int    `json:"knowledgeLevel"`
+	AggressivenessLevel int    `json:"aggressivenessLevel"`
+	PatienceLevel       int    `json:"patienceLevel"`
+	EmotionalReactivity int    `json:"emotionalReactivity"`
+	VoiceType           string `json:"voiceType"`
+	SpeakingSpeed       int    `json:"speakingSpeed"` // 1-5 scale
+	ImageURL            string `json:"imageUrl"`
+	Keywords            string `json:"keywords"`
+
+	// Archived avatars are hidden when starting new sessions but kept for history
+	Archived bool `json:"archived,omitempty"`
 }
 
 // PersonalityTypes returns available personality types
@@ -53,3 +56,14 @@ func VoiceTypes() []string {
 		"Soft-Spoken",
 	}
 }
+
+// Available returns the avatars that have not been archived
+func Available(list []Avatar) []Avatar {
+	result := make([]Avatar, 0, len(list))
+	for _, item := range list {
+		if !item.Archived {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -1,17 +1,111 @@
 // templates/components/observers/types.go
 package observers
 
+import (
+	"fmt"
+	"math"
+)
+
 type Observer struct {
-	ID                   string
-	Name                 string
-	Description          string
-	FeedbackStyle        string
-	InterventionLevel    int // 1-5 scale (1: Minimal, 5: Frequent)
-	DetailLevel          int // 1-5 scale (1: Brief, 5: Comprehensive)
-	FeedbackTone         string
-	SuccessMetrics       string
-	InterventionTriggers []string
-	Active               bool
+	ID                   string   `json:"id"`
+	Name                 string   `json:"name"`
+	Description          string   `json:"description"`
+	FeedbackStyle        string   `json:"feedbackStyle"`
+	InterventionLevel    int      `json:"interventionLevel"` // 1-5 scale (1: Minimal, 5: Frequent)
+	DetailLevel          int      `json:"detailLevel"`       // 1-5 scale (1: Brief, 5: Comprehensive)
+	FeedbackTone         string   `json:"feedbackTone"`
+	SuccessMetrics       string   `json:"successMetrics"`
+	InterventionTriggers []string `json:"interventionTriggers"`
+	Active               bool     `json:"active"`
+
+	// Rubric lists what the observer scores trainees on. Scenarios map their success
+	// criteria to its items, and completed sessions are scored from the item ratings.
+	Rubric []RubricItem `json:"rubric,omitempty"`
+
+	// Archived observers are hidden when starting new sessions but kept for history
+	Archived bool `json:"archived,omitempty"`
+}
+
+// RubricItem is one thing an observer rates a trainee on, on a scale from Min to Max
+type RubricItem struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Weight is the share of the item in the scores it counts towards, relative to the other items
+	Weight int `json:"weight"`
+	Min    int `json:"min"`
+	Max    int `json:"max"`
+}
+
+// InScale reports whether rating lies on the scale of the item
+func (item RubricItem) InScale(rating int) bool {
+	return rating >= item.Min && rating <= item.Max
+}
+
+// Percent converts a rating on the scale of the item to a score from 0 to 100
+func (item RubricItem) Percent(rating int) int {
+	return int(math.Round(item.ExactPercent(rating)))
+}
+
+// ExactPercent is Percent before rounding, for scores that combine several items
+func (item RubricItem) ExactPercent(rating int) float64 {
+	if item.Max <= item.Min {
+		return 0
+	}
+	return float64(rating-item.Min) * 100 / float64(item.Max-item.Min)
+}
+
+// ScaleLabel describes the scale of the item, such as "1–5"
+func (item RubricItem) ScaleLabel() string {
+	for _, scale := range RubricScales() {
+		if scale.Min == item.Min && scale.Max == item.Max {
+			return scale.Name
+		}
+	}
+	return fmt.Sprintf("%d–%d", item.Min, item.Max)
+}
+
+// Scale is a range of ratings offered for rubric items
+type Scale struct {
+	Name string
+	Min  int
+	Max  int
+}
+
+// Value identifies the scale in forms
+func (s Scale) Value() string {
+	return fmt.Sprintf("%d-%d", s.Min, s.Max)
+}
+
+// RubricScales returns the scales rubric items can be rated on
+func RubricScales() []Scale {
+	return []Scale{
+		{Name: "Pass / Fail", Min: 0, Max: 1},
+		{Name: "1–3", Min: 1, Max: 3},
+		{Name: "1–5", Min: 1, Max: 5},
+		{Name: "0–10", Min: 0, Max: 10},
+		{Name: "0–100", Min: 0, Max: 100},
+	}
+}
+
+// ParseScale reads a scale from its Value, such as "1-5". The scale must offer more than one rating.
+func ParseScale(value string) (Scale, bool) {
+	var scale Scale
+	if _, err := fmt.Sscanf(value, "%d-%d", &scale.Min, &scale.Max); err != nil || scale.Max <= scale.Min {
+		return Scale{}, false
+	}
+	scale.Name = RubricItem{Min: scale.Min, Max: scale.Max}.ScaleLabel()
+	return scale, true
+}
+
+// RubricItem returns the item of the observer's rubric with the given ID
+func (o Observer) RubricItem(id string) (RubricItem, bool) {
+	for _, item := range o.Rubric {
+		if item.ID == id {
+			return item, true
+		}
+	}
+	return RubricItem{}, false
 }
 
 // FeedbackStyles returns available feedback styles
@@ -53,3 +147,14 @@ func CommonTriggers() []string {
 		"Proper referral needed",
 	}
 }
+
+// Available returns the observers that have not been archived
+func Available(list []Observer) []Observer {
+	result := make([]Observer, 0, len(list))
+	for _, item := range list {
+		if !item.Archived {
+			result = append(result, item)
+		}
+	}
+	return result
+}
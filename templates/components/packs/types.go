@@ -0,0 +1,117 @@
+// templates/components/packs/types.go
+package packs
+
+import (
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/avatars"
+	"github.com/saladinomario/vr-training-admin/templates/components/observers"
+	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
+)
+
+// Content kinds a pack can hold
+const (
+	KindScenario = "scenario"
+	KindAvatar   = "avatar"
+	KindObserver = "observer"
+)
+
+// Conflict resolutions offered when an imported item has the ID of an existing one
+const (
+	ResolveSkip      = "skip"
+	ResolveOverwrite = "overwrite"
+	ResolveDuplicate = "duplicate"
+)
+
+// Pack is a portable set of scenarios, avatars and observers shared between installations.
+// Items keep their IDs so references between them survive the trip.
+type Pack struct {
+	Format        string               `json:"format"`
+	Version       int                  `json:"version"`
+	SchemaVersion int                  `json:"schemaVersion"`
+	ExportedAt    time.Time            `json:"exportedAt"`
+	Scenarios     []scenarios.Scenario `json:"scenarios,omitempty"`
+	Avatars       []avatars.Avatar     `json:"avatars,omitempty"`
+	Observers     []observers.Observer `json:"observers,omitempty"`
+}
+
+// Empty reports whether the pack holds no items
+func (p *Pack) Empty() bool {
+	return len(p.Scenarios) == 0 && len(p.Avatars) == 0 && len(p.Observers) == 0
+}
+
+// PreviewItem describes what importing one item of a pack would do
+type PreviewItem struct {
+	Kind         string
+	ID           string
+	Name         string
+	Conflict     bool
+	ExistingName string
+}
+
+// Key identifies the item in the import form
+func (p PreviewItem) Key() string {
+	return p.Kind + ":" + p.ID
+}
+
+// ImportResult summarizes an applied import
+type ImportResult struct {
+	Created     int
+	Overwritten int
+	Duplicated  int
+	Skipped     int
+	Errors      []string
+}
+
+// KindLabel returns the label used for a kind in the UI
+func KindLabel(kind string) string {
+	switch kind {
+	case KindScenario:
+		return "Scenario"
+	case KindAvatar:
+		return "Avatar"
+	case KindObserver:
+		return "Observer"
+	default:
+		return kind
+	}
+}
+
+// Option is an item that can be selected for export
+type Option struct {
+	ID   string
+	Name string
+}
+
+// OptionGroup holds the items of one kind that can be selected for export
+type OptionGroup struct {
+	Kind    string
+	Options []Option
+}
+
+// ScenarioOptions lists scenarios for the export selection
+func ScenarioOptions(list []scenarios.Scenario) []Option {
+	options := make([]Option, 0, len(list))
+	for _, scenario := range list {
+		options = append(options, Option{ID: scenario.ID, Name: scenario.Name})
+	}
+	return options
+}
+
+// AvatarOptions lists avatars for the export selection
+func AvatarOptions(list []avatars.Avatar) []Option {
+	options := make([]Option, 0, len(list))
+	for _, avatar := range list {
+		options = append(options, Option{ID: avatar.ID, Name: avatar.Name})
+	}
+	return options
+}
+
+// ObserverOptions lists observers for the export selection
+func ObserverOptions(list []observers.Observer) []Option {
+	options := make([]Option, 0, len(list))
+	for _, observer := range list {
+		options = append(options, Option{ID: observer.ID, Name: observer.Name})
+	}
+	return options
+}
@@ -0,0 +1,59 @@
+// templates/components/scenarios/revision.go
+package scenarios
+
+import (
+	"fmt"
+	"time"
+)
+
+// Revision is an immutable copy of a scenario as it was saved at one point in time
+type Revision struct {
+	ID           string    `json:"id"`
+	ScenarioID   string    `json:"scenarioId"`
+	Number       int       `json:"number"`
+	Author       string    `json:"author"`
+	CreatedAt    time.Time `json:"createdAt"`
+	Note         string    `json:"note,omitempty"`
+	RestoredFrom string    `json:"restoredFrom,omitempty"` // revision ID a rollback restored
+	Scenario     Scenario  `json:"scenario"`
+}
+
+// FieldChange describes one field that differs between two revisions
+type FieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// Diff lists the fields that differ between two versions of a scenario
+func Diff(old, new Scenario) []FieldChange {
+	fields := []struct {
+		name     string
+		old, new interface{}
+	}{
+		{"Name", old.Name, new.Name},
+		{"Description", old.Description, new.Description},
+		{"Category", old.Category, new.Category},
+		{"Difficulty", old.Difficulty, new.Difficulty},
+		{"Duration (min)", old.Duration, new.Duration},
+		{"Scene", old.Scene, new.Scene},
+		{"Background Noise", old.BackgroundNoise, new.BackgroundNoise},
+		{"Success Criteria", old.SuccessCriteria, new.SuccessCriteria},
+		{"Keywords", old.Keywords, new.Keywords},
+		{"Rubric Items", DescribeRubricItems(old.RubricItems), DescribeRubricItems(new.RubricItems)},
+	}
+
+	changes := make([]FieldChange, 0)
+	for _, f := range fields {
+		oldValue, newValue := fmt.Sprint(f.old), fmt.Sprint(f.new)
+		if oldValue != newValue {
+			changes = append(changes, FieldChange{Field: f.name, Old: oldValue, New: newValue})
+		}
+	}
+	return changes
+}
+
+// SameContent reports whether two scenarios hold the same content, ignoring their IDs
+func SameContent(a, b Scenario) bool {
+	return len(Diff(a, b)) == 0
+}
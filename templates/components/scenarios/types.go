@@ -1,17 +1,30 @@
 // templates/components/scenarios/types.go
 package scenarios
 
+import (
+	"strings"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/observers"
+)
+
 type Scenario struct {
-	ID              string
-	Name            string
-	Description     string
-	Category        string
-	Difficulty      int
-	Duration        int
-	Scene           string
-	BackgroundNoise bool
-	SuccessCriteria string
-	Keywords        string
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	Category        string `json:"category"`
+	Difficulty      int    `json:"difficulty"`
+	Duration        int    `json:"duration"`
+	Scene           string `json:"scene"`
+	BackgroundNoise bool   `json:"backgroundNoise"`
+	SuccessCriteria string `json:"successCriteria"`
+	Keywords        string `json:"keywords"`
+	// RubricItems maps each of the success criteria to the IDs of the observer rubric
+	// items that score it. Items of several observers may be mapped to one criterion;
+	// a session is scored with the items of its own observer.
+	RubricItems map[string][]string `json:"rubricItems,omitempty"`
+
+	// Archived scenarios are hidden when starting new sessions but kept for history
+	Archived bool `json:"archived,omitempty"`
 }
 
 // ScenarioCategories returns available scenario categories for public service training
@@ -56,3 +69,74 @@ func SuccessCriteriaTypes() []string {
 		"Service Standards Met",
 	}
 }
+
+// CriteriaOf returns the predefined success criteria named in the success criteria of a
+// scenario, which may combine several, such as "Clear Communication and Service Standards Met"
+func CriteriaOf(successCriteria string) []string {
+	text := strings.ToLower(successCriteria)
+	result := make([]string, 0)
+	for _, criterion := range SuccessCriteriaTypes() {
+		if strings.Contains(text, strings.ToLower(criterion)) {
+			result = append(result, criterion)
+		}
+	}
+	return result
+}
+
+// RubricItemsField is the name of the form field holding the rubric items mapped to a criterion
+func RubricItemsField(criterion string) string {
+	return "rubric_items:" + criterion
+}
+
+// MapsRubricItem reports whether the scenario scores criterion with the rubric item id
+func (s Scenario) MapsRubricItem(criterion, id string) bool {
+	for _, mapped := range s.RubricItems[criterion] {
+		if mapped == id {
+			return true
+		}
+	}
+	return false
+}
+
+// StaleRubricItems returns the IDs mapped to criterion that match no rubric item of
+// observerList, left behind when items or observers were removed. Sessions skip them
+// when scoring, so a criterion mapped only to stale items goes unscored.
+func (s Scenario) StaleRubricItems(criterion string, observerList []observers.Observer) []string {
+	stale := make([]string, 0)
+	for _, id := range s.RubricItems[criterion] {
+		known := false
+		for _, observer := range observerList {
+			if _, ok := observer.RubricItem(id); ok {
+				known = true
+				break
+			}
+		}
+		if !known {
+			stale = append(stale, id)
+		}
+	}
+	return stale
+}
+
+// DescribeRubricItems renders a rubric mapping in the order of SuccessCriteriaTypes,
+// such as "Clear Communication: a, b; Service Standards Met: c"
+func DescribeRubricItems(mapping map[string][]string) string {
+	parts := make([]string, 0, len(mapping))
+	for _, criterion := range SuccessCriteriaTypes() {
+		if ids := mapping[criterion]; len(ids) > 0 {
+			parts = append(parts, criterion+": "+strings.Join(ids, ", "))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Available returns the scenarios that have not been archived
+func Available(list []Scenario) []Scenario {
+	result := make([]Scenario, 0, len(list))
+	for _, item := range list {
+		if !item.Archived {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,242 @@
+// templates/components/sessions/query.go
+package sessions
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Columns the session index can be sorted by
+const (
+	SortCreated  = "created"
+	SortID       = "id"
+	SortTrainee  = "trainee"
+	SortScenario = "scenario"
+	SortStation  = "station"
+	SortStatus   = "status"
+	SortDuration = "duration"
+	SortScore    = "score"
+)
+
+// Page sizes of the session index
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// queryDateFormat is the format of the date range in query strings
+const queryDateFormat = "2006-01-02"
+
+// Statuses returns every session status, in the order of the session lifecycle
+func Statuses() []string {
+	return []string{
+		StatusScheduled, StatusPending, StatusStarting, StatusRunning, StatusPaused,
+		StatusCompleted, StatusFailed, StatusAborted,
+	}
+}
+
+// SortColumns returns every column the session index can be sorted by
+func SortColumns() []string {
+	return []string{SortCreated, SortID, SortTrainee, SortScenario, SortStation, SortStatus, SortDuration, SortScore}
+}
+
+// Query selects one page of sessions. Empty filters match every session.
+type Query struct {
+	Status     string
+	ScenarioID string
+	AvatarID   string
+	ObserverID string
+	StationID  string
+	TraineeID  string
+	// From and To are the first and last day sessions were created on, at midnight.
+	// A zero time leaves that end of the range open.
+	From time.Time
+	To   time.Time
+
+	// Sort is one of SortColumns; Desc reverses it. Ties are broken by creation time, newest first.
+	Sort string
+	Desc bool
+	// Page is the 1-based page of PageSize sessions
+	Page     int
+	PageSize int
+}
+
+// DefaultQuery returns the query for the first page of sessions, newest first
+func DefaultQuery() Query {
+	return Query{Sort: SortCreated, Desc: true, Page: 1, PageSize: DefaultPageSize}
+}
+
+// ParseQuery reads a query from the query string of the session index.
+// Unknown or invalid values fall back to the defaults.
+func ParseQuery(values url.Values) Query {
+	q := DefaultQuery()
+	q.Status = values.Get("status")
+	q.ScenarioID = values.Get("scenario")
+	q.AvatarID = values.Get("avatar")
+	q.ObserverID = values.Get("observer")
+	q.StationID = values.Get("station")
+	q.TraineeID = values.Get("trainee")
+	if from, err := time.ParseInLocation(queryDateFormat, values.Get("from"), time.Local); err == nil {
+		q.From = from
+	}
+	if to, err := time.ParseInLocation(queryDateFormat, values.Get("to"), time.Local); err == nil {
+		q.To = to
+	}
+	if sort := values.Get("sort"); sort != "" {
+		q.Sort = sort
+		q.Desc = values.Get("desc") == "true"
+	}
+	if page, err := strconv.Atoi(values.Get("page")); err == nil {
+		q.Page = page
+	}
+	if size, err := strconv.Atoi(values.Get("size")); err == nil {
+		q.PageSize = size
+	}
+	return q.Normalize()
+}
+
+// Normalize replaces an unknown sort column and out of range paging with the defaults
+func (q Query) Normalize() Query {
+	known := false
+	for _, column := range SortColumns() {
+		if q.Sort == column {
+			known = true
+			break
+		}
+	}
+	if !known {
+		q.Sort, q.Desc = SortCreated, true
+	}
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PageSize < 1 {
+		q.PageSize = DefaultPageSize
+	}
+	if q.PageSize > MaxPageSize {
+		q.PageSize = MaxPageSize
+	}
+	return q
+}
+
+// Offset returns how many sessions precede the page
+func (q Query) Offset() int {
+	return (q.Page - 1) * q.PageSize
+}
+
+// Until returns the exclusive upper bound of the creation time, or the zero time when
+// the range is open
+func (q Query) Until() time.Time {
+	if q.To.IsZero() {
+		return q.To
+	}
+	return q.To.AddDate(0, 0, 1)
+}
+
+// Values encodes the query for a query string, leaving out the defaults
+func (q Query) Values() url.Values {
+	values := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	set("status", q.Status)
+	set("scenario", q.ScenarioID)
+	set("avatar", q.AvatarID)
+	set("observer", q.ObserverID)
+	set("station", q.StationID)
+	set("trainee", q.TraineeID)
+	if !q.From.IsZero() {
+		values.Set("from", q.From.Format(queryDateFormat))
+	}
+	if !q.To.IsZero() {
+		values.Set("to", q.To.Format(queryDateFormat))
+	}
+	if q.Sort != SortCreated || !q.Desc {
+		values.Set("sort", q.Sort)
+		values.Set("desc", strconv.FormatBool(q.Desc))
+	}
+	if q.Page > 1 {
+		values.Set("page", strconv.Itoa(q.Page))
+	}
+	if q.PageSize != DefaultPageSize {
+		values.Set("size", strconv.Itoa(q.PageSize))
+	}
+	return values
+}
+
+// URL returns the session index showing the query
+func (q Query) URL() string {
+	if encoded := q.Values().Encode(); encoded != "" {
+		return "/sessions?" + encoded
+	}
+	return "/sessions"
+}
+
+// WithPage returns the query for another page
+func (q Query) WithPage(page int) Query {
+	q.Page = page
+	return q
+}
+
+// SortedBy returns the query sorted by column, starting again at the first page.
+// Sorting by the current column again reverses the order.
+func (q Query) SortedBy(column string) Query {
+	if q.Sort == column {
+		q.Desc = !q.Desc
+	} else {
+		q.Sort = column
+		q.Desc = column == SortCreated || column == SortDuration || column == SortScore
+	}
+	q.Page = 1
+	return q
+}
+
+// FormatDate formats a day of the date range for a date input
+func FormatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(queryDateFormat)
+}
+
+// Page is one page of sessions matching a query
+type Page struct {
+	Sessions []*Session
+	// Total is the number of sessions matching the query on every page
+	Total int
+	Query Query
+}
+
+// PageCount returns the number of pages, at least one
+func (p Page) PageCount() int {
+	if p.Total == 0 {
+		return 1
+	}
+	return (p.Total + p.Query.PageSize - 1) / p.Query.PageSize
+}
+
+// HasPrevious reports whether there is a page before this one
+func (p Page) HasPrevious() bool {
+	return p.Query.Page > 1
+}
+
+// HasNext reports whether there is a page after this one
+func (p Page) HasNext() bool {
+	return p.Query.Page < p.PageCount()
+}
+
+// First returns the 1-based position of the first session on the page, or 0 when it is empty
+func (p Page) First() int {
+	if len(p.Sessions) == 0 {
+		return 0
+	}
+	return p.Query.Offset() + 1
+}
+
+// Last returns the 1-based position of the last session on the page
+func (p Page) Last() int {
+	return p.Query.Offset() + len(p.Sessions)
+}
@@ -0,0 +1,56 @@
+// templates/components/sessions/rubric.go
+package sessions
+
+import (
+	"github.com/saladinomario/vr-training-admin/templates/components/observers"
+	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
+)
+
+// CriterionScore is what a session scored on one success criterion of its scenario,
+// from the ratings of the observer rubric items mapped to the criterion
+type CriterionScore struct {
+	Criterion string `json:"criterion"`
+	// Score is the weighted average of the item scores, from 0 to 100
+	Score int `json:"score"`
+	// Weight is the sum of the weights of the rated items, the share of the criterion
+	// in the session score
+	Weight int         `json:"weight"`
+	Items  []ItemScore `json:"items"`
+}
+
+// ItemScore is the rating one rubric item received, as the item was defined when
+// the session started
+type ItemScore struct {
+	ItemID string `json:"itemId"`
+	Name   string `json:"name"`
+	Weight int    `json:"weight"`
+	Min    int    `json:"min"`
+	Max    int    `json:"max"`
+	Rating int    `json:"rating"`
+	// Score is the rating on a scale from 0 to 100
+	Score int `json:"score"`
+}
+
+// RatingField is the name of the form field holding a trainer's rating of a rubric item
+func RatingField(itemID string) string {
+	return "rating:" + itemID
+}
+
+// ScoredItems returns the rubric items of the observer that score a success criterion
+// of the scenario, in rubric order. A trainer completing the session can rate them.
+func (c *Configuration) ScoredItems() []observers.RubricItem {
+	result := make([]observers.RubricItem, 0)
+	if c == nil {
+		return result
+	}
+	criteria := scenarios.CriteriaOf(c.Scenario.SuccessCriteria)
+	for _, item := range c.Observer.Rubric {
+		for _, criterion := range criteria {
+			if c.Scenario.MapsRubricItem(criterion, item.ID) {
+				result = append(result, item)
+				break
+			}
+		}
+	}
+	return result
+}
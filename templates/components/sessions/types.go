@@ -3,6 +3,7 @@ package sessions
 
 import (
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/saladinomario/vr-training-admin/templates/components/avatars"
@@ -12,25 +13,104 @@ import (
 
 // Session status constants
 const (
+	StatusScheduled = "scheduled"
 	StatusPending   = "pending"
+	StatusStarting  = "starting"
 	StatusRunning   = "running"
 	StatusPaused    = "paused"
 	StatusCompleted = "completed"
 	StatusFailed    = "failed"
+	StatusAborted   = "aborted"
 )
 
+// Actors that change the status of a session
+const (
+	ActorTrainer = "trainer"
+	ActorStation = "station"
+	ActorSystem  = "system"
+)
+
+// transitions lists the statuses each status may move to. Completed, failed and
+// aborted sessions are finished and never change again. Scheduled sessions become
+// pending when their planned start time is reached, or are aborted when cancelled.
+var transitions = map[string][]string{
+	StatusScheduled: {StatusPending, StatusAborted},
+	StatusPending:   {StatusStarting, StatusRunning, StatusFailed, StatusAborted},
+	StatusStarting:  {StatusRunning, StatusFailed, StatusAborted},
+	StatusRunning:   {StatusPaused, StatusCompleted, StatusFailed, StatusAborted},
+	StatusPaused:    {StatusRunning, StatusCompleted, StatusFailed, StatusAborted},
+}
+
+// CanTransition reports whether a session may move from one status to another
+func CanTransition(from, to string) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition is one recorded status change of a session
+type Transition struct {
+	From   string    `json:"from,omitempty"`
+	To     string    `json:"to"`
+	Actor  string    `json:"actor"`
+	Reason string    `json:"reason,omitempty"`
+	At     time.Time `json:"at"`
+}
+
 // Session represents a VR training session
 type Session struct {
-	ID         string     `json:"id"`
-	ScenarioID string     `json:"scenarioId"`
-	AvatarID   string     `json:"avatarId"`
-	ObserverID string     `json:"observerId"`
-	Status     string     `json:"status"`
-	StartTime  time.Time  `json:"startTime"`
-	EndTime    *time.Time `json:"endTime,omitempty"`
-	UpdateTime time.Time  `json:"updateTime"`
-	Score      *int       `json:"score,omitempty"`
-	Notes      string     `json:"notes,omitempty"`
+	ID         string `json:"id"`
+	ScenarioID string `json:"scenarioId"`
+	// ScenarioRevisionID is the scenario revision the session was started with
+	ScenarioRevisionID string `json:"scenarioRevisionId,omitempty"`
+	AvatarID           string `json:"avatarId"`
+	// StationID is the VR station the session runs on
+	StationID  string `json:"stationId,omitempty"`
+	ObserverID string `json:"observerId"`
+	// TraineeID is the trainee the session is run for. TraineeName keeps their name as it
+	// was when the session was created, for sessions that predate trainee records.
+	TraineeID   string     `json:"traineeId,omitempty"`
+	TraineeName string     `json:"traineeName,omitempty"`
+	Status      string     `json:"status"`
+	StartTime   time.Time  `json:"startTime"`
+	EndTime     *time.Time `json:"endTime,omitempty"`
+	UpdateTime  time.Time  `json:"updateTime"`
+	Score       *int       `json:"score,omitempty"`
+	Notes       string     `json:"notes,omitempty"`
+	// ScoreBreakdown is the score of each success criterion when the session was scored
+	// with its observer's rubric
+	ScoreBreakdown []CriterionScore `json:"scoreBreakdown,omitempty"`
+
+	// ScheduledFor is the planned start time of a session booked in advance
+	ScheduledFor *time.Time `json:"scheduledFor,omitempty"`
+	// StartedAt is when the session first started running
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+	// PausedAt is when the current pause began; nil unless the session is paused
+	PausedAt *time.Time `json:"pausedAt,omitempty"`
+	// PausedTotal is the time spent in pauses that have ended
+	PausedTotal time.Duration `json:"pausedTotal,omitempty"`
+	// Transitions records every status change, oldest first
+	Transitions []Transition `json:"transitions,omitempty"`
+
+	// Configuration is the scenario, avatar and observer as they were when the session started
+	Configuration *Configuration `json:"configuration,omitempty"`
+
+	// LegalHold exempts the session from the data retention policy
+	LegalHold bool `json:"legalHold,omitempty"`
+	// AnonymizedAt is set once personal data has been removed from the session
+	AnonymizedAt *time.Time `json:"anonymizedAt,omitempty"`
+}
+
+// Configuration is an immutable copy of the content a session was started with.
+// Later edits or deletions of the scenario, avatar or observer do not change it.
+type Configuration struct {
+	CapturedAt time.Time          `json:"capturedAt"`
+	Scenario   scenarios.Scenario `json:"scenario"`
+	Avatar     avatars.Avatar     `json:"avatar"`
+	Observer   observers.Observer `json:"observer"`
 }
 
 // SessionDetails contains all details for a session including related entities
@@ -39,19 +119,233 @@ type SessionDetails struct {
 	Scenario scenarios.Scenario `json:"scenario"`
 	Avatar   avatars.Avatar     `json:"avatar"`
 	Observer observers.Observer `json:"observer"`
+	// Live is set when the details were looked up from the current content
+	// because the session predates configuration snapshots
+	Live bool `json:"live,omitempty"`
+}
+
+// Event types reported by the Unreal Engine while a session runs
+const (
+	EventStarted              = "started"
+	EventPaused               = "paused"
+	EventPhaseChanged         = "phase-changed"
+	EventAvatarUtterance      = "avatar-utterance"
+	EventTraineeUtterance     = "trainee-utterance"
+	EventObserverIntervention = "observer-intervention"
+	EventCompleted            = "completed"
+	EventCrashed              = "crashed"
+)
+
+// EventTypes returns every event type a station may report
+func EventTypes() []string {
+	return []string{
+		EventStarted, EventPaused, EventPhaseChanged, EventAvatarUtterance,
+		EventTraineeUtterance, EventObserverIntervention, EventCompleted, EventCrashed,
+	}
+}
+
+// IsValidEventType reports whether t is one of EventTypes
+func IsValidEventType(t string) bool {
+	for _, known := range EventTypes() {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is one entry of the event log a station reports for a session.
+// Utterances and interventions carry their text, which makes the log the session transcript.
+type Event struct {
+	ID        string `json:"id"`
+	SessionID string `json:"sessionId"`
+	// ClientID is the identifier the station gave the event, used to ignore resent events
+	ClientID   string    `json:"clientId,omitempty"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurredAt"`
+	ReceivedAt time.Time `json:"receivedAt"`
+	Phase      string    `json:"phase,omitempty"`
+	Text       string    `json:"text,omitempty"`
+	Score      *int      `json:"score,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	// Ratings are the ratings of a completed event, by the ID of the observer rubric item
+	Ratings map[string]int `json:"ratings,omitempty"`
+}
+
+// Transcript returns the utterances of the avatar and the trainee from an event log,
+// in the order they were spoken
+func Transcript(events []Event) []Event {
+	return filterEvents(events, EventAvatarUtterance, EventTraineeUtterance)
+}
+
+// ObserverFeedback returns the interventions of the observer from an event log
+func ObserverFeedback(events []Event) []Event {
+	return filterEvents(events, EventObserverIntervention)
+}
+
+func filterEvents(events []Event, types ...string) []Event {
+	result := make([]Event, 0)
+	for _, event := range events {
+		for _, t := range types {
+			if event.Type == t {
+				result = append(result, event)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// IsActive reports whether the session has not finished yet
+func (s *Session) IsActive() bool {
+	switch s.Status {
+	case StatusScheduled, StatusPending, StatusStarting, StatusRunning, StatusPaused:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsQueued reports whether the session still waits to be sent to its station:
+// it is scheduled for later, or pending until a station or slot is free
+func (s *Session) IsQueued() bool {
+	return s.Status == StatusScheduled || s.Status == StatusPending
+}
+
+// QueuedSince returns the time the session lines up by in the queue: its planned
+// start time when it was scheduled, otherwise when it was created
+func (s *Session) QueuedSince() time.Time {
+	if s.ScheduledFor != nil {
+		return *s.ScheduledFor
+	}
+	return s.StartTime
+}
+
+// FinishedAt returns when the session finished, falling back to its last update
+func (s *Session) FinishedAt() time.Time {
+	if s.EndTime != nil {
+		return *s.EndTime
+	}
+	return s.UpdateTime
 }
 
-// GetDuration returns the duration of the session
+// Clone returns a deep copy of the session that shares no memory with it
+func (s *Session) Clone() *Session {
+	c := *s
+	c.EndTime = clonePtr(s.EndTime)
+	c.Score = clonePtr(s.Score)
+	c.ScheduledFor = clonePtr(s.ScheduledFor)
+	c.StartedAt = clonePtr(s.StartedAt)
+	c.PausedAt = clonePtr(s.PausedAt)
+	c.AnonymizedAt = clonePtr(s.AnonymizedAt)
+	c.Transitions = slices.Clone(s.Transitions)
+	if s.ScoreBreakdown != nil {
+		c.ScoreBreakdown = make([]CriterionScore, len(s.ScoreBreakdown))
+		for i, criterion := range s.ScoreBreakdown {
+			criterion.Items = slices.Clone(criterion.Items)
+			c.ScoreBreakdown[i] = criterion
+		}
+	}
+	if s.Configuration != nil {
+		c.Configuration = s.Configuration.Clone()
+	}
+	return &c
+}
+
+// Clone returns a deep copy of the configuration
+func (c *Configuration) Clone() *Configuration {
+	copied := *c
+	if c.Scenario.RubricItems != nil {
+		copied.Scenario.RubricItems = make(map[string][]string, len(c.Scenario.RubricItems))
+		for criterion, ids := range c.Scenario.RubricItems {
+			copied.Scenario.RubricItems[criterion] = slices.Clone(ids)
+		}
+	}
+	copied.Observer.InterventionTriggers = slices.Clone(c.Observer.InterventionTriggers)
+	copied.Observer.Rubric = slices.Clone(c.Observer.Rubric)
+	return &copied
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// ApplyTransition moves the session to t.To and records t, keeping the end time and the
+// pause bookkeeping in step. Callers check CanTransition first.
+func (s *Session) ApplyTransition(t Transition) {
+	t.From = s.Status
+	at := t.At
+
+	if s.PausedAt != nil && t.To != StatusPaused {
+		s.PausedTotal += at.Sub(*s.PausedAt)
+		s.PausedAt = nil
+	}
+	switch t.To {
+	case StatusRunning:
+		if s.StartedAt == nil {
+			s.StartedAt = &at
+		}
+	case StatusPaused:
+		s.PausedAt = &at
+	case StatusCompleted, StatusFailed, StatusAborted:
+		s.EndTime = &at
+	}
+
+	s.Status = t.To
+	s.UpdateTime = at
+	s.Transitions = append(s.Transitions, t)
+}
+
+// GetDuration returns the active training time of the session: the time since it first
+// started running, up to its end, without pauses. Sessions recorded before transitions
+// were tracked count from their start time.
 func (s *Session) GetDuration() time.Duration {
-	if s.EndTime == nil {
-		return time.Since(s.StartTime)
+	begin := s.StartTime
+	if s.StartedAt != nil {
+		begin = *s.StartedAt
+	} else if len(s.Transitions) > 0 {
+		// Never started running
+		return 0
+	}
+
+	end := time.Now()
+	if s.EndTime != nil {
+		end = *s.EndTime
+	} else if s.PausedAt != nil {
+		end = *s.PausedAt
+	}
+
+	duration := end.Sub(begin) - s.PausedTotal
+	if duration < 0 {
+		return 0
 	}
-	return s.EndTime.Sub(s.StartTime)
+	return duration
+}
+
+// GetPausedDuration returns the total time the session spent paused
+func (s *Session) GetPausedDuration() time.Duration {
+	paused := s.PausedTotal
+	if s.PausedAt != nil {
+		paused += time.Since(*s.PausedAt)
+	}
+	return paused
 }
 
 // GetFormattedDuration returns the duration as a string
 func (s *Session) GetFormattedDuration() string {
-	duration := s.GetDuration()
+	return formatDuration(s.GetDuration())
+}
+
+// GetFormattedPausedDuration returns the paused time as a string
+func (s *Session) GetFormattedPausedDuration() string {
+	return formatDuration(s.GetPausedDuration())
+}
+
+func formatDuration(duration time.Duration) string {
 	hours := int(duration.Hours())
 	minutes := int(duration.Minutes()) % 60
 	seconds := int(duration.Seconds()) % 60
@@ -74,7 +368,34 @@ func (s *Session) GetStatusClass() string {
 		return "badge-success"
 	case StatusFailed:
 		return "badge-error"
+	case StatusStarting:
+		return "badge-info"
+	case StatusAborted:
+		return "badge-neutral"
+	case StatusScheduled:
+		return "badge-accent"
 	default:
 		return "badge-ghost"
 	}
 }
+
+// ScheduleDay is one day of the schedule calendar with the sessions planned for it,
+// in order of their planned start time
+type ScheduleDay struct {
+	Date     time.Time
+	Sessions []*Session
+}
+
+// WeekStart returns midnight of the Monday of the week t falls in, in the location of t
+func WeekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+// ScenarioName returns the name of the scenario the session was configured with
+func (s *Session) ScenarioName() string {
+	if s.Configuration == nil {
+		return s.ScenarioID
+	}
+	return s.Configuration.Scenario.Name
+}
@@ -0,0 +1,146 @@
+// templates/components/sessions/types_test.go
+package sessions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/observers"
+	"github.com/saladinomario/vr-training-admin/templates/components/scenarios"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{StatusScheduled, StatusPending, true},
+		{StatusScheduled, StatusRunning, false},
+		{StatusPending, StatusStarting, true},
+		{StatusPending, StatusPaused, false},
+		{StatusStarting, StatusRunning, true},
+		{StatusStarting, StatusPaused, false},
+		{StatusRunning, StatusPaused, true},
+		{StatusRunning, StatusStarting, false},
+		{StatusPaused, StatusRunning, true},
+		{StatusPaused, StatusPaused, false},
+		{StatusCompleted, StatusRunning, false},
+		{StatusFailed, StatusCompleted, false},
+		{StatusAborted, StatusPending, false},
+	}
+
+	for _, tt := range tests {
+		if got := CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%s, %s) = %t, want %t", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestGetDurationExcludesPauses(t *testing.T) {
+	created := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return created.Add(time.Duration(minutes) * time.Minute) }
+
+	tests := []struct {
+		name  string
+		steps []Transition
+		want  time.Duration
+	}{
+		{"never started", []Transition{
+			{To: StatusStarting, At: at(1)},
+		}, 0},
+		{"finished without pauses", []Transition{
+			{To: StatusRunning, At: at(1)},
+			{To: StatusCompleted, At: at(31)},
+		}, 30 * time.Minute},
+		{"finished after two pauses", []Transition{
+			{To: StatusRunning, At: at(1)},
+			{To: StatusPaused, At: at(11)},
+			{To: StatusRunning, At: at(16)},
+			{To: StatusPaused, At: at(20)},
+			{To: StatusRunning, At: at(22)},
+			{To: StatusCompleted, At: at(30)},
+		}, 22 * time.Minute},
+		{"paused now", []Transition{
+			{To: StatusRunning, At: at(1)},
+			{To: StatusPaused, At: at(11)},
+			{To: StatusRunning, At: at(16)},
+			{To: StatusPaused, At: at(20)},
+		}, 14 * time.Minute},
+		{"aborted while paused", []Transition{
+			{To: StatusRunning, At: at(1)},
+			{To: StatusPaused, At: at(11)},
+			{To: StatusAborted, At: at(21)},
+		}, 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := &Session{Status: StatusPending, StartTime: created, Transitions: []Transition{{To: StatusPending, At: created}}}
+			for _, step := range tt.steps {
+				if !CanTransition(session.Status, step.To) {
+					t.Fatalf("test moves from %s to %s, which is not allowed", session.Status, step.To)
+				}
+				session.ApplyTransition(step)
+			}
+			if got := session.GetDuration(); got != tt.want {
+				t.Errorf("GetDuration() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCloneSharesNothing(t *testing.T) {
+	score := 80
+	original := &Session{
+		Score:          &score,
+		Transitions:    []Transition{{To: StatusPending}},
+		ScoreBreakdown: []CriterionScore{{Criterion: "Empathy", Items: []ItemScore{{ItemID: "item_1"}}}},
+		Configuration: &Configuration{
+			Scenario: scenarios.Scenario{RubricItems: map[string][]string{"Empathy": {"item_1"}}},
+		},
+	}
+
+	clone := original.Clone()
+	*clone.Score = 10
+	clone.Transitions[0].To = StatusAborted
+	clone.ScoreBreakdown[0].Items[0].ItemID = "changed"
+	clone.Configuration.Scenario.RubricItems["Empathy"][0] = "changed"
+
+	if *original.Score != 80 || original.Transitions[0].To != StatusPending ||
+		original.ScoreBreakdown[0].Items[0].ItemID != "item_1" ||
+		original.Configuration.Scenario.RubricItems["Empathy"][0] != "item_1" {
+		t.Errorf("changing the clone changed the original: %+v", original)
+	}
+}
+
+func TestScoredItems(t *testing.T) {
+	config := &Configuration{
+		Scenario: scenarios.Scenario{
+			SuccessCriteria: "Clear Communication and Service Standards Met",
+			RubricItems: map[string][]string{
+				"Clear Communication":         {"clarity", "greeting"},
+				"Service Standards Met":       {"greeting"},
+				"Privacy Guidelines Followed": {"privacy"},
+			},
+		},
+		Observer: observers.Observer{Rubric: []observers.RubricItem{
+			{ID: "greeting"}, {ID: "privacy"}, {ID: "clarity"}, {ID: "unmapped"},
+		}},
+	}
+
+	got := config.ScoredItems()
+	want := []string{"greeting", "clarity"}
+	if len(got) != len(want) {
+		t.Fatalf("scored items = %v, want %v", got, want)
+	}
+	for i, item := range got {
+		if item.ID != want[i] {
+			t.Errorf("item %d = %s, want %s", i, item.ID, want[i])
+		}
+	}
+
+	var none *Configuration
+	if items := none.ScoredItems(); len(items) != 0 {
+		t.Errorf("a session without configuration has scored items %v", items)
+	}
+}
@@ -2,31 +2,73 @@
 package settings
 
 type LLMSettings struct {
-	ID                string
-	Provider          string
-	APIKey            string
-	Model             string
-	MaxTokens         int
-	Temperature       float64
-	TopP              float64
-	FrequencyPenalty  float64
-	PresencePenalty   float64
-	ProjectID         string // For Google LLM services
-	Location          string // For Google LLM services
-	Endpoint          string // For Google LLM services
-	ServiceAccountKey string // For Google LLM services
+	ID                string  `json:"id"`
+	Provider          string  `json:"provider"`
+	APIKey            string  `json:"apiKey"`
+	Model             string  `json:"model"`
+	MaxTokens         int     `json:"maxTokens"`
+	Temperature       float64 `json:"temperature"`
+	TopP              float64 `json:"topP"`
+	FrequencyPenalty  float64 `json:"frequencyPenalty"`
+	PresencePenalty   float64 `json:"presencePenalty"`
+	ProjectID         string  `json:"projectId"`         // For Google LLM services
+	Location          string  `json:"location"`          // For Google LLM services
+	Endpoint          string  `json:"endpoint"`          // For Google LLM services
+	ServiceAccountKey string  `json:"serviceAccountKey"` // For Google LLM services
 }
 
 type GeneralSettings struct {
-	ApplicationName       string
-	LogLevel              string
-	MaxConcurrentSessions int
-	SessionTimeout        int // minutes
-	RecordSessions        bool
-	StoreSessionData      bool
-	DataRetentionDays     int
+	ApplicationName       string `json:"applicationName"`
+	LogLevel              string `json:"logLevel"`
+	MaxConcurrentSessions int    `json:"maxConcurrentSessions"` // 0 for no limit
+	OverLimitAction       string `json:"overLimitAction"`       // what happens to sessions started beyond MaxConcurrentSessions
+	SessionTimeout        int    `json:"sessionTimeout"`        // minutes
+	TimeoutAction         string `json:"timeoutAction"`         // what happens to sessions running past SessionTimeout
+	RecordSessions        bool   `json:"recordSessions"`
+	StoreSessionData      bool   `json:"storeSessionData"`
+	DataRetentionDays     int    `json:"dataRetentionDays"`
+	RetentionAction       string `json:"retentionAction"`      // what happens to sessions past DataRetentionDays
+	PracticeIntervalDays  int    `json:"practiceIntervalDays"` // days without practice before a trainee's competency is flagged; 0 for never
 }
 
+// Station is a VR headset or PC running the Unreal Engine training application
+type Station struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Endpoint  string `json:"endpoint"`  // URL session payloads are POSTed to
+	AuthToken string `json:"authToken"` // sent as a bearer token; empty for none
+	Enabled   bool   `json:"enabled"`
+}
+
+// EnabledStations returns the stations sessions can be started on
+func EnabledStations(stations []Station) []Station {
+	result := make([]Station, 0, len(stations))
+	for _, station := range stations {
+		if station.Enabled {
+			result = append(result, station)
+		}
+	}
+	return result
+}
+
+// Actions for sessions started while MaxConcurrentSessions are running
+const (
+	OverLimitReject = "reject"
+	OverLimitQueue  = "queue"
+)
+
+// Actions for sessions running past the session timeout
+const (
+	TimeoutFail     = "fail"
+	TimeoutComplete = "complete"
+)
+
+// Retention actions for sessions older than the retention window
+const (
+	RetentionDelete    = "delete"
+	RetentionAnonymize = "anonymize"
+)
+
 // Providers returns available LLM providers
 func Providers() []string {
 	return []string{
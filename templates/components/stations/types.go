@@ -0,0 +1,103 @@
+// templates/components/stations/types.go
+package stations
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/saladinomario/vr-training-admin/templates/components/settings"
+)
+
+// Station states, from the heartbeats of the station and the sessions running on it
+const (
+	StateIdle     = "idle"
+	StateBusy     = "busy"
+	StateStale    = "stale"
+	StateOffline  = "offline"
+	StateDisabled = "disabled"
+)
+
+// Status is the live state of a configured station
+type Status struct {
+	Station settings.Station
+	State   string
+	// Connected is set while the station holds an open control connection
+	Connected bool
+	// LastSeen is when the station was last heard from; nil if never since the admin started
+	LastSeen   *time.Time
+	Battery    *int
+	AppVersion string
+	// SessionID is the session the station runs, as reported by the station or
+	// known from the sessions started on it
+	SessionID       string
+	PayloadVersions []int
+}
+
+// Available reports whether a new session can be assigned to the station
+func (s Status) Available() bool {
+	return s.State == StateIdle
+}
+
+// GetStateClass returns the CSS class for the state badge
+func (s Status) GetStateClass() string {
+	switch s.State {
+	case StateIdle:
+		return "badge-success"
+	case StateBusy:
+		return "badge-primary"
+	case StateStale:
+		return "badge-warning"
+	case StateOffline:
+		return "badge-error"
+	default:
+		return "badge-ghost"
+	}
+}
+
+// GetBatteryClass returns the CSS class for the battery level
+func (s Status) GetBatteryClass() string {
+	switch {
+	case s.Battery == nil:
+		return ""
+	case *s.Battery < 20:
+		return "text-error"
+	case *s.Battery < 50:
+		return "text-warning"
+	default:
+		return "text-success"
+	}
+}
+
+// FormatBattery returns the battery level for display
+func (s Status) FormatBattery() string {
+	if s.Battery == nil {
+		return "–"
+	}
+	return fmt.Sprintf("%d%%", *s.Battery)
+}
+
+// FormatLastSeen returns how long ago the station was last heard from, relative to now
+func (s Status) FormatLastSeen(now time.Time) string {
+	if s.LastSeen == nil {
+		return "never"
+	}
+	ago := now.Sub(*s.LastSeen).Round(time.Second)
+	switch {
+	case ago < 5*time.Second:
+		return "just now"
+	case ago < time.Minute:
+		return fmt.Sprintf("%ds ago", int(ago.Seconds()))
+	case ago < time.Hour:
+		return fmt.Sprintf("%dm ago", int(ago.Minutes()))
+	default:
+		return s.LastSeen.Format("2006-01-02 15:04")
+	}
+}
+
+// Label returns the station name with its state, for station pickers
+func (s Status) Label() string {
+	if s.Available() {
+		return s.Station.Name
+	}
+	return fmt.Sprintf("%s (%s)", s.Station.Name, s.State)
+}
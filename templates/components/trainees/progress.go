@@ -0,0 +1,84 @@
+// templates/components/trainees/progress.go
+package trainees
+
+import "time"
+
+// ScorePoint is the score a trainee reached in one session
+type ScorePoint struct {
+	SessionID string
+	At        time.Time
+	Score     int
+}
+
+// Competency is the progress of a trainee in one scenario category or success criterion
+type Competency struct {
+	Name string
+	// Practiced counts the completed sessions that trained the competency
+	Practiced int
+	// Scores of the scored sessions, oldest first
+	Scores        []ScorePoint
+	LastPracticed *time.Time
+	// Overdue is set when the competency was not practiced within the practice interval
+	Overdue bool
+}
+
+// Average returns the mean score, and false when no session was scored
+func (c Competency) Average() (int, bool) {
+	if len(c.Scores) == 0 {
+		return 0, false
+	}
+	total := 0
+	for _, point := range c.Scores {
+		total += point.Score
+	}
+	return total / len(c.Scores), true
+}
+
+// Latest returns the most recent score, and false when no session was scored
+func (c Competency) Latest() (int, bool) {
+	if len(c.Scores) == 0 {
+		return 0, false
+	}
+	return c.Scores[len(c.Scores)-1].Score, true
+}
+
+// Trend returns how much the latest score differs from the first one
+func (c Competency) Trend() int {
+	if len(c.Scores) < 2 {
+		return 0
+	}
+	return c.Scores[len(c.Scores)-1].Score - c.Scores[0].Score
+}
+
+// Progress summarises the sessions of a trainee by competency
+type Progress struct {
+	Trainee Trainee
+	// Sessions counts every session of the trainee; Completed those that ran to the end
+	Sessions  int
+	Completed int
+	// Scores of every scored session, oldest first
+	Scores []ScorePoint
+	// Categories follow scenarios.ScenarioCategories, Criteria scenarios.SuccessCriteriaTypes
+	Categories []Competency
+	Criteria   []Competency
+	// PracticeIntervalDays is the interval competencies are flagged after; 0 when flags are off
+	PracticeIntervalDays int
+}
+
+// Overdue returns the competencies that are due for practice, categories first
+func (p Progress) Overdue() []Competency {
+	result := make([]Competency, 0)
+	for _, list := range [][]Competency{p.Categories, p.Criteria} {
+		for _, competency := range list {
+			if competency.Overdue {
+				result = append(result, competency)
+			}
+		}
+	}
+	return result
+}
+
+// Average returns the mean score over every scored session, and false when none was scored
+func (p Progress) Average() (int, bool) {
+	return Competency{Scores: p.Scores}.Average()
+}
@@ -0,0 +1,63 @@
+// templates/components/trainees/types.go
+package trainees
+
+type Trainee struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	EmployeeID string `json:"employeeId"`
+	Department string `json:"department"`
+	// Language is the language the trainee is trained in
+	Language           string   `json:"language"`
+	AccessibilityNeeds []string `json:"accessibilityNeeds"`
+
+	// Archived trainees, such as staff who left, cannot be assigned new sessions but keep their history
+	Archived bool `json:"archived,omitempty"`
+}
+
+// Departments returns the departments trainees commonly belong to
+func Departments() []string {
+	return []string{
+		"Citizen Services",
+		"Registration Office",
+		"Social Services",
+		"Immigration Office",
+		"Tax Office",
+		"Public Order Office",
+	}
+}
+
+// Languages returns the languages sessions can be held in
+func Languages() []string {
+	return []string{
+		"German",
+		"English",
+		"French",
+		"Italian",
+		"Spanish",
+		"Turkish",
+	}
+}
+
+// CommonAccessibilityNeeds returns accommodations commonly needed in VR training
+func CommonAccessibilityNeeds() []string {
+	return []string{
+		"Seated play",
+		"Subtitles",
+		"Reduced motion",
+		"Hearing support",
+		"Colour-blind friendly display",
+		"One-handed controls",
+		"Extra time",
+	}
+}
+
+// Available returns the trainees that have not been archived
+func Available(list []Trainee) []Trainee {
+	result := make([]Trainee, 0, len(list))
+	for _, item := range list {
+		if !item.Archived {
+			result = append(result, item)
+		}
+	}
+	return result
+}